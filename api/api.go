@@ -4,6 +4,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"golang/models"
 	"golang/util"
+	"golang/webhooks"
 	"net/http"
 	"strconv"
 )
@@ -41,9 +42,12 @@ func AddIdea(c *gin.Context) {
 		return
 	}
 
-	success, err := models.AddIdea(json)
+	id, err := models.AddIdea(json, "api")
 
-	if success {
+	if err == nil {
+		json.Id = int(id)
+		json.Status = models.IdeaNew
+		webhooks.Fire(webhooks.IdeaCreated, json)
 		c.JSON(http.StatusOK, gin.H{"message": "Success"})
 	} else {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err})
@@ -51,25 +55,34 @@ func AddIdea(c *gin.Context) {
 }
 
 func UpdateIdea(c *gin.Context) {
-	var json models.Idea
+	ideaId, err := strconv.Atoi(c.Param("id"))
 
-	if err := c.ShouldBindJSON(&json); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
 		return
 	}
 
-	ideaId, err := strconv.Atoi(c.Param("id"))
+	json, err := models.GetIdeaById(c.Param("id"))
 
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+	if err != nil || json.Id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No Records Found"})
+		return
 	}
 
-	success, err := models.UpdateIdea(json, ideaId)
+	//The body is read over the saved idea, so fields it leaves out keep their values
+	if err := c.ShouldBindJSON(&json); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	//A status in the body moves the idea through its lifecycle, a move it doesn't allow saves nothing
+	json.Id = ideaId
+	success, err := models.UpdateIdeaAndStatus(json, "api")
 
 	if success {
 		c.JSON(http.StatusOK, gin.H{"message": "Success"})
 	} else {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 	}
 }
 
@@ -0,0 +1,410 @@
+package bulk
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Formats lists the file formats ideas are imported from and data is exported to
+var Formats = []string{"csv", "json", "markdown"}
+
+// Extensions maps each format to the file extension exports are named with
+var Extensions = map[string]string{"csv": ".csv", "json": ".json", "markdown": ".md"}
+
+// ContentTypes maps each format to the content type exports are served with
+var ContentTypes = map[string]string{"csv": "text/csv; charset=utf-8", "json": "application/json", "markdown": "text/markdown; charset=utf-8"}
+
+// Row is one idea read from an import file. Line is where it was found, the line of a CSV or Markdown file
+// or the position in a JSON list. The fields other than Idea are empty when the file doesn't give them.
+type Row struct {
+	Line      int
+	Idea      string
+	Concept   string
+	Series    string
+	Language  string
+	Priority  string
+	Tags      string
+	Keyword   string
+	NotBefore string
+}
+
+// Table is data to export, each of Rows holds a value for each of Columns
+type Table struct {
+	Columns []string
+	Rows    [][]string
+}
+
+// columnAliases maps the column names a spreadsheet might use to the Row field they fill
+var columnAliases = map[string]string{
+	"idea":        "idea",
+	"ideas":       "idea",
+	"ideatext":    "idea",
+	"text":        "idea",
+	"title":       "idea",
+	"prompt":      "idea",
+	"concept":     "concept",
+	"ideaconcept": "concept",
+	"topic":       "concept",
+	"series":      "series",
+	"seriesname":  "series",
+	"seriesid":    "series",
+	"language":    "language",
+	"lang":        "language",
+	"priority":    "priority",
+	"tags":        "tags",
+	"tag":         "tags",
+	"keyword":     "keyword",
+	"keywords":    "keyword",
+	"notbefore":   "not_before",
+}
+
+// positional is the order of the columns in a CSV file without a header row
+var positional = []string{"idea", "concept", "series", "language"}
+
+// Detect picks the format from the file extension, or failing that from how the content starts
+func Detect(fileName string, data []byte) string {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".csv", ".tsv":
+		return "csv"
+	case ".json":
+		return "json"
+	case ".md", ".markdown":
+		return "markdown"
+	}
+	trimmed := bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")))
+	if bytes.HasPrefix(trimmed, []byte("[")) || bytes.HasPrefix(trimmed, []byte("{")) {
+		return "json"
+	}
+	for _, line := range strings.Split(string(trimmed), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if markdownLine(line) {
+			return "markdown"
+		}
+	}
+	return "csv"
+}
+
+var listItemPattern = regexp.MustCompile(`^(?:[-*+]|\d+[.)])\s+(?:\[[ xX]\]\s+)?(.*)$`)
+var headingPattern = regexp.MustCompile(`^#{1,6}\s+(.*?)\s*#*$`)
+var separatorPattern = regexp.MustCompile(`^\|?[\s:|-]+\|?$`)
+
+func markdownLine(line string) bool {
+	return strings.HasPrefix(line, "|") || listItemPattern.MatchString(line) || headingPattern.MatchString(line)
+}
+
+// Parse reads the ideas in data, dropping rows without idea text
+func Parse(format string, data []byte) ([]Row, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	var rows []Row
+	var err error
+	switch format {
+	case "csv":
+		rows, err = parseCsv(data)
+	case "json":
+		rows, err = parseJson(data)
+	case "markdown":
+		rows, err = parseMarkdown(data)
+	default:
+		return nil, errors.New("Unknown format " + format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	ideas := make([]Row, 0, len(rows))
+	for _, row := range rows {
+		row.Idea = strings.TrimSpace(row.Idea)
+		row.Concept = strings.TrimSpace(row.Concept)
+		row.Series = strings.TrimSpace(row.Series)
+		row.Language = strings.TrimSpace(row.Language)
+		row.Priority = strings.TrimSpace(row.Priority)
+		row.Tags = strings.TrimSpace(row.Tags)
+		row.Keyword = strings.TrimSpace(row.Keyword)
+		row.NotBefore = strings.TrimSpace(row.NotBefore)
+		if row.Idea != "" {
+			ideas = append(ideas, row)
+		}
+	}
+	return ideas, nil
+}
+
+// header returns the Row field of each column when the first record names them, or nil when it is data
+func header(record []string) []string {
+	fields := make([]string, len(record))
+	found := false
+	for i, name := range record {
+		key := strings.Map(func(r rune) rune {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				return unicode.ToLower(r)
+			}
+			return -1
+		}, name)
+		fields[i] = columnAliases[key]
+		if fields[i] == "idea" {
+			found = true
+		}
+	}
+	if !found {
+		return nil
+	}
+	return fields
+}
+
+func fill(row *Row, field string, value string) {
+	switch field {
+	case "idea":
+		row.Idea = value
+	case "concept":
+		row.Concept = value
+	case "series":
+		row.Series = value
+	case "language":
+		row.Language = value
+	case "priority":
+		row.Priority = value
+	case "tags":
+		row.Tags = value
+	case "keyword":
+		row.Keyword = value
+	case "not_before":
+		row.NotBefore = value
+	}
+}
+
+// tableRows turns records into rows by their header, or by position when the first record isn't a header
+func tableRows(records [][]string, lines []int) []Row {
+	if len(records) == 0 {
+		return nil
+	}
+	fields := header(records[0])
+	if fields != nil {
+		records = records[1:]
+		lines = lines[1:]
+	} else {
+		fields = positional
+	}
+	rows := make([]Row, 0, len(records))
+	for i, record := range records {
+		row := Row{Line: lines[i]}
+		for j, value := range record {
+			if j < len(fields) {
+				fill(&row, fields[j], value)
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// parseCsv reads comma, semicolon or tab separated values, whichever the first line uses
+func parseCsv(data []byte) ([]Row, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+	firstLine, _, _ := strings.Cut(string(data), "\n")
+	if strings.Count(firstLine, "\t") > strings.Count(firstLine, ",") {
+		reader.Comma = '\t'
+	} else if strings.Count(firstLine, ";") > strings.Count(firstLine, ",") {
+		reader.Comma = ';'
+	}
+	var records [][]string
+	var lines []int
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		records = append(records, record)
+		lines = append(lines, line)
+	}
+	return tableRows(records, lines), nil
+}
+
+// parseJson reads a list of idea strings or of objects keyed like the CSV columns
+func parseJson(data []byte) ([]Row, error) {
+	var items []interface{}
+	if err := json.Unmarshal(data, &items); err != nil {
+		var wrapped map[string][]interface{}
+		if json.Unmarshal(data, &wrapped) != nil || wrapped["ideas"] == nil {
+			return nil, errors.New("Expected a JSON list of ideas: " + err.Error())
+		}
+		items = wrapped["ideas"]
+	}
+	rows := make([]Row, 0, len(items))
+	for i, item := range items {
+		row := Row{Line: i + 1}
+		switch value := item.(type) {
+		case string:
+			row.Idea = value
+		case map[string]interface{}:
+			keys := make([]string, 0, len(value))
+			for key := range value {
+				keys = append(keys, key)
+			}
+			fields := header(keys)
+			if fields == nil {
+				return nil, fmt.Errorf("Item %d has no idea, idea_text or title", i+1)
+			}
+			for j, key := range keys {
+				switch v := value[key].(type) {
+				case string:
+					fill(&row, fields[j], v)
+				case float64:
+					fill(&row, fields[j], fmt.Sprint(v))
+				}
+			}
+		default:
+			return nil, fmt.Errorf("Item %d is neither a string nor an object", i+1)
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// parseMarkdown reads a table with a header row, or a list where each item is an idea. In a list, a heading
+// sets the concept of the items under it, and a heading starting with "Series:" sets their series instead.
+func parseMarkdown(data []byte) ([]Row, error) {
+	var rows []Row
+	var records [][]string
+	var lines []int
+	concept := ""
+	series := ""
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "|") {
+			if !separatorPattern.MatchString(line) {
+				records = append(records, tableCells(line))
+				lines = append(lines, i+1)
+			}
+			continue
+		}
+		if match := headingPattern.FindStringSubmatch(line); match != nil {
+			if name, ok := cutPrefixFold(match[1], "series:"); ok {
+				series = strings.TrimSpace(name)
+			} else {
+				concept = match[1]
+				series = ""
+			}
+			continue
+		}
+		if match := listItemPattern.FindStringSubmatch(line); match != nil {
+			rows = append(rows, Row{Line: i + 1, Idea: match[1], Concept: concept, Series: series})
+		}
+	}
+	if len(records) > 0 {
+		rows = append(rows, tableRows(records, lines)...)
+	}
+	return rows, nil
+}
+
+func cutPrefixFold(s string, prefix string) (string, bool) {
+	if len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix) {
+		return s[len(prefix):], true
+	}
+	return s, false
+}
+
+// tableCells splits a Markdown table row on the pipes that aren't escaped
+func tableCells(line string) []string {
+	line = strings.TrimSuffix(strings.TrimPrefix(line, "|"), "|")
+	var cells []string
+	var cell strings.Builder
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '\\' && i+1 < len(line) && line[i+1] == '|':
+			cell.WriteByte('|')
+			i++
+		case line[i] == '|':
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+		default:
+			cell.WriteByte(line[i])
+		}
+	}
+	return append(cells, strings.TrimSpace(cell.String()))
+}
+
+// Write encodes the table in the format. JSON is a list of objects keyed by column, Markdown is a table,
+// and both read back in with Parse.
+func Write(w io.Writer, format string, table Table) error {
+	switch format {
+	case "csv":
+		writer := csv.NewWriter(w)
+		if err := writer.Write(table.Columns); err != nil {
+			return err
+		}
+		if err := writer.WriteAll(table.Rows); err != nil {
+			return err
+		}
+		return writer.Error()
+	case "json":
+		// Written by hand rather than from maps so the keys keep the order of the columns
+		var out bytes.Buffer
+		out.WriteString("[")
+		for r, row := range table.Rows {
+			if r > 0 {
+				out.WriteString(",")
+			}
+			out.WriteString("\n  {")
+			for i, column := range table.Columns {
+				value := ""
+				if i < len(row) {
+					value = row[i]
+				}
+				key, _ := json.Marshal(column)
+				encoded, _ := json.Marshal(value)
+				if i > 0 {
+					out.WriteString(",")
+				}
+				out.WriteString("\n    " + string(key) + ": " + string(encoded))
+			}
+			out.WriteString("\n  }")
+		}
+		if len(table.Rows) > 0 {
+			out.WriteString("\n")
+		}
+		out.WriteString("]\n")
+		_, err := out.WriteTo(w)
+		return err
+	case "markdown":
+		lines := []string{markdownRow(table.Columns), "|" + strings.Repeat(" --- |", len(table.Columns))}
+		for _, row := range table.Rows {
+			lines = append(lines, markdownRow(row))
+		}
+		_, err := io.WriteString(w, strings.Join(lines, "\n")+"\n")
+		return err
+	}
+	return errors.New("Unknown format " + format)
+}
+
+var markdownEscaper = strings.NewReplacer("|", `\|`, "\r\n", "<br>", "\n", "<br>")
+
+func markdownRow(cells []string) string {
+	escaped := make([]string, len(cells))
+	for i, cell := range cells {
+		escaped[i] = markdownEscaper.Replace(cell)
+	}
+	return "| " + strings.Join(escaped, " | ") + " |"
+}
+
+// Normalize reduces idea text to what duplicate detection compares, its lowercase words without punctuation
+func Normalize(text string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
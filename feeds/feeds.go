@@ -0,0 +1,222 @@
+package feeds
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"html"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Feed is an RSS or Atom feed reduced to what ideas are made from
+type Feed struct {
+	Title string
+	Items []Item
+}
+
+// Item is one entry of a feed. Id is the guid or Atom id, falling back to the link and then the title.
+type Item struct {
+	Id        string
+	Title     string
+	Link      string
+	Summary   string
+	Published time.Time
+}
+
+// maxFeedSize bounds how much of a feed is read
+const maxFeedSize = 5 * 1024 * 1024
+
+// maxSummary is how many characters of an entry's description are kept
+const maxSummary = 500
+
+// Fetch downloads and parses the feed at feedUrl
+func Fetch(ctx context.Context, feedUrl string) (Feed, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feedUrl, nil)
+	if err != nil {
+		return Feed{}, err
+	}
+	req.Header.Set("User-Agent", "Blog-o-Tron")
+	req.Header.Set("Accept", "application/rss+xml, application/atom+xml, application/xml, text/xml;q=0.9, */*;q=0.8")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return Feed{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return Feed{}, errors.New("Feed returned status code " + strconv.Itoa(resp.StatusCode))
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxFeedSize))
+	if err != nil {
+		return Feed{}, err
+	}
+	return Parse(data)
+}
+
+type link struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Text string `xml:",chardata"`
+}
+
+type entry struct {
+	Guid        string `xml:"guid"`
+	Id          string `xml:"id"`
+	About       string `xml:"about,attr"`
+	Title       string `xml:"title"`
+	Links       []link `xml:"link"`
+	Description string `xml:"description"`
+	Summary     string `xml:"summary"`
+	Content     string `xml:"content"`
+	PubDate     string `xml:"pubDate"`
+	Published   string `xml:"published"`
+	Updated     string `xml:"updated"`
+	Date        string `xml:"date"`
+}
+
+// document covers RSS 2.0 and Atom, and RSS 1.0 which keeps its items beside the channel rather than in it
+type document struct {
+	XMLName xml.Name
+	Title   string `xml:"title"`
+	Channel struct {
+		Title string  `xml:"title"`
+		Items []entry `xml:"item"`
+	} `xml:"channel"`
+	Items   []entry `xml:"item"`
+	Entries []entry `xml:"entry"`
+}
+
+// Parse reads an RSS 1.0, RSS 2.0 or Atom document
+func Parse(data []byte) (Feed, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = false
+	decoder.Entity = xml.HTMLEntity
+	decoder.CharsetReader = charsetReader
+	var doc document
+	if err := decoder.Decode(&doc); err != nil {
+		return Feed{}, errors.New("Not an RSS or Atom feed: " + err.Error())
+	}
+	var feed Feed
+	var entries []entry
+	switch strings.ToLower(doc.XMLName.Local) {
+	case "rss":
+		feed.Title = doc.Channel.Title
+		entries = doc.Channel.Items
+	case "rdf":
+		feed.Title = doc.Channel.Title
+		entries = doc.Items
+	case "feed":
+		feed.Title = doc.Title
+		entries = doc.Entries
+	default:
+		return Feed{}, errors.New("Not an RSS or Atom feed: the document is <" + doc.XMLName.Local + ">")
+	}
+	feed.Title = strings.TrimSpace(feed.Title)
+	for _, e := range entries {
+		item := Item{
+			Title:   plainText(e.Title),
+			Link:    e.link(),
+			Summary: summary(firstOf(e.Description, e.Summary, e.Content)),
+		}
+		item.Id = firstOf(e.Guid, e.Id, e.About, item.Link, item.Title)
+		item.Published = parseDate(firstOf(e.PubDate, e.Published, e.Updated, e.Date))
+		if item.Id == "" {
+			continue
+		}
+		feed.Items = append(feed.Items, item)
+	}
+	return feed, nil
+}
+
+// link returns the RSS link text or the Atom alternate link, RSS items often carry an atom:link as well
+func (e entry) link() string {
+	for _, l := range e.Links {
+		if text := strings.TrimSpace(l.Text); text != "" {
+			return text
+		}
+	}
+	for _, l := range e.Links {
+		if l.Href != "" && (l.Rel == "" || l.Rel == "alternate") {
+			return strings.TrimSpace(l.Href)
+		}
+	}
+	return ""
+}
+
+func firstOf(values ...string) string {
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+var tagPattern = regexp.MustCompile(`<[^>]*>`)
+var spacePattern = regexp.MustCompile(`\s+`)
+
+// plainText strips the markup feeds put in titles and descriptions
+func plainText(text string) string {
+	text = html.UnescapeString(tagPattern.ReplaceAllString(text, " "))
+	return strings.TrimSpace(spacePattern.ReplaceAllString(text, " "))
+}
+
+func summary(text string) string {
+	text = plainText(text)
+	if utf8.RuneCountInString(text) <= maxSummary {
+		return text
+	}
+	runes := []rune(text)
+	cut := string(runes[:maxSummary])
+	if i := strings.LastIndex(cut, " "); i > maxSummary/2 {
+		cut = cut[:i]
+	}
+	return cut + "..."
+}
+
+var dateLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC3339,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04:05 -0700",
+	"Mon, 02 Jan 06 15:04:05 -0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+// parseDate tries the date formats feeds use in practice, returning the zero time when none fit
+func parseDate(value string) time.Time {
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// charsetReader decodes Latin-1 and Windows-1252 feeds, the only common ones that aren't UTF-8
+func charsetReader(charset string, input io.Reader) (io.Reader, error) {
+	switch strings.ToLower(charset) {
+	case "utf-8", "utf8", "us-ascii", "ascii":
+		return input, nil
+	case "iso-8859-1", "latin1", "windows-1252", "cp1252":
+		data, err := io.ReadAll(input)
+		if err != nil {
+			return nil, err
+		}
+		runes := make([]rune, len(data))
+		for i, b := range data {
+			runes[i] = rune(b)
+		}
+		return strings.NewReader(string(runes)), nil
+	}
+	return nil, errors.New("Unsupported feed charset " + charset)
+}
@@ -0,0 +1,209 @@
+package imagegen
+
+import (
+	"context"
+	"golang/stablediffusion"
+	"golang/util"
+	"sort"
+	"strconv"
+)
+
+type automatic1111 struct{}
+
+func init() {
+	Register(automatic1111{})
+}
+
+func (automatic1111) Name() string {
+	return "sd"
+}
+
+func (automatic1111) Label() string {
+	return "StableDiffusion (AUTOMATIC1111)"
+}
+
+func (automatic1111) Fields() []SettingField {
+	return []SettingField{
+		{Name: "SD_URL", Type: "text", Help: "URL of the AUTOMATIC1111 web ui, started with --api."},
+		{Name: "IMG_SAMPLER", Type: "select"},
+		{Name: "IMG_UPSCALER", Type: "select"},
+		{Name: "IMG_STEPS", Type: "text"},
+		{Name: "IMG_NEGATIVE_PROMPTS", Type: "text"},
+		{Name: "SD_MODEL", Type: "select", Help: "Checkpoint to generate with, NONE keeps whatever the web ui has loaded."},
+		{Name: "SD_STYLE", Type: "select", Help: "Saved prompt style applied to every image."},
+		{Name: "SD_LORA", Type: "select", Help: "LoRA added to every prompt."},
+		{Name: "SD_LORA_WEIGHT", Type: "text", Help: "Strength of the LoRA, usually between 0.5 and 1."},
+		{Name: "SD_DENOISING", Type: "text", Help: "How far img2img may stray from the reference image, 0 keeps it and 1 ignores it."},
+	}
+}
+
+func (automatic1111) LoadOptions(ctx context.Context, settings map[string]string) map[string][]string {
+	options := map[string][]string{}
+	sdUrl := settings["SD_URL"]
+	if sdUrl == "" {
+		return options
+	}
+	samplers, err := stablediffusion.GetSamplers(sdUrl, ctx)
+	if err != nil {
+		util.Logger.Error().Err(err).Msg("Error getting samplers")
+	}
+	for name := range samplers {
+		options["IMG_SAMPLER"] = append(options["IMG_SAMPLER"], name)
+	}
+	upscalers, err := stablediffusion.GetUpscalers(sdUrl, ctx)
+	if err != nil {
+		util.Logger.Error().Err(err).Msg("Error getting upscalers")
+	}
+	for name := range upscalers {
+		options["IMG_UPSCALER"] = append(options["IMG_UPSCALER"], name)
+	}
+	models, err := stablediffusion.GetModels(sdUrl, ctx)
+	if err != nil {
+		util.Logger.Error().Err(err).Msg("Error getting models")
+	}
+	for _, model := range models {
+		options["SD_MODEL"] = append(options["SD_MODEL"], model.Title)
+	}
+	styles, err := stablediffusion.GetStyles(sdUrl, ctx)
+	if err != nil {
+		util.Logger.Error().Err(err).Msg("Error getting styles")
+	}
+	for _, style := range styles {
+		options["SD_STYLE"] = append(options["SD_STYLE"], style.Name)
+	}
+	loras, err := stablediffusion.GetLoras(sdUrl, ctx)
+	if err != nil {
+		util.Logger.Error().Err(err).Msg("Error getting loras")
+	}
+	for _, lora := range loras {
+		options["SD_LORA"] = append(options["SD_LORA"], lora.Name)
+	}
+	sort.Strings(options["IMG_SAMPLER"])
+	sort.Strings(options["IMG_UPSCALER"])
+	sort.Strings(options["SD_MODEL"])
+	sort.Strings(options["SD_STYLE"])
+	sort.Strings(options["SD_LORA"])
+	return options
+}
+
+func (automatic1111) Generate(ctx context.Context, settings map[string]string, req ImageRequest) ([]Image, error) {
+	iSteps, err := strconv.Atoi(settings["IMG_STEPS"])
+	if err != nil {
+		iSteps = 30
+	}
+	negativePrompt := settings["IMG_NEGATIVE_PROMPTS"]
+	if req.NegativePrompt != "" {
+		negativePrompt = req.NegativePrompt
+	}
+	model := firstSet(req.Model, settings["SD_MODEL"])
+	style := firstSet(req.Style, settings["SD_STYLE"])
+	lora := firstSet(req.Lora, settings["SD_LORA"])
+
+	prompt := req.Prompt
+	if lora != "" {
+		loraWeight, err := strconv.ParseFloat(settings["SD_LORA_WEIGHT"], 64)
+		if err != nil {
+			loraWeight = 0.8
+		}
+		prompt += " " + stablediffusion.LoraPrompt(lora, loraWeight)
+	}
+	var styles []string
+	if style != "" {
+		styles = []string{style}
+	}
+	var overrideSettings map[string]interface{}
+	if model != "" {
+		overrideSettings = map[string]interface{}{"sd_model_checkpoint": model}
+	}
+	imageReq := stablediffusion.SimpleImageRequest{
+		Prompt:                            prompt,
+		NegativePrompt:                    negativePrompt,
+		Styles:                            styles,
+		Seed:                              -1,
+		SamplerName:                       settings["IMG_SAMPLER"],
+		BatchSize:                         req.Count,
+		NIter:                             1,
+		Steps:                             iSteps,
+		CfgScale:                          7,
+		Width:                             req.Width,
+		Height:                            req.Height,
+		SNoise:                            0,
+		OverrideSettings:                  overrideSettings,
+		OverrideSettingsRestoreAfterwards: false,
+		SaveImages:                        true,
+		EnableHr:                          true,
+		HrScale:                           2,
+		HrUpscaler:                        settings["IMG_UPSCALER"],
+	}
+
+	var resp *stablediffusion.ImageResponse
+	if len(req.InitImage) > 0 {
+		denoising, err := strconv.ParseFloat(settings["SD_DENOISING"], 64)
+		if err != nil {
+			denoising = 0.6
+		}
+		//img2img has no hires fix pass, the reference image sets the composition instead
+		imageReq.EnableHr = false
+		resp, err = stablediffusion.Img2Img(settings["SD_URL"], ctx, stablediffusion.Img2ImgRequest{
+			SimpleImageRequest: imageReq,
+			InitImages:         [][]byte{req.InitImage},
+			DenoisingStrength:  denoising,
+		})
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		resp, err = stablediffusion.Generate(settings["SD_URL"], ctx, imageReq)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	info, err := resp.ParseInfo()
+	if err != nil {
+		util.Logger.Error().Err(err).Msg("Error parsing generation info")
+	}
+	images := make([]Image, 0, len(resp.Images))
+	for i, imgBytes := range resp.Images {
+		image := Image{Data: imgBytes, Seed: -1}
+		if i < len(info.AllSeeds) {
+			image.Seed = info.AllSeeds[i]
+		} else if i == 0 && info.Seed > 0 {
+			image.Seed = info.Seed
+		}
+		if i < len(info.Infotexts) {
+			image.Info = info.Infotexts[i]
+		}
+		images = append(images, image)
+	}
+	return images, nil
+}
+
+func (automatic1111) Progress(ctx context.Context, settings map[string]string) (Progress, error) {
+	progress, err := stablediffusion.GetProgress(settings["SD_URL"], ctx)
+	if err != nil {
+		return Progress{}, err
+	}
+	return Progress{
+		Active:  progress.State.JobCount > 0,
+		Percent: progress.Progress * 100,
+		Eta:     progress.EtaRelative,
+		Step:    progress.State.SamplingStep,
+		Steps:   progress.State.SamplingSteps,
+		Preview: progress.CurrentImage,
+	}, nil
+}
+
+func (automatic1111) Interrupt(ctx context.Context, settings map[string]string) error {
+	return stablediffusion.Interrupt(settings["SD_URL"], ctx)
+}
+
+// firstSet returns the first value that isn't empty
+func firstSet(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
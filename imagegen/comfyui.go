@@ -0,0 +1,198 @@
+package imagegen
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strings"
+	"text/template"
+	"time"
+)
+
+type comfyUI struct{}
+
+func init() {
+	Register(comfyUI{})
+}
+
+func (comfyUI) Name() string {
+	return "comfyui"
+}
+
+func (comfyUI) Label() string {
+	return "ComfyUI Workflow"
+}
+
+func (comfyUI) Fields() []SettingField {
+	return []SettingField{
+		{Name: "COMFY_URL", Type: "text", Help: "URL of the ComfyUI server."},
+		{Name: "COMFY_WORKFLOW", Type: "textarea", Help: "Workflow exported with Save (API Format).  Use {{.Prompt}}, {{.NegativePrompt}}, {{.Width}}, {{.Height}}, {{.Count}} and {{.Seed}} where the values should go."},
+	}
+}
+
+// comfyWorkflowData is passed to the workflow template, strings are escaped so they can sit inside JSON quotes
+type comfyWorkflowData struct {
+	Prompt         string
+	NegativePrompt string
+	Width          int
+	Height         int
+	Count          int
+	Seed           int64
+}
+
+type comfyPromptResponse struct {
+	PromptId string `json:"prompt_id"`
+}
+
+type comfyImage struct {
+	Filename  string `json:"filename"`
+	Subfolder string `json:"subfolder"`
+	Type      string `json:"type"`
+}
+
+type comfyHistory struct {
+	Outputs map[string]struct {
+		Images []comfyImage `json:"images"`
+	} `json:"outputs"`
+}
+
+func (comfyUI) Generate(ctx context.Context, settings map[string]string, req ImageRequest) ([]Image, error) {
+	comfyUrl := strings.TrimSuffix(settings["COMFY_URL"], "/")
+	if comfyUrl == "" || settings["COMFY_WORKFLOW"] == "" {
+		return nil, errors.New("COMFY_URL and COMFY_WORKFLOW must be set")
+	}
+	workflowTmpl, err := template.New("comfy-workflow").Parse(settings["COMFY_WORKFLOW"])
+	if err != nil {
+		return nil, fmt.Errorf("error parsing workflow: %w", err)
+	}
+	seed := rand.Int63n(1 << 32)
+	workflow := new(bytes.Buffer)
+	err = workflowTmpl.Execute(workflow, comfyWorkflowData{
+		Prompt:         jsonEscape(req.Prompt),
+		NegativePrompt: jsonEscape(req.NegativePrompt),
+		Width:          req.Width,
+		Height:         req.Height,
+		Count:          req.Count,
+		Seed:           seed,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error building workflow: %w", err)
+	}
+	if !json.Valid(workflow.Bytes()) {
+		return nil, errors.New("COMFY_WORKFLOW is not valid JSON once filled in")
+	}
+
+	body := []byte(`{"client_id": "blogotron", "prompt": ` + workflow.String() + `}`)
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, comfyUrl+"/prompt", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("error making request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching response: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("error status code %d: %s", resp.StatusCode, string(respBody))
+	}
+	var queued comfyPromptResponse
+	if err := json.NewDecoder(resp.Body).Decode(&queued); err != nil {
+		return nil, fmt.Errorf("error parsing prompt response: %w", err)
+	}
+
+	//ComfyUI queues the workflow, poll its history until the outputs appear
+	var history comfyHistory
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(time.Second):
+		}
+		histories := map[string]comfyHistory{}
+		err = comfyGet(ctx, comfyUrl+"/history/"+queued.PromptId, &histories)
+		if err != nil {
+			return nil, err
+		}
+		if found, ok := histories[queued.PromptId]; ok && len(found.Outputs) > 0 {
+			history = found
+			break
+		}
+	}
+
+	//The seed only means something when the workflow uses it, otherwise the workflow's own seed applies
+	if !strings.Contains(settings["COMFY_WORKFLOW"], ".Seed") {
+		seed = -1
+	}
+	var images []Image
+	for _, output := range history.Outputs {
+		for _, image := range output.Images {
+			if image.Type != "output" {
+				continue
+			}
+			params := url.Values{}
+			params.Set("filename", image.Filename)
+			params.Set("subfolder", image.Subfolder)
+			params.Set("type", image.Type)
+			imgBytes, err := download(ctx, comfyUrl+"/view?"+params.Encode())
+			if err != nil {
+				return nil, err
+			}
+			images = append(images, Image{Data: imgBytes, Seed: seed})
+		}
+	}
+	if len(images) == 0 {
+		return nil, errors.New("ComfyUI workflow produced no output images")
+	}
+	return images, nil
+}
+
+func (comfyUI) Interrupt(ctx context.Context, settings map[string]string) error {
+	comfyUrl := strings.TrimSuffix(settings["COMFY_URL"], "/")
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, comfyUrl+"/interrupt", nil)
+	if err != nil {
+		return fmt.Errorf("error making request: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("error fetching response: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("error status code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Test asks ComfyUI for its system stats, which it answers as soon as it is up
+func (comfyUI) Test(ctx context.Context, settings map[string]string) error {
+	comfyUrl := strings.TrimSuffix(settings["COMFY_URL"], "/")
+	if comfyUrl == "" || settings["COMFY_WORKFLOW"] == "" {
+		return errors.New("COMFY_URL and COMFY_WORKFLOW must be set")
+	}
+	_, err := download(ctx, comfyUrl+"/system_stats")
+	return err
+}
+
+func comfyGet(ctx context.Context, getUrl string, result interface{}) error {
+	imgBytes, err := download(ctx, getUrl)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(imgBytes, result); err != nil {
+		return fmt.Errorf("error parsing response: %w", err)
+	}
+	return nil
+}
+
+func jsonEscape(text string) string {
+	escaped, _ := json.Marshal(text)
+	return strings.Trim(string(escaped), "\"")
+}
@@ -0,0 +1,43 @@
+package imagegen
+
+import (
+	"context"
+	"errors"
+	"golang/openai"
+)
+
+type dalle struct{}
+
+func init() {
+	Register(dalle{})
+}
+
+func (dalle) Name() string {
+	return "openai"
+}
+
+func (dalle) Label() string {
+	return "OpenAI Dall-E"
+}
+
+func (dalle) Fields() []SettingField {
+	return []SettingField{
+		{Name: "DALLE_SIZE", Type: "select", Help: "Size of the generated image.  Uses OPENAI_API_KEY.", Options: []string{"256x256", "512x512", "1024x1024"}},
+	}
+}
+
+func (dalle) Generate(ctx context.Context, settings map[string]string, req ImageRequest) ([]Image, error) {
+	images, err := openai.GenerateImg(ctx, req.Prompt, settings["OPENAI_API_KEY"], settings["DALLE_SIZE"], req.Count)
+	if err != nil {
+		return nil, err
+	}
+	return unseeded(images), nil
+}
+
+// Test checks OPENAI_API_KEY rather than generating an image, which Dall-E charges for
+func (dalle) Test(ctx context.Context, settings map[string]string) error {
+	if settings["OPENAI_API_KEY"] == "" {
+		return errors.New("OPENAI_API_KEY must be set")
+	}
+	return openai.CheckAccess(ctx, settings["OPENAI_API_KEY"])
+}
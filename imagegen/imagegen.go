@@ -0,0 +1,139 @@
+package imagegen
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+)
+
+// ImageRequest is what every engine needs to generate images for an article
+type ImageRequest struct {
+	Prompt         string
+	NegativePrompt string
+	Width          int
+	Height         int
+	Count          int
+	// InitImage is a reference image to generate from, engines without img2img ignore it
+	InitImage []byte
+	// Model, Style and Lora override the engine's configured checkpoint, style and LoRA
+	Model string
+	Style string
+	Lora  string
+}
+
+// Image is one generated image, Seed is -1 when the engine doesn't report one
+type Image struct {
+	Data []byte
+	Seed int64
+	// Info is the engine's generation parameters, kept so an image can be reproduced
+	Info string
+}
+
+// SettingField describes one setting an engine reads, used to render it on the settings page.
+// Type is text, password, textarea or select.
+type SettingField struct {
+	Name    string
+	Type    string
+	Help    string
+	Options []string
+}
+
+// ImageGenerator is implemented by each image backend. Name is the value stored in IMG_MODE.
+type ImageGenerator interface {
+	Name() string
+	Label() string
+	Fields() []SettingField
+	Generate(ctx context.Context, settings map[string]string, req ImageRequest) ([]Image, error)
+}
+
+// OptionLoader is implemented by engines whose select options are read from the backend itself
+type OptionLoader interface {
+	LoadOptions(ctx context.Context, settings map[string]string) map[string][]string
+}
+
+// Progress is how far along an engine is with its current image, Preview is the partly generated image when it has one
+type Progress struct {
+	Active  bool
+	Percent float64
+	// Eta is the estimated seconds remaining
+	Eta     float64
+	Step    int
+	Steps   int
+	Preview []byte
+}
+
+// ProgressReporter is implemented by engines that can report on the image they are generating
+type ProgressReporter interface {
+	Progress(ctx context.Context, settings map[string]string) (Progress, error)
+}
+
+// Interrupter is implemented by engines that can stop the image they are generating
+type Interrupter interface {
+	Interrupt(ctx context.Context, settings map[string]string) error
+}
+
+// Tester is implemented by engines that can check their connection without generating an image.
+// Engines without it are tested by generating one.
+type Tester interface {
+	Test(ctx context.Context, settings map[string]string) error
+}
+
+var generators = map[string]ImageGenerator{}
+
+// Register makes an engine available to IMG_MODE, engines register themselves from init
+func Register(generator ImageGenerator) {
+	generators[generator.Name()] = generator
+}
+
+func Get(name string) (ImageGenerator, bool) {
+	generator, ok := generators[name]
+	return generator, ok
+}
+
+// All returns the registered engines ordered by name
+func All() []ImageGenerator {
+	all := make([]ImageGenerator, 0, len(generators))
+	for _, generator := range generators {
+		all = append(all, generator)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		return all[i].Name() < all[j].Name()
+	})
+	return all
+}
+
+// unseeded wraps images from engines that don't report a seed
+func unseeded(images [][]byte) []Image {
+	result := make([]Image, 0, len(images))
+	for _, image := range images {
+		result = append(result, Image{Data: image, Seed: -1})
+	}
+	return result
+}
+
+// download fetches a url, returning the body when the response is 200
+func download(ctx context.Context, getUrl string) ([]byte, error) {
+	return get(ctx, getUrl, nil)
+}
+
+// get fetches a url with the given headers, returning the body when the response is 200
+func get(ctx context.Context, getUrl string, headers map[string]string) ([]byte, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, getUrl, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error making request: %w", err)
+	}
+	for k, v := range headers {
+		httpReq.Header.Set(k, v)
+	}
+	resp, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching response: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error status code %d", resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
@@ -0,0 +1,125 @@
+package imagegen
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// openAICompatible talks to any server exposing the OpenAI images API, such as LocalAI
+type openAICompatible struct{}
+
+func init() {
+	Register(openAICompatible{})
+}
+
+func (openAICompatible) Name() string {
+	return "openai-compatible"
+}
+
+func (openAICompatible) Label() string {
+	return "OpenAI Compatible Endpoint"
+}
+
+func (openAICompatible) Fields() []SettingField {
+	return []SettingField{
+		{Name: "OPENAI_IMG_URL", Type: "text", Help: "Base URL of the server, /v1/images/generations is appended."},
+		{Name: "OPENAI_IMG_KEY", Type: "password", Help: "API key sent as a Bearer token, if the server needs one."},
+		{Name: "OPENAI_IMG_MODEL", Type: "text", Help: "Model name to request, if the server needs one."},
+	}
+}
+
+type compatImageRequest struct {
+	Model          string `json:"model,omitempty"`
+	Prompt         string `json:"prompt"`
+	N              int    `json:"n"`
+	Size           string `json:"size"`
+	ResponseFormat string `json:"response_format"`
+}
+
+type compatImageResponse struct {
+	Data []struct {
+		B64Json string `json:"b64_json"`
+		Url     string `json:"url"`
+	} `json:"data"`
+}
+
+func (openAICompatible) Generate(ctx context.Context, settings map[string]string, req ImageRequest) ([]Image, error) {
+	baseUrl := strings.TrimSuffix(settings["OPENAI_IMG_URL"], "/")
+	if baseUrl == "" {
+		return nil, errors.New("OPENAI_IMG_URL must be set")
+	}
+	reqBody, err := json.Marshal(compatImageRequest{
+		Model:          settings["OPENAI_IMG_MODEL"],
+		Prompt:         req.Prompt,
+		N:              req.Count,
+		Size:           strconv.Itoa(req.Width) + "x" + strconv.Itoa(req.Height),
+		ResponseFormat: "b64_json",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error encoding json: %w", err)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, baseUrl+"/v1/images/generations", bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("error making request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if settings["OPENAI_IMG_KEY"] != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+settings["OPENAI_IMG_KEY"])
+	}
+	resp, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching response: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("error status code %d: %s", resp.StatusCode, string(respBody))
+	}
+	var result compatImageResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("error parsing image response: %w", err)
+	}
+	var images [][]byte
+	for _, data := range result.Data {
+		if data.B64Json != "" {
+			imgBytes, err := base64.StdEncoding.DecodeString(data.B64Json)
+			if err != nil {
+				return nil, err
+			}
+			images = append(images, imgBytes)
+		} else if data.Url != "" {
+			//Some servers ignore response_format and always return a url
+			imgBytes, err := download(ctx, data.Url)
+			if err != nil {
+				return nil, err
+			}
+			images = append(images, imgBytes)
+		}
+	}
+	if len(images) == 0 {
+		return nil, errors.New("no images returned")
+	}
+	return unseeded(images), nil
+}
+
+// Test lists the server's models, most servers behind the OpenAI images API answer /v1/models as well
+func (openAICompatible) Test(ctx context.Context, settings map[string]string) error {
+	baseUrl := strings.TrimSuffix(settings["OPENAI_IMG_URL"], "/")
+	if baseUrl == "" {
+		return errors.New("OPENAI_IMG_URL must be set")
+	}
+	headers := map[string]string{}
+	if settings["OPENAI_IMG_KEY"] != "" {
+		headers["Authorization"] = "Bearer " + settings["OPENAI_IMG_KEY"]
+	}
+	_, err := get(ctx, baseUrl+"/v1/models", headers)
+	return err
+}
@@ -0,0 +1,241 @@
+package imageproc
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/draw"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+
+	xdraw "golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// Options controls how an image is prepared for upload.
+// Format is original, jpeg, png or webp. Original keeps the source's format and bytes unless the image has to be
+// resized, compressed or overlaid. When Width and Height are both set the image is cropped to fill them,
+// with only Width set it is scaled down to fit. MaxBytes is the size target, 0 skips it.
+// Overlay, when set, is drawn on after resizing.
+type Options struct {
+	Format   string
+	Width    int
+	Height   int
+	Quality  int
+	MaxBytes int
+	Overlay  *Overlay
+}
+
+// Result is a processed image along with what it needs to be uploaded as
+type Result struct {
+	Bytes     []byte
+	MimeType  string
+	Extension string
+	Width     int
+	Height    int
+}
+
+const minQuality = 40
+
+// Extension returns the file extension for a detected mime type
+func Extension(mimeType string) string {
+	switch mimeType {
+	case "image/png":
+		return ".png"
+	case "image/jpeg":
+		return ".jpg"
+	case "image/webp":
+		return ".webp"
+	case "image/gif":
+		return ".gif"
+	}
+	return ".img"
+}
+
+// Detect returns the mime type and extension of the image bytes, ignoring whatever name the engine gave them
+func Detect(imgBytes []byte) (string, string) {
+	mimeType := http.DetectContentType(imgBytes)
+	return mimeType, Extension(mimeType)
+}
+
+// Process decodes the image, resizes and crops it, then re-encodes it in the requested format.
+// Re-encoding drops EXIF and any other metadata the source carried.
+func Process(imgBytes []byte, opts Options) (Result, error) {
+	mimeType, extension := Detect(imgBytes)
+	format := opts.Format
+	keepOriginal := format == "" || format == "original"
+	if keepOriginal {
+		if opts.Overlay == nil && opts.Width <= 0 && opts.MaxBytes <= 0 {
+			return Result{Bytes: imgBytes, MimeType: mimeType, Extension: extension}, nil
+		}
+		//Resizing, compressing or drawing the overlay means re-encoding, in the source's format where it can be
+		switch mimeType {
+		case "image/png":
+			format = "png"
+		case "image/webp":
+			format = "webp"
+		default:
+			format = "jpeg"
+		}
+	}
+	src, _, err := image.Decode(bytes.NewReader(imgBytes))
+	if err != nil {
+		return Result{}, err
+	}
+	img := resize(src, opts.Width, opts.Height)
+	if keepOriginal && opts.Overlay == nil && img.Bounds().Size() == src.Bounds().Size() && (opts.MaxBytes <= 0 || len(imgBytes) <= opts.MaxBytes) {
+		//Already the right size, the untouched bytes are better than a second lossy encode
+		bounds := src.Bounds()
+		return Result{Bytes: imgBytes, MimeType: mimeType, Extension: extension, Width: bounds.Dx(), Height: bounds.Dy()}, nil
+	}
+	if opts.Overlay != nil {
+		img, err = applyOverlay(img, *opts.Overlay)
+		if err != nil {
+			return Result{}, err
+		}
+	}
+	if opts.Quality < 1 || opts.Quality > 100 {
+		opts.Quality = 82
+	}
+
+	if format == "webp" && !WebPAvailable() {
+		format = "jpeg"
+	}
+	var out []byte
+	switch format {
+	case "png":
+		out, err = encodePng(img)
+		mimeType = "image/png"
+	case "webp":
+		out, err = compress(img, opts, encodeWebP)
+		mimeType = "image/webp"
+	case "jpeg":
+		out, err = compress(img, opts, encodeJpeg)
+		mimeType = "image/jpeg"
+	default:
+		return Result{}, errors.New("Unknown image format: " + opts.Format)
+	}
+	if err != nil {
+		return Result{}, err
+	}
+	bounds := img.Bounds()
+	return Result{Bytes: out, MimeType: mimeType, Extension: Extension(mimeType), Width: bounds.Dx(), Height: bounds.Dy()}, nil
+}
+
+// WebPAvailable reports whether the cwebp encoder is installed, Go has no lossy WebP encoder of its own
+func WebPAvailable() bool {
+	_, err := exec.LookPath("cwebp")
+	return err == nil
+}
+
+// resize scales the image to cover width x height and crops the overflow from the center.
+// Images are never scaled up.
+func resize(src image.Image, width int, height int) image.Image {
+	bounds := src.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+	if width <= 0 {
+		return src
+	}
+	if height <= 0 {
+		if srcW <= width {
+			return src
+		}
+		height = srcH * width / srcW
+		return scale(src, bounds, width, height)
+	}
+
+	//Crop the source to the target aspect ratio first
+	crop := bounds
+	if srcW*height > srcH*width {
+		cropW := srcH * width / height
+		crop.Min.X += (srcW - cropW) / 2
+		crop.Max.X = crop.Min.X + cropW
+	} else {
+		cropH := srcW * height / width
+		crop.Min.Y += (srcH - cropH) / 2
+		crop.Max.Y = crop.Min.Y + cropH
+	}
+	if crop.Dx() < width {
+		width, height = crop.Dx(), crop.Dy()
+	}
+	return scale(src, crop, width, height)
+}
+
+func scale(src image.Image, srcRect image.Rectangle, width int, height int) image.Image {
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	xdraw.CatmullRom.Scale(dst, dst.Bounds(), src, srcRect, draw.Over, nil)
+	return dst
+}
+
+// compress encodes at the configured quality, stepping it down until the image fits the size target
+func compress(img image.Image, opts Options, encode func(image.Image, int) ([]byte, error)) ([]byte, error) {
+	quality := opts.Quality
+	for {
+		out, err := encode(img, quality)
+		if err != nil {
+			return nil, err
+		}
+		if opts.MaxBytes <= 0 || len(out) <= opts.MaxBytes || quality <= minQuality {
+			return out, nil
+		}
+		quality -= 8
+		if quality < minQuality {
+			quality = minQuality
+		}
+	}
+}
+
+func encodeJpeg(img image.Image, quality int) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	err := jpeg.Encode(buf, flatten(img), &jpeg.Options{Quality: quality})
+	return buf.Bytes(), err
+}
+
+func encodePng(img image.Image) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	encoder := png.Encoder{CompressionLevel: png.BestCompression}
+	err := encoder.Encode(buf, img)
+	return buf.Bytes(), err
+}
+
+// encodeWebP hands the image to cwebp as a PNG and reads the WebP back
+func encodeWebP(img image.Image, quality int) ([]byte, error) {
+	pngBytes, err := encodePng(img)
+	if err != nil {
+		return nil, err
+	}
+	in, err := os.CreateTemp("", "blogotron-*.png")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(in.Name())
+	_, err = in.Write(pngBytes)
+	in.Close()
+	if err != nil {
+		return nil, err
+	}
+	outName := in.Name() + ".webp"
+	defer os.Remove(outName)
+	output, err := exec.Command("cwebp", "-quiet", "-metadata", "none", "-q", strconv.Itoa(quality), in.Name(), "-o", outName).CombinedOutput()
+	if err != nil {
+		return nil, errors.New("cwebp failed: " + err.Error() + " " + string(output))
+	}
+	return os.ReadFile(outName)
+}
+
+// flatten puts transparent images on a white background since JPEG has no alpha channel
+func flatten(img image.Image) image.Image {
+	if opaque, ok := img.(interface{ Opaque() bool }); ok && opaque.Opaque() {
+		return img
+	}
+	bounds := img.Bounds()
+	dst := image.NewRGBA(bounds)
+	draw.Draw(dst, bounds, image.White, image.Point{}, draw.Src)
+	draw.Draw(dst, bounds, img, bounds.Min, draw.Over)
+	return dst
+}
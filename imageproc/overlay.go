@@ -0,0 +1,210 @@
+package imageproc
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"strconv"
+	"strings"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// Overlay is branding composited onto an image, the logo and text are each skipped when left empty.
+// LogoPosition is top-left, top-right, bottom-left, bottom-right or center, TextPosition is top, center or bottom.
+// LogoSize is a percent of the image width and FontSize a percent of its height.
+type Overlay struct {
+	Logo           []byte
+	LogoPosition   string
+	LogoSize       int
+	LogoOpacity    int
+	Text           string
+	Font           []byte
+	FontSize       int
+	TextColor      color.Color
+	TextPosition   string
+	TextBackground color.Color
+}
+
+// maxTextLines keeps long titles from covering the whole image, the last line is cut short instead
+const maxTextLines = 3
+
+// ParseColor reads a #rgb, #rrggbb or #rrggbbaa hex color
+func ParseColor(hex string) (color.Color, error) {
+	hex = strings.TrimPrefix(strings.TrimSpace(hex), "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+	if len(hex) != 8 {
+		return nil, errors.New("Colors must be #rgb, #rrggbb or #rrggbbaa: " + hex)
+	}
+	value, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return nil, errors.New("Colors must be #rgb, #rrggbb or #rrggbbaa: " + hex)
+	}
+	return color.NRGBA{R: uint8(value >> 24), G: uint8(value >> 16), B: uint8(value >> 8), A: uint8(value)}, nil
+}
+
+// applyOverlay draws the text and then the logo over a copy of the image
+func applyOverlay(src image.Image, overlay Overlay) (image.Image, error) {
+	bounds := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(dst, dst.Bounds(), src, bounds.Min, draw.Src)
+	margin := dst.Bounds().Dx() * 3 / 100
+	if dst.Bounds().Dy() < dst.Bounds().Dx() {
+		margin = dst.Bounds().Dy() * 3 / 100
+	}
+
+	if strings.TrimSpace(overlay.Text) != "" {
+		if err := drawText(dst, overlay, margin); err != nil {
+			return nil, err
+		}
+	}
+	if len(overlay.Logo) > 0 {
+		if err := drawLogo(dst, overlay, margin); err != nil {
+			return nil, err
+		}
+	}
+	return dst, nil
+}
+
+func drawLogo(dst *image.RGBA, overlay Overlay, margin int) error {
+	logo, _, err := image.Decode(bytes.NewReader(overlay.Logo))
+	if err != nil {
+		return errors.New("Error decoding overlay logo: " + err.Error())
+	}
+	size := overlay.LogoSize
+	if size < 1 || size > 100 {
+		size = 15
+	}
+	opacity := overlay.LogoOpacity
+	if opacity < 1 || opacity > 100 {
+		opacity = 100
+	}
+	logoBounds := logo.Bounds()
+	width := dst.Bounds().Dx() * size / 100
+	height := logoBounds.Dy() * width / logoBounds.Dx()
+	if width < 1 || height < 1 {
+		return nil
+	}
+	scaled := scale(logo, logoBounds, width, height)
+
+	var at image.Point
+	switch overlay.LogoPosition {
+	case "top-left":
+		at = image.Pt(margin, margin)
+	case "top-right":
+		at = image.Pt(dst.Bounds().Dx()-width-margin, margin)
+	case "bottom-left":
+		at = image.Pt(margin, dst.Bounds().Dy()-height-margin)
+	case "center":
+		at = image.Pt((dst.Bounds().Dx()-width)/2, (dst.Bounds().Dy()-height)/2)
+	default:
+		at = image.Pt(dst.Bounds().Dx()-width-margin, dst.Bounds().Dy()-height-margin)
+	}
+	mask := image.NewUniform(color.Alpha{A: uint8(255 * opacity / 100)})
+	draw.DrawMask(dst, image.Rectangle{Min: at, Max: at.Add(image.Pt(width, height))}, scaled, image.Point{}, mask, image.Point{}, draw.Over)
+	return nil
+}
+
+func drawText(dst *image.RGBA, overlay Overlay, margin int) error {
+	fontBytes := overlay.Font
+	if len(fontBytes) == 0 {
+		fontBytes = gobold.TTF
+	}
+	parsed, err := opentype.Parse(fontBytes)
+	if err != nil {
+		return errors.New("Error reading overlay font: " + err.Error())
+	}
+	fontSize := overlay.FontSize
+	if fontSize < 1 || fontSize > 50 {
+		fontSize = 7
+	}
+	face, err := opentype.NewFace(parsed, &opentype.FaceOptions{
+		Size:    float64(dst.Bounds().Dy() * fontSize / 100),
+		DPI:     72,
+		Hinting: font.HintingFull,
+	})
+	if err != nil {
+		return errors.New("Error loading overlay font: " + err.Error())
+	}
+	defer face.Close()
+
+	textColor := overlay.TextColor
+	if textColor == nil {
+		textColor = color.White
+	}
+	maxWidth := dst.Bounds().Dx() - margin*4
+	lines := wrapText(face, overlay.Text, maxWidth)
+	metrics := face.Metrics()
+	lineHeight := (metrics.Ascent + metrics.Descent).Ceil()
+	blockHeight := lineHeight * len(lines)
+
+	top := dst.Bounds().Dy() - blockHeight - margin*2
+	switch overlay.TextPosition {
+	case "top":
+		top = margin * 2
+	case "center":
+		top = (dst.Bounds().Dy() - blockHeight) / 2
+	}
+	if overlay.TextBackground != nil {
+		band := image.Rect(0, top-margin, dst.Bounds().Dx(), top+blockHeight+margin)
+		draw.Draw(dst, band, image.NewUniform(overlay.TextBackground), image.Point{}, draw.Over)
+	}
+
+	drawer := &font.Drawer{Dst: dst, Face: face}
+	for i, line := range lines {
+		lineWidth := drawer.MeasureString(line).Ceil()
+		x := (dst.Bounds().Dx() - lineWidth) / 2
+		y := top + lineHeight*i + metrics.Ascent.Ceil()
+		//Without a background band a soft shadow keeps the text readable on light images
+		if overlay.TextBackground == nil {
+			offset := lineHeight/30 + 1
+			drawer.Src = image.NewUniform(color.NRGBA{A: 160})
+			drawer.Dot = fixed.P(x+offset, y+offset)
+			drawer.DrawString(line)
+		}
+		drawer.Src = image.NewUniform(textColor)
+		drawer.Dot = fixed.P(x, y)
+		drawer.DrawString(line)
+	}
+	return nil
+}
+
+// wrapText splits the text into lines that fit maxWidth, at most maxTextLines of them
+func wrapText(face font.Face, text string, maxWidth int) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(text) {
+		candidate := word
+		if line != "" {
+			candidate = line + " " + word
+		}
+		if line != "" && font.MeasureString(face, candidate).Ceil() > maxWidth {
+			lines = append(lines, line)
+			line = word
+		} else {
+			line = candidate
+		}
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	if len(lines) > maxTextLines {
+		lines = lines[:maxTextLines]
+		words := strings.Fields(lines[maxTextLines-1])
+		for len(words) > 1 && font.MeasureString(face, strings.Join(words, " ")+"…").Ceil() > maxWidth {
+			words = words[:len(words)-1]
+		}
+		lines[maxTextLines-1] = strings.Join(words, " ") + "…"
+	}
+	return lines
+}
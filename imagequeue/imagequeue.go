@@ -0,0 +1,169 @@
+package imagequeue
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Priority orders the jobs waiting on an engine, higher runs first and equal priorities run in the order they were queued
+type Priority int
+
+const (
+	PriorityAuto   Priority = 0
+	PriorityManual Priority = 10
+)
+
+func (p Priority) String() string {
+	if p >= PriorityManual {
+		return "manual"
+	}
+	return "auto"
+}
+
+// Job is an image request waiting for or holding one of its engine's slots
+type Job struct {
+	Id          int
+	Engine      string
+	Description string
+	Priority    Priority
+	Running     bool
+	QueuedAt    time.Time
+	StartedAt   time.Time
+	cancel      context.CancelFunc
+	ready       chan struct{}
+}
+
+// Queue limits how many jobs run at once on each engine, Limit returns that number for an engine
+type Queue struct {
+	Limit   func(engine string) int
+	mu      sync.Mutex
+	next    int
+	jobs    []*Job
+	running map[string]int
+}
+
+func New(limit func(engine string) int) *Queue {
+	return &Queue{Limit: limit, running: map[string]int{}}
+}
+
+// Do waits for a free slot on the engine then runs fn with it.
+// The context fn gets is cancelled by Cancel and CancelAll as well as by ctx.
+func (q *Queue) Do(ctx context.Context, engine string, description string, priority Priority, fn func(ctx context.Context) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	q.mu.Lock()
+	q.next++
+	job := &Job{
+		Id:          q.next,
+		Engine:      engine,
+		Description: description,
+		Priority:    priority,
+		QueuedAt:    time.Now(),
+		cancel:      cancel,
+		ready:       make(chan struct{}),
+	}
+	q.jobs = append(q.jobs, job)
+	q.dispatch(engine)
+	q.mu.Unlock()
+	defer q.finish(job)
+
+	select {
+	case <-job.ready:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return fn(ctx)
+}
+
+// finish drops the job and hands its slot, if it had one, to the next job waiting on the engine
+func (q *Queue) finish(job *Job) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i, queued := range q.jobs {
+		if queued == job {
+			q.jobs = append(q.jobs[:i], q.jobs[i+1:]...)
+			break
+		}
+	}
+	if job.Running {
+		q.running[job.Engine]--
+	}
+	q.dispatch(job.Engine)
+}
+
+// dispatch starts waiting jobs on the engine while it has free slots, callers hold mu
+func (q *Queue) dispatch(engine string) {
+	limit := q.Limit(engine)
+	if limit < 1 {
+		limit = 1
+	}
+	for q.running[engine] < limit {
+		var nextJob *Job
+		for _, job := range q.jobs {
+			if job.Engine != engine || job.Running {
+				continue
+			}
+			if nextJob == nil || job.Priority > nextJob.Priority {
+				nextJob = job
+			}
+		}
+		if nextJob == nil {
+			return
+		}
+		nextJob.Running = true
+		nextJob.StartedAt = time.Now()
+		q.running[engine]++
+		close(nextJob.ready)
+	}
+}
+
+// Jobs lists the running jobs and then the queued ones in the order they will run
+func (q *Queue) Jobs() []Job {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	jobs := make([]Job, 0, len(q.jobs))
+	for _, job := range q.jobs {
+		jobs = append(jobs, Job{
+			Id:          job.Id,
+			Engine:      job.Engine,
+			Description: job.Description,
+			Priority:    job.Priority,
+			Running:     job.Running,
+			QueuedAt:    job.QueuedAt,
+			StartedAt:   job.StartedAt,
+		})
+	}
+	sort.SliceStable(jobs, func(i, j int) bool {
+		if jobs[i].Running != jobs[j].Running {
+			return jobs[i].Running
+		}
+		return jobs[i].Priority > jobs[j].Priority
+	})
+	return jobs
+}
+
+// Cancel stops a queued or running job, returning whether it was found
+func (q *Queue) Cancel(id int) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, job := range q.jobs {
+		if job.Id == id {
+			job.cancel()
+			return true
+		}
+	}
+	return false
+}
+
+// CancelAll stops every job, returning how many there were
+func (q *Queue) CancelAll() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, job := range q.jobs {
+		job.cancel()
+	}
+	return len(q.jobs)
+}
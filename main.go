@@ -12,19 +12,34 @@ import (
 	"github.com/go-co-op/gocron"
 	"github.com/golang-migrate/migrate/v4/source/iofs"
 	"golang/api"
+	"golang/bulk"
+	"golang/feeds"
+	"golang/imagegen"
+	"golang/imageproc"
+	"golang/imagequeue"
 	"golang/models"
 	"golang/openai"
-	"golang/stablediffusion"
+	"golang/social"
+	"golang/stockimg"
 	"golang/unsplash"
 	"golang/util"
+	"golang/webhooks"
+	"html"
 	"html/template"
 	"io"
 	"io/ioutil"
+	"math/rand"
 	"mime/multipart"
 	"net/http"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	texttemplate "text/template"
 	"time"
 )
 
@@ -53,6 +68,20 @@ var (
 
 type Restart struct{}
 
+// imageQueue runs image generations in priority order without overloading an engine, see IMG_CONCURRENCY
+var imageQueue = imagequeue.New(imageConcurrency)
+
+// LanguageNames are the languages articles can be written in or translated to, keyed by ISO 639-1 code
+var LanguageNames = map[string]string{
+	"en": "English",
+	"es": "Spanish",
+	"de": "German",
+	"fr": "French",
+	"it": "Italian",
+	"pt": "Portuguese",
+	"nl": "Dutch",
+}
+
 func main() {
 	util.Init()
 	util.Logger.Info().Msg("Starting Blogotron")
@@ -133,9 +162,6 @@ func startCronSrv() {
 	//Cron Service
 	autoPost := Settings["AUTO_POST_ENABLE"]
 	autoPostInterval := Settings["AUTO_POST_INTERVAL"]
-	autoPostImgEngine := Settings["AUTO_POST_IMG_ENGINE"]
-	autoPostLen := Settings["AUTO_POST_LEN"]
-	autoPostState := Settings["AUTO_POST_STATE"]
 	lowIdeaThreshold := Settings["LOW_IDEA_THRESHOLD"]
 	cronSrv = gocron.NewScheduler(time.UTC)
 	iThreshold, convErr := strconv.Atoi(lowIdeaThreshold)
@@ -157,49 +183,56 @@ func startCronSrv() {
 	} else {
 		util.Logger.Info().Msg("Auto Idea Generation Disabled")
 	}
+	//Failed webhook deliveries wait in the log for their next attempt, including ones left over from before a restart
+	_, err := cronSrv.Every("30s").Do(webhooks.RetryDue)
+	if err != nil {
+		util.Logger.Error().Err(err).Msg("Error scheduling webhook retries")
+	}
+	if feedInterval := Settings["FEED_INTERVAL"]; feedInterval != "" {
+		util.Logger.Info().Msg("Feed Watcher Enabled - Interval Set to " + feedInterval)
+		_, err := cronSrv.Every(feedInterval).Do(checkFeeds)
+		if err != nil {
+			util.Logger.Error().Err(err).Msg("Error scheduling feed watcher")
+		}
+	} else {
+		util.Logger.Info().Msg("Feed Watcher Disabled")
+	}
 	if autoPost == "true" {
 		util.Logger.Info().Msg("Auto Post Enabled - Interval Set to " + autoPostInterval)
 		cronSrv.Every(autoPostInterval).Do(func() {
 			util.Logger.Info().Msg("Auto Post Triggered")
-			//Get a Random Idea
-			idea := models.GetRandomIdea()
+			//Pick the next idea
+			idea, err := pickIdea(Settings["AUTO_POST_STRATEGY"])
+			if err != nil {
+				util.Logger.Error().Err(err).Msg("Error picking idea")
+			}
 			if idea.Id == 0 {
-				util.Logger.Info().Msg("Could not get random idea")
+				util.Logger.Info().Msg("Could not pick an idea")
 			} else {
-				util.Logger.Info().Msg("Random Idea: " + idea.IdeaText)
-				//Create a new post from the idea
-				iLen, convErr := strconv.Atoi(autoPostLen)
-				if convErr != nil {
-					iLen = 750
-				}
-				publishStatus := autoPostState
-				unsplashSearch := ""
-				unsplashImg := false
-				generateImg := false
-				if autoPostImgEngine == "unsplash" {
-					unsplashImg = true
-				} else if autoPostImgEngine == "generate" {
-					generateImg = true
-				}
+				util.Logger.Info().Msg("Picked Idea: " + idea.IdeaText)
+				//Create a new post from the idea, the length, post state and image source are left to the
+				//idea's overrides and then the AUTO_POST_ settings
+				stockSearch := ""
 
 				post := Post{
 					Prompt:         idea.IdeaText,
-					Length:         iLen,
-					PublishStatus:  publishStatus,
 					UseGpt4:        false,
 					ConceptAsTitle: false,
 					IncludeYt:      false,
-					GenerateImg:    generateImg,
 					DownloadImg:    false,
-					UnsplashImg:    unsplashImg,
 					IdeaId:         strconv.Itoa(idea.Id),
-					UnsplashSearch: unsplashSearch,
+					StockSearch:    stockSearch,
 					Concept:        idea.IdeaConcept,
+					SeriesId:       idea.SeriesId,
+					Language:       idea.Language,
+					Keyword:        idea.Keyword,
+					AutoPost:       true,
 				}
 
-				err, post := writeArticle(post)
+				err, post := writeIdeaArticle(post)
 				if err != nil {
 					util.Logger.Error().Err(err).Msg("Could not write article")
+					webhooks.Fire(webhooks.JobFailed, JobEvent{Job: "auto-post", Error: err.Error(), IdeaId: idea.Id})
 				}
 			}
 		})
@@ -233,9 +266,11 @@ func startWebSrv() {
 	mux.HandleFunc("/idea", ideaHandler)
 	mux.HandleFunc("/ideaSave", ideaSaveHandler)
 	mux.HandleFunc("/ideaDel", ideaRemoveHandler)
+	mux.HandleFunc("/ideaStatus", ideaStatusHandler)
 	mux.HandleFunc("/series", seriesHandler)
 	mux.HandleFunc("/seriesList", seriesListHandler)
 	mux.HandleFunc("/seriesSave", seriesSaveHandler)
+	mux.HandleFunc("/seriesMove", seriesMoveHandler)
 	mux.HandleFunc("/settings", settingsHandler)
 	mux.HandleFunc("/settingsSave", settingsSaveHandler)
 	mux.HandleFunc("/templates", templateHandler)
@@ -245,6 +280,43 @@ func startWebSrv() {
 	mux.HandleFunc("/restart", restartHandler)
 	mux.HandleFunc("/articles", articleListHandler)
 	mux.HandleFunc("/article", articleHandler)
+	mux.HandleFunc("/articleTranslate", articleTranslateHandler)
+	mux.HandleFunc("/articleShare", articleShareHandler)
+	mux.HandleFunc("/review", reviewHandler)
+	mux.HandleFunc("/reviewAction", reviewActionHandler)
+	mux.HandleFunc("/imagePick", imagePickHandler)
+	mux.HandleFunc("/imagePickSave", imagePickSaveHandler)
+	mux.HandleFunc("/media", mediaListHandler)
+	mux.HandleFunc("/mediaFile", mediaFileHandler)
+	mux.HandleFunc("/mediaDel", mediaDeleteHandler)
+	mux.HandleFunc("/concepts", conceptListHandler)
+	mux.HandleFunc("/concept", conceptHandler)
+	mux.HandleFunc("/conceptSave", conceptSaveHandler)
+	mux.HandleFunc("/personas", personaListHandler)
+	mux.HandleFunc("/persona", personaHandler)
+	mux.HandleFunc("/personaSave", personaSaveHandler)
+	mux.HandleFunc("/personaDel", personaRemoveHandler)
+	mux.HandleFunc("/ideaImport", ideaImportHandler)
+	mux.HandleFunc("/export", exportHandler)
+	mux.HandleFunc("/feeds", feedListHandler)
+	mux.HandleFunc("/feed", feedHandler)
+	mux.HandleFunc("/feedSave", feedSaveHandler)
+	mux.HandleFunc("/feedDel", feedRemoveHandler)
+	mux.HandleFunc("/feedCheck", feedCheckHandler)
+	mux.HandleFunc("/webhooks", webhookListHandler)
+	mux.HandleFunc("/webhook", webhookHandler)
+	mux.HandleFunc("/webhookSave", webhookSaveHandler)
+	mux.HandleFunc("/webhookDel", webhookRemoveHandler)
+	mux.HandleFunc("/webhookTest", webhookTestHandler)
+	mux.HandleFunc("/webhookRedeliver", webhookRedeliverHandler)
+	mux.HandleFunc("/experiments", experimentsHandler)
+	mux.HandleFunc("/experimentViews", experimentViewsHandler)
+	mux.HandleFunc("/variant", variantHandler)
+	mux.HandleFunc("/variantSave", variantSaveHandler)
+	mux.HandleFunc("/variantDel", variantRemoveHandler)
+	mux.HandleFunc("/imageProgress", imageProgressHandler)
+	mux.HandleFunc("/imageCancel", imageCancelHandler)
+	mux.HandleFunc("/imageQueue", imageQueueHandler)
 	mux.Handle("/assets/", http.StripPrefix("/assets/", fs))
 	webSrv = &http.Server{Addr: ":" + webPort, Handler: mux}
 
@@ -315,59 +387,180 @@ func stopCronSrv() {
 	util.Logger.Info().Msg("Cron Server Stopped")
 }
 
-func generateSizedImage(p string, iWidth int, iHeight int) ([]byte, error) {
-	var imgBytes []byte
-	imgSampler := Settings["IMG_SAMPLER"]
-	imgUpscaler := Settings["IMG_UPSCALER"]
-	imgNegativePrompts := Settings["IMG_NEGATIVE_PROMPTS"]
-	imgSteps := Settings["IMG_STEPS"]
-	iSteps, err := strconv.Atoi(imgSteps)
+func generateSizedImage(engine string, p string, iWidth int, iHeight int, priority imagequeue.Priority) ([]byte, error) {
+	images, err := generateSizedImages(engine, imagegen.ImageRequest{Prompt: p, Width: iWidth, Height: iHeight, Count: 1}, priority)
+	if err != nil || len(images) == 0 {
+		return nil, err
+	}
+	return images[0].Data, nil
+}
+
+// imageEngine returns the named image engine, or the one selected by IMG_MODE when no engine is named
+func imageEngine(engine string) (imagegen.ImageGenerator, bool) {
+	if engine == "" {
+		engine = Settings["IMG_MODE"]
+	}
+	return imagegen.Get(engine)
+}
+
+// generateSizedImages queues the request for the named image engine, empty for the one selected by IMG_MODE
+func generateSizedImages(engine string, req imagegen.ImageRequest, priority imagequeue.Priority) ([]imagegen.Image, error) {
+	if req.Prompt == "" {
+		return nil, nil
+	}
+	generator, ok := imageEngine(engine)
+	if !ok {
+		return nil, nil
+	}
+	//The timeout only starts once the job has its turn on the engine
+	timeout := imageTimeout()
+	var images []imagegen.Image
+	err := imageQueue.Do(context.Background(), generator.Name(), req.Prompt, priority, func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		var err error
+		images, err = generator.Generate(ctx, Settings, req)
+		return err
+	})
+	if errors.Is(err, context.DeadlineExceeded) {
+		//Stop the engine too, otherwise it keeps working on an image nobody is waiting for
+		interruptImageEngine(generator.Name())
+		err = errors.New("Image generation timed out after " + timeout.String())
+	} else if errors.Is(err, context.Canceled) {
+		return nil, errors.New("Image generation was cancelled")
+	} else if err == nil && len(images) == 0 {
+		err = errors.New("No image returned from " + generator.Label())
+	}
 	if err != nil {
-		iSteps = 30
+		webhooks.Fire(webhooks.JobFailed, JobEvent{Job: "image-generation", Error: err.Error(), Detail: req.Prompt})
+		return nil, err
 	}
+	return images, nil
+}
 
-	if p != "" {
-		imgMode := Settings["IMG_MODE"]
-		if imgMode == "openai" {
-			aiApiKey := Settings["OPENAI_API_KEY"]
-			imgBytes, err = openai.GenerateImg(p, aiApiKey)
-			if err != nil {
-				return nil, err
-			}
-		} else if imgMode == "sd" {
-			sdUrl := Settings["SD_URL"]
-			ctx := context.Background()
-			images, err := stablediffusion.Generate(sdUrl, ctx, stablediffusion.SimpleImageRequest{
-				Prompt:                            p,
-				NegativePrompt:                    imgNegativePrompts,
-				Styles:                            nil,
-				Seed:                              -1,
-				SamplerName:                       imgSampler,
-				BatchSize:                         1,
-				NIter:                             1,
-				Steps:                             iSteps,
-				CfgScale:                          7,
-				Width:                             iWidth,
-				Height:                            iHeight,
-				SNoise:                            0,
-				OverrideSettings:                  struct{}{},
-				OverrideSettingsRestoreAfterwards: false,
-				SaveImages:                        true,
-				EnableHr:                          true,
-				HrScale:                           2,
-				HrUpscaler:                        imgUpscaler,
-			})
-			if err != nil {
-				return nil, err
-			} else {
-				imgBytes = images.Images[0]
-			}
+// imageTimeout is how long to wait on the image engine, from IMG_TIMEOUT in seconds
+func imageTimeout() time.Duration {
+	seconds, err := strconv.Atoi(Settings["IMG_TIMEOUT"])
+	if err != nil || seconds < 1 {
+		seconds = 600
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// imageConcurrency is how many images an engine may generate at once, from IMG_CONCURRENCY.
+// It is a comma separated list of engine=limit, engines left out get 1.
+func imageConcurrency(engine string) int {
+	for _, limit := range strings.Split(Settings["IMG_CONCURRENCY"], ",") {
+		name, value, found := strings.Cut(strings.TrimSpace(limit), "=")
+		if !found || strings.TrimSpace(name) != engine {
+			continue
+		}
+		count, err := strconv.Atoi(strings.TrimSpace(value))
+		if err == nil && count > 0 {
+			return count
+		}
+	}
+	return 1
+}
+
+// cancelImageJobs stops the queued and running image generations, returning how many there were
+func cancelImageJobs() int {
+	engines := map[string]bool{}
+	for _, job := range imageQueue.Jobs() {
+		if job.Running {
+			engines[job.Engine] = true
+		}
+	}
+	cancelled := imageQueue.CancelAll()
+	for engine := range engines {
+		interruptImageEngine(engine)
+	}
+	return cancelled
+}
+
+// cancelImageJob stops one queued or running image generation
+func cancelImageJob(jobId int) bool {
+	engine := ""
+	for _, job := range imageQueue.Jobs() {
+		if job.Id == jobId && job.Running {
+			engine = job.Engine
+		}
+	}
+	if !imageQueue.Cancel(jobId) {
+		return false
+	}
+	if engine != "" {
+		interruptImageEngine(engine)
+	}
+	return true
+}
+
+// interruptImageEngine asks an engine to stop its current image, when it supports that
+func interruptImageEngine(engine string) {
+	generator, ok := imageEngine(engine)
+	if !ok {
+		return
+	}
+	interrupter, ok := generator.(imagegen.Interrupter)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	err := interrupter.Interrupt(ctx, Settings)
+	if err != nil {
+		util.Logger.Error().Err(err).Msg("Error interrupting " + generator.Label())
+	}
+}
+
+// imageProgress reports on the running engine's current image, Active is false when nothing is generating
+func imageProgress() imagegen.Progress {
+	running := false
+	engine := ""
+	for _, job := range imageQueue.Jobs() {
+		if job.Running && !running {
+			running = true
+			engine = job.Engine
 		}
 	}
-	return imgBytes, nil
+	if !running {
+		return imagegen.Progress{Active: len(imageQueue.Jobs()) > 0}
+	}
+	progress := imagegen.Progress{Active: true}
+	generator, ok := imageEngine(engine)
+	if !ok {
+		return progress
+	}
+	reporter, ok := generator.(imagegen.ProgressReporter)
+	if !ok {
+		return progress
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	progress, err := reporter.Progress(ctx, Settings)
+	if err != nil {
+		util.Logger.Error().Err(err).Msg("Error getting progress from " + generator.Label())
+	}
+	progress.Active = true
+	return progress
 }
 
-func generateImage(p string) ([]byte, error) {
+// generateImages generates at the configured IMG_WIDTH and IMG_HEIGHT on the post's image engine, with its series branding
+func generateImages(p string, count int, post Post) ([]imagegen.Image, error) {
+	req := imagegen.ImageRequest{
+		Prompt: p,
+		Count:  count,
+		Model:  post.ImgModel,
+		Style:  post.ImgStyle,
+		Lora:   post.ImgLora,
+	}
+	if post.RefMediaId > 0 {
+		refBytes, _, err := loadFromLibrary(post.RefMediaId)
+		if err != nil {
+			return nil, err
+		}
+		req.InitImage = refBytes
+	}
 
 	imgWidth := Settings["IMG_WIDTH"]
 	imgHeight := Settings["IMG_HEIGHT"]
@@ -380,14 +573,193 @@ func generateImage(p string) ([]byte, error) {
 	if err != nil {
 		iHeight = 512
 	}
-	return generateSizedImage(p, iWidth, iHeight)
+	req.Width = iWidth
+	req.Height = iHeight
+	priority := imagequeue.PriorityManual
+	if post.AutoPost {
+		priority = imagequeue.PriorityAuto
+	}
+	return generateSizedImages(post.ImgMode, req, priority)
+}
+
+// imageCandidateCount is how many images to fetch per article so one can be picked, capped to keep requests reasonable
+func imageCandidateCount() int {
+	count, err := strconv.Atoi(Settings["IMG_CANDIDATES"])
+	if err != nil || count < 1 {
+		return 1
+	}
+	if count > 8 {
+		return 8
+	}
+	return count
+}
+
+// pickImage chooses the featured image for auto-posts, where nobody is around to pick, using the IMG_AUTO_PICK rule.
+// It returns the index of the chosen candidate.
+func pickImage(candidates [][]byte, rule string) int {
+	switch rule {
+	case "random":
+		return rand.Intn(len(candidates))
+	case "largest":
+		//The largest file is usually the most detailed image
+		largest := 0
+		for i, candidate := range candidates {
+			if len(candidate) > len(candidates[largest]) {
+				largest = i
+			}
+		}
+		return largest
+	}
+	return 0
+}
+
+// searchStockPhotos asks the providers in STOCK_PROVIDERS order until one has photos for the search
+func searchStockPhotos(search string, count int) ([]stockimg.Photo, error) {
+	var order []string
+	for _, name := range strings.Split(Settings["STOCK_PROVIDERS"], ",") {
+		if strings.TrimSpace(name) != "" {
+			order = append(order, strings.TrimSpace(name))
+		}
+	}
+	return stockimg.Search(context.Background(), Settings, order, search, count)
+}
+
+// setStockPhotos makes the search results the post's image candidates, keeping the credit and license for each
+func setStockPhotos(post Post, photos []stockimg.Photo) Post {
+	post.ImageCandidates = nil
+	for _, photo := range photos {
+		post.ImageCandidates = append(post.ImageCandidates, photo.Image)
+	}
+	post.StockPhotos = photos
+	post.Image = photos[0].Image
+	return post
+}
+
+// trackStockDownload reports a photo as used to providers that ask for it, failures are only logged since the post can go ahead without it
+func trackStockDownload(photo stockimg.Photo) {
+	err := stockimg.Track(context.Background(), Settings, photo)
+	if err != nil {
+		util.Logger.Error().Err(err).Msg("Error tracking " + photo.Provider + " download")
+	}
+}
+
+// addImageCredit appends the featured image's attribution to the end of the post when IMG_CREDIT_ENABLE is on
+func addImageCredit(content string, credit string) string {
+	if credit == "" || Settings["IMG_CREDIT_ENABLE"] != "true" {
+		return content
+	}
+	return content + "\n<p class=\"image-credit\"><em>" + credit + "</em></p>"
+}
+
+// writeIdeaArticle writes an article, holding its idea IN-PROGRESS until it is posted and putting the idea back
+// where it was if writing fails
+func writeIdeaArticle(post Post) (error, Post) {
+	if post.IdeaId == "" {
+		return writeArticle(post)
+	}
+	idea, err := models.GetIdeaById(post.IdeaId)
+	if err != nil {
+		return err, post
+	}
+	if idea.Id == 0 {
+		return writeArticle(post)
+	}
+	_, err = models.SetIdeaStatus(post.IdeaId, models.IdeaInProgress, ideaActor(post), "")
+	if err != nil {
+		return err, post
+	}
+	err, post = writeArticle(post)
+	if err != nil {
+		_, setErr := models.SetIdeaStatus(post.IdeaId, idea.Status, ideaActor(post), "Writing failed: "+err.Error())
+		if setErr != nil {
+			util.Logger.Error().Err(setErr).Msg("Error restoring idea status")
+		}
+	}
+	return err, post
+}
+
+// ideaActor names who moved an idea along in its history
+func ideaActor(post Post) string {
+	if post.AutoPost {
+		return "auto-post"
+	}
+	return "web"
+}
+
+// setIdeaPosted moves an idea on once its article is on WordPress, to PUBLISHED when it went out live and to
+// WRITTEN when it is a draft
+func setIdeaPosted(ideaId string, publishStatus string, changedBy string, postId int) {
+	if ideaId == "" {
+		return
+	}
+	status := models.IdeaWritten
+	if publishStatus == "publish" {
+		status = models.IdeaPublished
+	}
+	_, err := models.SetIdeaStatus(ideaId, status, changedBy, "WordPress post "+strconv.Itoa(postId))
+	if err != nil {
+		util.Logger.Error().Err(err).Msg("Error updating idea status")
+	}
 }
 
 func writeArticle(post Post) (error, Post) {
 	newImgPrompt := ""
+	imgSource := ""
 	article := ""
 	title := ""
 	aiApiKey := Settings["OPENAI_API_KEY"]
+	post.Language = resolveLanguage(post)
+	systemPrompt := localizedTemplate("system-prompt", post.Language)
+	articlePrompt := localizedTemplate("article-prompt", post.Language)
+	post, oSystemPrompt, oArticlePrompt, err := applyOverrides(post, systemPrompt, articlePrompt)
+	if err != nil {
+		return err, post
+	}
+	post = applyPostDefaults(post)
+	//An override's own prompts would replace the variant's, so the article is kept out of the experiment
+	promptOverride := oSystemPrompt != systemPrompt || oArticlePrompt != articlePrompt
+	systemPrompt, articlePrompt = oSystemPrompt, oArticlePrompt
+	if promptOverride {
+		post.VariantId = 0
+	} else if Settings["PROMPT_EXPERIMENT_ENABLE"] == "true" && post.VariantId == 0 {
+		variant := models.GetRandomActiveVariant()
+		if variant.Id > 0 {
+			util.Logger.Info().Msg("Experiment assigned prompt variant: " + variant.VariantName)
+			post.VariantId = variant.Id
+		}
+	}
+	if post.VariantId > 0 {
+		variant, err := models.GetPromptVariantById(strconv.Itoa(post.VariantId))
+		if err != nil {
+			return err, post
+		}
+		if strings.TrimSpace(variant.SystemPrompt) != "" {
+			systemPrompt = variant.SystemPrompt
+		}
+		if strings.TrimSpace(variant.ArticlePrompt) != "" {
+			articlePrompt = variant.ArticlePrompt
+		}
+	}
+	if post.WpCategory == 0 {
+		post.WpCategory, _ = strconv.Atoi(Settings["WP_CATEGORY_ID"])
+	}
+	if post.PersonaId == 0 {
+		post.PersonaId, _ = strconv.Atoi(Settings["DEFAULT_PERSONA_ID"])
+	}
+	persona := models.Persona{}
+	if post.PersonaId > 0 {
+		persona, err = models.GetPersonaById(strconv.Itoa(post.PersonaId))
+		if err != nil {
+			return err, post
+		}
+		post.PersonaId = persona.Id
+		systemPrompt = buildPersonaPrompt(systemPrompt, persona)
+	}
+	systemPrompt = addLanguageInstruction(systemPrompt, post.Language)
+	series, parts, partIndex, err := seriesPart(post.IdeaId)
+	if err != nil {
+		return err, post
+	}
 	if post.Prompt != "" {
 		if post.Keyword == "" {
 			kwTmpl := template.Must(template.New("keyword-prompt").Parse(openai.KeywordTemplate))
@@ -396,20 +768,27 @@ func writeArticle(post Post) (error, Post) {
 			if err != nil {
 				return err, post
 			}
-			keywordResp, err := openai.GenerateKeywords(aiApiKey, post.UseGpt4, keywordPrompt.String(), Templates["system-prompt"])
+			keywordResp, err := openai.GenerateKeywords(aiApiKey, post.UseGpt4, keywordPrompt.String(), systemPrompt)
 			if err != nil {
 				return err, post
 			}
 			post.Keyword = keywordResp
 		}
-		wpTmpl := template.Must(template.New("web-prompt").Parse(Templates["article-prompt"]))
+		wpTmpl := template.Must(template.New("web-prompt").Parse(articlePrompt))
 		webPrompt := new(bytes.Buffer)
 		err := wpTmpl.Execute(webPrompt, post)
 		if err != nil {
 			return err, post
 		}
+		if partIndex >= 0 {
+			partPrompt, err := renderSeriesTemplate("series-part-prompt", post.Language, seriesPartData(series, parts, partIndex))
+			if err != nil {
+				return err, post
+			}
+			webPrompt.WriteString("\n\n" + partPrompt)
+		}
 		util.Logger.Info().Msg("Generating Article from Prompt" + webPrompt.String() + "")
-		articleResp, err := openai.GenerateArticle(aiApiKey, post.UseGpt4, webPrompt.String(), Templates["system-prompt"])
+		articleResp, err := openai.GenerateArticle(aiApiKey, post.UseGpt4, webPrompt.String(), systemPrompt)
 		if err != nil {
 			return err, post
 		}
@@ -427,7 +806,7 @@ func writeArticle(post Post) (error, Post) {
 		}
 		if title == "" {
 			if !post.ConceptAsTitle {
-				titleResp, err := openai.GenerateTitle(aiApiKey, false, article, Templates["title-prompt"], Templates["system-prompt"])
+				titleResp, err := openai.GenerateTitle(aiApiKey, false, article, localizedTemplate("title-prompt", post.Language), systemPrompt)
 				if err != nil {
 					return err, post
 				}
@@ -436,12 +815,18 @@ func writeArticle(post Post) (error, Post) {
 				title = post.Prompt
 			}
 		}
+		if persona.Id > 0 {
+			article, post.PersonaFlags = checkPersona(aiApiKey, post.UseGpt4, article, persona, systemPrompt)
+		}
+		if Settings["FACT_CHECK_ENABLE"] == "true" {
+			post = factCheckArticle(aiApiKey, post, article, systemPrompt)
+		}
 		//Generate description
 		if post.Description == "" {
-			descTmpl := template.Must(template.New("description-prompt").Parse(Templates["description-prompt"]))
+			descTmpl := template.Must(template.New("description-prompt").Parse(localizedTemplate("description-prompt", post.Language)))
 			descPrompt := new(bytes.Buffer)
 			err := descTmpl.Execute(descPrompt, post)
-			descResp, err := openai.GenerateDescription(aiApiKey, false, article, descPrompt.String(), Templates["system-prompt"])
+			descResp, err := openai.GenerateDescription(aiApiKey, false, article, descPrompt.String(), systemPrompt)
 			if err != nil {
 				return err, post
 			}
@@ -457,18 +842,37 @@ func writeArticle(post Post) (error, Post) {
 		if strings.HasSuffix(title, "\"") {
 			title = strings.TrimSuffix(title, "\"")
 		}
+		if partIndex >= 0 && series.PartTitles {
+			partData := seriesPartData(series, parts, partIndex)
+			partData.Title = title
+			title, err = renderSeriesTemplate("series-part-title", post.Language, partData)
+			if err != nil {
+				return err, post
+			}
+		}
 		post.Content = article
 		post.Title = title
 	} else {
 		post.Error = "Please input an article idea first."
 	}
 
-	if post.Error == "" && post.GenerateImg {
+	if post.Error == "" && post.LibraryMediaId > 0 {
+		imgBytes, media, err := loadFromLibrary(post.LibraryMediaId)
+		if err != nil {
+			return err, post
+		}
+		post.Image = imgBytes
+		post.ImageCredit = media.Credit
+		newImgPrompt = media.Prompt
+		if _, generated := imagegen.Get(media.Engine); generated {
+			post.ImageGenPrompt = media.Prompt
+		}
+	} else if post.Error == "" && post.GenerateImg {
 		if post.ImagePrompt == "" {
 			igTmpl := template.Must(template.New("imggen-prompt").Parse(Templates["imggen-prompt"]))
 			imgGenPrompt := new(bytes.Buffer)
 			err := igTmpl.Execute(imgGenPrompt, post)
-			imgGenResp, err := openai.GenerateImagePrompt(aiApiKey, false, title, imgGenPrompt.String(), Templates["system-prompt"])
+			imgGenResp, err := openai.GenerateImagePrompt(aiApiKey, false, title, imgGenPrompt.String(), systemPrompt)
 			if err != nil {
 				return err, post
 			}
@@ -485,12 +889,24 @@ func writeArticle(post Post) (error, Post) {
 			return err, post
 		}
 		newImgPrompt = imgBuiltPrompt.String()
+		post.ImageGenPrompt = newImgPrompt
 		util.Logger.Info().Msg("Img Prompt Out is: " + newImgPrompt)
-		imgBytes, err := generateImage(newImgPrompt)
+		images, err := generateImages(newImgPrompt, imageCandidateCount(), post)
 		if err != nil {
 			return err, post
 		}
-		post.Image = imgBytes
+		if len(images) > 0 {
+			post.Image = images[0].Data
+		}
+		post.ImageCandidates = nil
+		for _, image := range images {
+			post.ImageCandidates = append(post.ImageCandidates, image.Data)
+		}
+		post.GeneratedImages = images
+		imgSource = post.ImgMode
+		if imgSource == "" {
+			imgSource = Settings["IMG_MODE"]
+		}
 	} else if post.Error == "" && post.DownloadImg && post.ImgUrl != "" {
 		response, err := http.Get(post.ImgUrl)
 		if err != nil {
@@ -507,138 +923,1291 @@ func writeArticle(post Post) (error, Post) {
 			return err, post
 		}
 		post.Image = imgBytes
-	} else if post.Error == "" && post.UnsplashImg && post.UnsplashSearch != "" {
-		unsplashKey := Settings["UNSPLASH_ACCESS_KEY"]
-		imgBytes, err := unsplash.GetImageBySearch(unsplashKey, post.UnsplashSearch)
+		imgSource = "download"
+	} else if post.Error == "" && post.StockImg && post.StockSearch != "" {
+		photos, err := searchStockPhotos(post.StockSearch, imageCandidateCount())
 		if err != nil {
 			return err, post
 		}
-		post.Image = imgBytes
-	} else if post.Error == "" && post.UnsplashImg && post.UnsplashSearch == "" {
-		imgSearchResp, err := openai.GenerateImageSearch(aiApiKey, false, title, Templates["imgsearch-prompt"], Templates["system-prompt"])
+		post = setStockPhotos(post, photos)
+		imgSource = photos[0].Provider
+	} else if post.Error == "" && post.StockImg && post.StockSearch == "" {
+		imgSearchResp, err := openai.GenerateImageSearch(aiApiKey, false, title, Templates["imgsearch-prompt"], systemPrompt)
 		if err != nil {
 			return err, post
 		}
-		post.UnsplashSearch = imgSearchResp
-		unsplashKey := Settings["UNSPLASH_ACCESS_KEY"]
-		imgBytes, err := unsplash.GetImageBySearch(unsplashKey, imgSearchResp)
+		post.StockSearch = imgSearchResp
+		photos, err := searchStockPhotos(imgSearchResp, imageCandidateCount())
 		if err != nil {
 			return err, post
 		}
-		post.Image = imgBytes
+		post = setStockPhotos(post, photos)
+		imgSource = photos[0].Provider
+	}
+	//Keep every new image in the local library so it can be reused instead of regenerated
+	if post.LibraryMediaId == 0 {
+		libraryImages := post.ImageCandidates
+		if len(libraryImages) == 0 && len(post.Image) > 0 {
+			libraryImages = [][]byte{post.Image}
+		}
+		libraryMedia := models.Media{
+			Prompt: newImgPrompt,
+			Seed:   -1,
+			Engine: imgSource,
+		}
+		if len(post.StockPhotos) > 0 {
+			libraryMedia.Prompt = post.StockSearch
+		} else if imgSource == "download" {
+			libraryMedia.SourceUrl = post.ImgUrl
+		}
+		for i, libraryImage := range libraryImages {
+			if i < len(post.StockPhotos) {
+				libraryMedia.Engine = post.StockPhotos[i].Provider
+				libraryMedia.Credit = post.StockPhotos[i].Credit
+				libraryMedia.SourceUrl = post.StockPhotos[i].PageUrl
+				libraryMedia.License = post.StockPhotos[i].License
+				libraryMedia.LicenseUrl = post.StockPhotos[i].LicenseUrl
+			}
+			if i < len(post.GeneratedImages) {
+				libraryMedia.Seed = post.GeneratedImages[i].Seed
+				libraryMedia.GenInfo = post.GeneratedImages[i].Info
+			}
+			libraryId := saveToLibrary(libraryImage, libraryMedia)
+			if libraryId > 0 {
+				post.LibraryIds = append(post.LibraryIds, libraryId)
+			}
+		}
+	}
+	chosen := 0
+	if len(post.ImageCandidates) > 1 {
+		if post.AutoPost {
+			chosen = pickImage(post.ImageCandidates, Settings["IMG_AUTO_PICK"])
+			post.Image = post.ImageCandidates[chosen]
+		} else {
+			post.PickImage = true
+		}
+	}
+	if !post.PickImage && chosen < len(post.StockPhotos) {
+		post.ImageCredit = post.StockPhotos[chosen].Credit
+		post.ImageLicense = post.StockPhotos[chosen].License
+		trackStockDownload(post.StockPhotos[chosen])
 	}
 	post.ImageB64 = base64.StdEncoding.EncodeToString(post.Image)
-	postId, mediaId, err := postToWordpress(post)
-	if err != nil {
-		return err, post
+	postId, mediaId := 0, 0
+	articleStatus := "written"
+	if post.PickImage {
+		//Hold the article until one of the candidate images is picked as the featured image
+		articleStatus = "pending-image"
+	} else if Settings["REVIEW_ENABLE"] == "true" {
+		//Hold the article in the review queue, it is posted to WordPress once approved
+		articleStatus = "review"
+		post.InReview = true
 	} else {
-		models.SetIdeaWritten(post.IdeaId)
+		post.Content = addSeriesNav(addImageCredit(addSectionImages(post.Content, post.Title, post.AutoPost), post.ImageCredit), post.IdeaId, post.Language)
+		postId, mediaId, err = postToWordpress(post)
+		if err != nil {
+			return err, post
+		}
+		setIdeaPosted(post.IdeaId, post.PublishStatus, ideaActor(post), postId)
 	}
 	post.WordPressId = postId
 	//Write Post as Article to DB
 	articleDb := models.Article{
-		Title:          post.Title,
-		Content:        post.Content,
-		Description:    post.Description,
-		PrimaryKeyword: post.Keyword,
-		MediaId:        mediaId,
-		Prompt:         post.Prompt,
-		YtUrl:          post.YtUrl,
-		ImgPrompt:      newImgPrompt,
-		ImgSearch:      post.UnsplashSearch,
-		ImgSrcUrl:      post.ImgUrl,
-		Concept:        post.Concept,
-		IdeaId:         post.IdeaId,
-		Status:         "written",
-		Version:        1,
-		WordPressId:    postId,
+		Title:           post.Title,
+		Content:         post.Content,
+		Description:     post.Description,
+		PrimaryKeyword:  post.Keyword,
+		MediaId:         mediaId,
+		Prompt:          post.Prompt,
+		YtUrl:           post.YtUrl,
+		ImgPrompt:       newImgPrompt,
+		ImgSearch:       post.StockSearch,
+		ImgSrcUrl:       post.ImgUrl,
+		Concept:         post.Concept,
+		IdeaId:          post.IdeaId,
+		Status:          articleStatus,
+		Version:         1,
+		WordPressId:     postId,
+		VariantId:       post.VariantId,
+		PersonaId:       post.PersonaId,
+		PersonaFlags:    post.PersonaFlags,
+		Language:        post.Language,
+		FactCheckRisk:   post.FactCheckRisk,
+		FactCheckReport: post.FactCheckReport,
+		PublishStatus:   post.PublishStatus,
+		WpCategory:      post.WpCategory,
+		ImgCredit:       post.ImageCredit,
+		ImgLicense:      post.ImageLicense,
 	}
 	articleId, err := models.UpsertArticle(articleDb)
 	if err != nil {
 		util.Logger.Error().Err(err).Msg("Error writing article to DB")
 		return err, post
 	}
+	articleDb.Id = int(articleId)
+	webhooks.Fire(webhooks.ArticleGenerated, newArticleEvent(articleDb, ""))
+	if postId > 0 {
+		rememberWordPressModified(articleDb)
+		fireArticlePublished(articleDb)
+		relinkSeries(post.IdeaId)
+	}
+	if post.InReview && post.ImageB64 != "" {
+		_, err = models.SetArticleReviewImage(int(articleId), post.ImageB64)
+		if err != nil {
+			util.Logger.Error().Err(err).Msg("Error saving review image")
+		}
+	}
+	for _, libraryId := range post.LibraryIds {
+		models.SetMediaArticle(libraryId, int(articleId))
+	}
+	if postId > 0 && post.PublishStatus == "publish" {
+		if err := distributeArticle(articleDb, post.Image); err != nil {
+			util.Logger.Error().Err(err).Msg("Error sharing article")
+		}
+	}
+	if post.PickImage {
+		for i, candidate := range post.ImageCandidates {
+			imageCandidate := models.ImageCandidate{
+				ArticleId: int(articleId),
+				ImageData: base64.StdEncoding.EncodeToString(candidate),
+				Source:    imgSource,
+			}
+			if i < len(post.StockPhotos) {
+				imageCandidate.Source = post.StockPhotos[i].Provider
+				imageCandidate.Credit = post.StockPhotos[i].Credit
+				imageCandidate.DownloadUrl = post.StockPhotos[i].DownloadLocation
+				imageCandidate.License = post.StockPhotos[i].License
+				imageCandidate.LicenseUrl = post.StockPhotos[i].LicenseUrl
+			}
+			_, err = models.AddImageCandidate(imageCandidate)
+			if err != nil {
+				util.Logger.Error().Err(err).Msg("Error saving image candidate")
+			}
+		}
+	}
 	post.ArticleId = int(articleId)
 	return nil, post
 }
 
-func generateIdeas(ideaCount string, builtConcept string, useGpt4 bool, sid int, ideaConcept string) {
-	prompt := Prompt{
-		IdeaCount:   ideaCount,
-		IdeaConcept: builtConcept,
+// postOverride is what a concept or series override sets for the articles written from its ideas, empty fields set nothing
+type postOverride struct {
+	SystemPrompt  string
+	ArticlePrompt string
+	Length        int
+	ImgSource     string
+	ImgMode       string
+	PublishStatus string
+	WpCategory    int
+}
+
+// or fills the fields the override leaves empty from a less specific one
+func (o postOverride) or(base postOverride) postOverride {
+	if strings.TrimSpace(o.SystemPrompt) == "" {
+		o.SystemPrompt = base.SystemPrompt
 	}
-	aiApiKey := Settings["OPENAI_API_KEY"]
-	ideaTmpl := template.Must(template.New("idea-prompt").Parse(openai.IdeaTemplate))
-	ideaPrompt := new(bytes.Buffer)
-	err := ideaTmpl.Execute(ideaPrompt, prompt)
-	if err != nil {
-		util.Logger.Error().Err(err).Msg("Error executing idea template")
-	} else {
-		util.Logger.Info().Msg("Prompt is: " + ideaPrompt.String())
-		ideaResp, err := openai.GenerateIdeas(aiApiKey, useGpt4, ideaPrompt.String(), Templates["system-prompt"])
+	if strings.TrimSpace(o.ArticlePrompt) == "" {
+		o.ArticlePrompt = base.ArticlePrompt
+	}
+	if o.Length <= 0 {
+		o.Length = base.Length
+	}
+	if o.ImgSource == "" {
+		o.ImgSource = base.ImgSource
+	}
+	if o.ImgMode == "" {
+		o.ImgMode = base.ImgMode
+	}
+	if o.PublishStatus == "" {
+		o.PublishStatus = base.PublishStatus
+	}
+	if o.WpCategory <= 0 {
+		o.WpCategory = base.WpCategory
+	}
+	return o
+}
+
+// applyOverrides resolves the concept and then series overrides for a post, the series being the more specific wins
+func applyOverrides(post Post, systemPrompt string, articlePrompt string) (Post, string, string, error) {
+	override := postOverride{}
+	if post.Concept != "" {
+		concept, err := models.GetConceptOverride(post.Concept)
 		if err != nil {
-			util.Logger.Error().Err(err).Msg("Error generating ideas")
-		} else {
-			ideaResp = strings.ReplaceAll(ideaResp, "\n", "")
-			util.Logger.Info().Msg("Idea Brainstorm Results: " + ideaResp)
-			ideaList := strings.Split(ideaResp, "|")
-			for index, value := range ideaList {
-				util.Logger.Info().Msgf("Index: %d, Value: %s\n", index, value)
-				if strings.TrimSpace(value) != "" {
-					idea := models.Idea{
-						IdeaText:    strings.TrimSpace(value),
-						Status:      "NEW",
-						IdeaConcept: ideaConcept,
-						SeriesId:    sid,
-					}
-					_, err = models.AddIdea(idea)
-					if err != nil {
-						util.Logger.Error().Err(err).Msg("Error adding idea")
-					}
-				}
-			}
+			return post, systemPrompt, articlePrompt, err
 		}
+		override = postOverride{SystemPrompt: concept.SystemPrompt, ArticlePrompt: concept.ArticlePrompt, Length: concept.ArticleLength,
+			ImgSource: concept.ImgEngine, ImgMode: concept.ImgMode, PublishStatus: concept.PublishStatus, WpCategory: concept.WpCategory}
 	}
-
+	if post.SeriesId > 0 {
+		series, err := models.GetSeriesById(strconv.Itoa(post.SeriesId))
+		if err != nil {
+			return post, systemPrompt, articlePrompt, err
+		}
+		override = postOverride{SystemPrompt: series.SystemPrompt, ArticlePrompt: series.ArticlePrompt, Length: series.ArticleLength,
+			ImgSource: series.ImgEngine, ImgMode: series.ImgMode, PublishStatus: series.PublishStatus, WpCategory: series.WpCategory}.or(override)
+		if post.PersonaId == 0 {
+			post.PersonaId = series.PersonaId
+		}
+		post.ImgModel = series.SdModel
+		post.ImgStyle = series.SdStyle
+		post.ImgLora = series.SdLora
+		post.RefMediaId = series.RefMediaId
+	}
+	post, systemPrompt, articlePrompt = mergeOverride(post, systemPrompt, articlePrompt, override)
+	return post, systemPrompt, articlePrompt, nil
 }
 
-func fullBrainstorm(ideaCount string, useGpt4 bool) {
-	conceptList := ""
-	builtTopic := ""
-	aiApiKey := Settings["OPENAI_API_KEY"]
-	concepts, _ := models.GetIdeaConcepts()
-	series, _ := models.GetSeries()
-	for _, concept := range concepts {
-		conceptList = conceptList + ", " + concept
+// mergeOverride applies an override as defaults, only filling in what the post hasn't set itself
+func mergeOverride(post Post, systemPrompt string, articlePrompt string, override postOverride) (Post, string, string) {
+	if strings.TrimSpace(override.SystemPrompt) != "" {
+		systemPrompt = override.SystemPrompt
 	}
-	for _, s := range series {
-		conceptList = conceptList + ", " + s.SeriesPrompt
+	if strings.TrimSpace(override.ArticlePrompt) != "" {
+		articlePrompt = override.ArticlePrompt
 	}
-	builtTopic = builtTopic + " Previous topics used include: " + conceptList + "."
-	ideaTmpl := template.Must(template.New("topic-prompt").Parse(openai.TopicTemplate))
-	ideaPrompt := new(bytes.Buffer)
-	prompt := Prompt{
-		IdeaCount:   ideaCount,
-		IdeaConcept: builtTopic,
+	if override.Length > 0 && post.Length == 0 {
+		post.Length = override.Length
 	}
-	err := ideaTmpl.Execute(ideaPrompt, prompt)
+	if override.PublishStatus != "" && post.PublishStatus == "" {
+		post.PublishStatus = override.PublishStatus
+	}
+	if override.WpCategory > 0 && post.WpCategory == 0 {
+		post.WpCategory = override.WpCategory
+	}
+	if override.ImgMode != "" && post.ImgMode == "" {
+		post.ImgMode = override.ImgMode
+	}
+	if override.ImgSource != "" && !hasImageSource(post) {
+		post = setImageSource(post, override.ImgSource)
+	}
+	return post, systemPrompt, articlePrompt
+}
+
+// applyPostDefaults fills in what neither the post nor its overrides set, from the AUTO_POST_ settings for auto-posts
+func applyPostDefaults(post Post) Post {
+	if post.PublishStatus == "" {
+		post.PublishStatus = "draft"
+		if post.AutoPost && Settings["AUTO_POST_STATE"] != "" {
+			post.PublishStatus = Settings["AUTO_POST_STATE"]
+		}
+	}
+	if post.Length <= 0 {
+		post.Length = 750
+		if post.AutoPost {
+			if autoPostLen, err := strconv.Atoi(Settings["AUTO_POST_LEN"]); err == nil && autoPostLen > 0 {
+				post.Length = autoPostLen
+			}
+		}
+	}
+	if post.AutoPost && !hasImageSource(post) {
+		post = setImageSource(post, Settings["AUTO_POST_IMG_ENGINE"])
+	}
+	return post
+}
+
+// hasImageSource reports whether the post's image source has been picked, by hand or by an override
+func hasImageSource(post Post) bool {
+	return post.ImgSource != "" || post.GenerateImg || post.StockImg || post.DownloadImg || post.LibraryMediaId > 0
+}
+
+// setImageSource picks where the post's featured image comes from, none, generate or stock
+func setImageSource(post Post, source string) Post {
+	post.ImgSource = source
+	post.GenerateImg = source == "generate"
+	post.StockImg = source == "stock"
+	return post
+}
+
+// SeriesPartData is what the series-part-prompt, series-part-title and series-nav templates are rendered with.
+// Previous and Next are the ideas either side of the part, the Title and Link fields the nearest posted parts.
+type SeriesPartData struct {
+	Series        string
+	Part          int
+	Parts         int
+	Title         string
+	Previous      string
+	Next          string
+	PreviousTitle string
+	PreviousLink  string
+	NextTitle     string
+	NextLink      string
+}
+
+// The series navigation is kept between these markers so it can be replaced when later parts are posted
+const seriesNavStart = "<!-- series-nav -->"
+const seriesNavEnd = "<!-- /series-nav -->"
+
+// seriesPart finds an idea's place among the ordered parts of its series, index is -1 when it isn't in one
+func seriesPart(ideaId string) (models.Series, []models.Idea, int, error) {
+	if ideaId == "" {
+		return models.Series{}, nil, -1, nil
+	}
+	idea, err := models.GetIdeaById(ideaId)
+	if err != nil || idea.SeriesId == 0 {
+		return models.Series{}, nil, -1, err
+	}
+	series, err := models.GetSeriesById(strconv.Itoa(idea.SeriesId))
+	if err != nil || series.Id == 0 {
+		return models.Series{}, nil, -1, err
+	}
+	parts, err := models.GetSeriesIdeas(strconv.Itoa(series.Id))
+	if err != nil {
+		return models.Series{}, nil, -1, err
+	}
+	for i, part := range parts {
+		if part.Id == idea.Id {
+			return series, parts, i, nil
+		}
+	}
+	return series, parts, -1, nil
+}
+
+func seriesPartData(series models.Series, parts []models.Idea, index int) SeriesPartData {
+	data := SeriesPartData{Series: series.SeriesName, Part: index + 1, Parts: len(parts)}
+	if index > 0 {
+		data.Previous = parts[index-1].IdeaText
+	}
+	if index+1 < len(parts) {
+		data.Next = parts[index+1].IdeaText
+	}
+	return data
+}
+
+// renderSeriesTemplate renders one of the series templates, series-nav as html so the titles in its links are escaped
+func renderSeriesTemplate(name string, language string, data SeriesPartData) (string, error) {
+	text := localizedTemplate(name, language)
+	rendered := new(bytes.Buffer)
+	if name == "series-nav" {
+		tmpl, err := template.New(name).Parse(text)
+		if err != nil {
+			return "", err
+		}
+		err = tmpl.Execute(rendered, data)
+		return rendered.String(), err
+	}
+	tmpl, err := texttemplate.New(name).Parse(text)
+	if err != nil {
+		return "", err
+	}
+	err = tmpl.Execute(rendered, data)
+	return strings.TrimSpace(rendered.String()), err
+}
+
+// seriesNav renders the navigation for a part, linking the nearest parts before and after it that are on WordPress
+func seriesNav(series models.Series, parts []models.Idea, index int, language string) (string, error) {
+	data := seriesPartData(series, parts, index)
+	for i := index - 1; i >= 0 && data.PreviousLink == ""; i-- {
+		data.PreviousTitle, data.PreviousLink = postedPart(parts[i].Id)
+	}
+	for i := index + 1; i < len(parts) && data.NextLink == ""; i++ {
+		data.NextTitle, data.NextLink = postedPart(parts[i].Id)
+	}
+	return renderSeriesTemplate("series-nav", language, data)
+}
+
+// postedPart returns the title and link of the article posted for a series idea, empty when it hasn't been posted
+func postedPart(ideaId int) (string, string) {
+	articleId, err := models.GetPublishedIdeaArticleId(ideaId)
+	if err != nil || articleId == 0 {
+		return "", ""
+	}
+	article, err := models.GetArticleById(articleId)
+	if err != nil {
+		return "", ""
+	}
+	link, err := getWordPressPostLink(article.WordPressId)
+	if err != nil {
+		util.Logger.Error().Err(err).Msg("Error getting series part link")
+		return "", ""
+	}
+	return article.Title, link
+}
+
+// withSeriesNav replaces the series navigation in content, adding it to the end when there is none yet.
+// An empty nav removes it.
+func withSeriesNav(content string, nav string) string {
+	block := ""
+	if nav != "" {
+		block = seriesNavStart + nav + seriesNavEnd
+	}
+	if before, rest, found := strings.Cut(content, seriesNavStart); found {
+		if _, after, found := strings.Cut(rest, seriesNavEnd); found {
+			if block == "" {
+				before = strings.TrimSuffix(before, "\n")
+			}
+			return before + block + after
+		}
+	}
+	if block == "" {
+		return content
+	}
+	return content + "\n" + block
+}
+
+// addSeriesNav adds the navigation to an article about to be posted when its idea is part of a series with NavLinks on
+func addSeriesNav(content string, ideaId string, language string) string {
+	series, parts, index, err := seriesPart(ideaId)
+	if err != nil {
+		util.Logger.Error().Err(err).Msg("Error getting series part")
+	}
+	if index < 0 || !series.NavLinks {
+		return content
+	}
+	nav, err := seriesNav(series, parts, index, language)
+	if err != nil {
+		util.Logger.Error().Err(err).Msg("Error rendering series-nav template")
+		return content
+	}
+	return withSeriesNav(content, nav)
+}
+
+// relinkSeries updates the navigation of the nearest posted parts either side of a newly posted part, on WordPress
+// and in the articles, so they link to it
+func relinkSeries(ideaId string) {
+	series, parts, index, err := seriesPart(ideaId)
+	if err != nil {
+		util.Logger.Error().Err(err).Msg("Error getting series part")
+	}
+	if index < 0 || !series.NavLinks {
+		return
+	}
+	for _, step := range []int{-1, 1} {
+		for i := index + step; i >= 0 && i < len(parts); i += step {
+			articleId, err := models.GetPublishedIdeaArticleId(parts[i].Id)
+			if err != nil || articleId == 0 {
+				continue
+			}
+			if err := relinkPart(articleId, series, parts, i); err != nil {
+				util.Logger.Error().Err(err).Msg("Error updating series navigation")
+				webhooks.Fire(webhooks.JobFailed, JobEvent{Job: "series-nav", Error: err.Error(), ArticleId: articleId})
+			}
+			break
+		}
+	}
+}
+
+// relinkPart rewrites the navigation of one posted part. The post's content is read back from WordPress so
+// edits made there are kept.
+func relinkPart(articleId int, series models.Series, parts []models.Idea, index int) error {
+	article, err := models.GetArticleById(articleId)
+	if err != nil {
+		return err
+	}
+	nav, err := seriesNav(series, parts, index, article.Language)
+	if err != nil {
+		return err
+	}
+	var wpPost struct {
+		Content struct {
+			Raw string `json:"raw"`
+		} `json:"content"`
+		ModifiedGmt string `json:"modified_gmt"`
+	}
+	err = getWordPressJSON("/wp-json/wp/v2/posts/"+strconv.Itoa(article.WordPressId)+"?context=edit", &wpPost)
+	if err != nil {
+		return err
+	}
+	//Catch an edit before the new links overwrite the modified time it shows up in
+	if article.WpModified != "" && wpPost.ModifiedGmt != article.WpModified {
+		models.SetArticleWpEdited(article.Id)
+	}
+	content := withSeriesNav(wpPost.Content.Raw, nav)
+	if content != wpPost.Content.Raw {
+		_, err = doWordpressPost("/wp-json/wp/v2/posts/"+strconv.Itoa(article.WordPressId), map[string]interface{}{"content": content})
+		if err != nil {
+			return err
+		}
+		rememberWordPressModified(article)
+	}
+	article.Content = withSeriesNav(article.Content, nav)
+	_, err = models.UpsertArticle(article)
+	return err
+}
+
+// nextSeriesParts keeps only the next part of each series, the first that isn't written yet, so auto-post writes
+// a series in order and waits while that part is held for an image or review or not due yet
+func nextSeriesParts(ideas []models.Idea) ([]models.Idea, error) {
+	next := map[int]int{}
+	kept := make([]models.Idea, 0, len(ideas))
+	for _, idea := range ideas {
+		if idea.SeriesId == 0 {
+			kept = append(kept, idea)
+			continue
+		}
+		nextId, ok := next[idea.SeriesId]
+		if !ok {
+			parts, err := models.GetSeriesIdeas(strconv.Itoa(idea.SeriesId))
+			if err != nil {
+				return nil, err
+			}
+			for _, part := range parts {
+				if !models.IdeaDone(part.Status) {
+					nextId = part.Id
+					break
+				}
+			}
+			next[idea.SeriesId] = nextId
+		}
+		if idea.Id == nextId {
+			kept = append(kept, idea)
+		}
+	}
+	return kept, nil
+}
+
+// resolveLanguage picks the post's language, then its series' language, then DEFAULT_LANGUAGE
+func resolveLanguage(post Post) string {
+	if post.Language != "" {
+		return post.Language
+	}
+	if post.SeriesId > 0 {
+		series, err := models.GetSeriesById(strconv.Itoa(post.SeriesId))
+		if err != nil {
+			util.Logger.Error().Err(err).Msg("Error getting series language")
+		} else if series.Language != "" {
+			return series.Language
+		}
+	}
+	if Settings["DEFAULT_LANGUAGE"] != "" {
+		return Settings["DEFAULT_LANGUAGE"]
+	}
+	return "en"
+}
+
+// localizedTemplate returns the template named "<name>.<language>" when one exists, otherwise the default template
+func localizedTemplate(name string, language string) string {
+	if language != "" && language != "en" {
+		if localized := Templates[name+"."+language]; strings.TrimSpace(localized) != "" {
+			return localized
+		}
+	}
+	return Templates[name]
+}
+
+// addLanguageInstruction makes sure overrides and personas written in English still produce the requested language
+func addLanguageInstruction(systemPrompt string, language string) string {
+	languageName, ok := LanguageNames[language]
+	if !ok || language == "en" {
+		return systemPrompt
+	}
+	return systemPrompt + " Always write in " + languageName + "."
+}
+
+// buildPersonaPrompt appends a persona's voice, rules, banned phrases and examples to the system prompt
+func buildPersonaPrompt(systemPrompt string, persona models.Persona) string {
+	personaPrompt := systemPrompt + " Write as " + persona.PersonaName + "."
+	if strings.TrimSpace(persona.Voice) != "" {
+		personaPrompt = personaPrompt + " Your voice: " + strings.TrimSpace(persona.Voice)
+	}
+	if strings.TrimSpace(persona.StyleRules) != "" {
+		personaPrompt = personaPrompt + " Follow these style rules: " + strings.TrimSpace(persona.StyleRules)
+	}
+	bannedPhrases := splitBannedPhrases(persona.BannedPhrases)
+	if len(bannedPhrases) > 0 {
+		personaPrompt = personaPrompt + " Never use any of these phrases: \"" + strings.Join(bannedPhrases, "\", \"") + "\"."
+	}
+	if strings.TrimSpace(persona.Examples) != "" {
+		personaPrompt = personaPrompt + " Here are example paragraphs written in this voice: " + strings.TrimSpace(persona.Examples)
+	}
+	return personaPrompt
+}
+
+func splitBannedPhrases(bannedPhrases string) []string {
+	var phrases []string
+	for _, phrase := range strings.Split(bannedPhrases, "\n") {
+		phrase = strings.TrimSpace(phrase)
+		if phrase != "" {
+			phrases = append(phrases, phrase)
+		}
+	}
+	return phrases
+}
+
+func findBannedPhrases(text string, bannedPhrases string) []string {
+	var found []string
+	lowerText := strings.ToLower(text)
+	for _, phrase := range splitBannedPhrases(bannedPhrases) {
+		if strings.Contains(lowerText, strings.ToLower(phrase)) {
+			found = append(found, phrase)
+		}
+	}
+	return found
+}
+
+// checkPersona flags banned phrases in the article, asking for a revision first when PERSONA_REVISE_ENABLE is on.
+// The returned flags list whatever banned phrases remain.
+func checkPersona(aiApiKey string, useGpt4 bool, article string, persona models.Persona, systemPrompt string) (string, string) {
+	found := findBannedPhrases(article, persona.BannedPhrases)
+	if len(found) == 0 {
+		return article, ""
+	}
+	util.Logger.Info().Msg("Article contains banned phrases: " + strings.Join(found, ", "))
+	if Settings["PERSONA_REVISE_ENABLE"] == "true" {
+		revisePrompt := "Revise the article so it no longer uses any of these phrases: \"" + strings.Join(found, "\", \"") + "\". Keep everything else as close to the original as possible."
+		revision, err := openai.GenerateRevision(aiApiKey, useGpt4, article, revisePrompt, systemPrompt)
+		if err != nil {
+			util.Logger.Error().Err(err).Msg("Error revising article, keeping original")
+		} else if strings.TrimSpace(revision) != "" {
+			article = revision
+			found = findBannedPhrases(article, persona.BannedPhrases)
+		}
+	}
+	return article, strings.Join(found, ", ")
+}
+
+// riskLevels orders the fact-check risk ratings so they can be compared against FACT_CHECK_DRAFT_RISK
+var riskLevels = map[string]int{
+	"low":    1,
+	"medium": 2,
+	"high":   3,
+}
+
+// factCheckArticle asks the model to list the article's factual claims and flag the unverifiable ones.
+// The report is stored with the post and a publish is forced to draft once the risk reaches FACT_CHECK_DRAFT_RISK.
+func factCheckArticle(aiApiKey string, post Post, article string, systemPrompt string) Post {
+	var report FactCheckReport
+	reportResp, err := openai.GenerateFactCheck(aiApiKey, post.UseGpt4, article, Templates["factcheck-prompt"], systemPrompt)
+	if err != nil {
+		util.Logger.Error().Err(err).Msg("Error fact checking article")
+		//A check that didn't run can't vouch for the article either
+		report = FactCheckReport{Risk: "high", Error: "Fact check failed: " + err.Error()}
+	} else {
+		report, err = parseFactCheckReport(reportResp)
+		if err != nil {
+			util.Logger.Error().Err(err).Msg("Error parsing fact check report")
+			//An unreadable report can't vouch for the article so treat it as high risk
+			report = FactCheckReport{Risk: "high", Error: "Fact check report unreadable: " + err.Error()}
+		}
+	}
+	reportJson, err := json.Marshal(report)
+	if err != nil {
+		util.Logger.Error().Err(err).Msg("Error marshalling fact check report")
+	}
+	post.FactCheckRisk = report.Risk
+	post.FactCheckReport = string(reportJson)
+	draftLevel, ok := riskLevels[Settings["FACT_CHECK_DRAFT_RISK"]]
+	if ((ok && riskLevels[report.Risk] >= draftLevel) || report.Error != "") && post.PublishStatus == "publish" {
+		util.Logger.Info().Msg("Fact check risk is " + report.Risk + ", saving as draft instead of publishing")
+		post.PublishStatus = "draft"
+	}
+	return post
+}
+
+func parseFactCheckReport(reportResp string) (FactCheckReport, error) {
+	var report FactCheckReport
+	//Strip anything the model wrapped around the JSON object
+	start := strings.Index(reportResp, "{")
+	end := strings.LastIndex(reportResp, "}")
+	if start < 0 || end < start {
+		return report, errors.New("No JSON object in fact check response")
+	}
+	err := json.Unmarshal([]byte(reportResp[start:end+1]), &report)
+	if err != nil {
+		return report, err
+	}
+	report.Risk = strings.ToLower(strings.TrimSpace(report.Risk))
+	if _, ok := riskLevels[report.Risk]; !ok {
+		report.Risk = "high"
+	}
+	return report, nil
+}
+
+var sectionHeadingPattern = regexp.MustCompile(`(?is)<h2[^>]*>(.*?)</h2>`)
+var htmlTagPattern = regexp.MustCompile(`<[^>]+>`)
+
+// addSectionImages uploads an image for each of the first INLINE_IMG_MAX H2 sections of the content
+// and places it as a figure under the section heading, using the heading as alt text
+func addSectionImages(content string, title string, autoPost bool) string {
+	engine := Settings["INLINE_IMG_ENGINE"]
+	if engine != "generate" && engine != "stock" {
+		return content
+	}
+	maxImages, err := strconv.Atoi(Settings["INLINE_IMG_MAX"])
+	if err != nil || maxImages < 1 {
+		maxImages = 3
+	}
+	headings := sectionHeadingPattern.FindAllStringSubmatchIndex(content, maxImages)
+	//Work backwards so inserting a figure doesn't shift the positions of the headings before it
+	for i := len(headings) - 1; i >= 0; i-- {
+		heading := headings[i]
+		headingText := strings.TrimSpace(html.UnescapeString(htmlTagPattern.ReplaceAllString(content[heading[2]:heading[3]], "")))
+		if headingText == "" {
+			continue
+		}
+		image, credit, err := sectionImage(engine, headingText, title, autoPost)
+		if err != nil {
+			util.Logger.Error().Err(err).Msg("Error getting image for section: " + headingText)
+			continue
+		}
+		imgBytes := image.Data
+		saveToLibrary(imgBytes, models.Media{Prompt: headingText, Seed: image.Seed, GenInfo: image.Info, Engine: engine, Credit: credit})
+		media := uploadImageToWordpress(processImage(imgBytes, false, ""), headingText, credit)
+		if media.ID <= 0 || media.SourceUrl == "" {
+			continue
+		}
+		caption := ""
+		if credit != "" {
+			caption = "<figcaption class=\"wp-element-caption\">" + credit + "</figcaption>"
+		}
+		figure := "\n<figure class=\"wp-block-image size-large\"><img src=\"" + html.EscapeString(media.SourceUrl) + "\" alt=\"" +
+			html.EscapeString(headingText) + "\" class=\"wp-image-" + strconv.Itoa(media.ID) + "\"/>" + caption + "</figure>\n"
+		content = content[:heading[1]] + figure + content[heading[1]:]
+	}
+	return content
+}
+
+// sectionImage gets the image for one section along with its credit, which is only set for stock photos
+func sectionImage(engine string, heading string, title string, autoPost bool) (imagegen.Image, string, error) {
+	if engine == "stock" {
+		photos, err := searchStockPhotos(heading, 1)
+		if err != nil {
+			return imagegen.Image{}, "", err
+		}
+		trackStockDownload(photos[0])
+		return imagegen.Image{Data: photos[0].Image, Seed: -1}, photos[0].Credit, nil
+	}
+	imgTmpl := template.Must(template.New("img-prompt").Parse(Templates["img-prompt"]))
+	imgBuiltPrompt := new(bytes.Buffer)
+	err := imgTmpl.Execute(imgBuiltPrompt, Post{ImagePrompt: heading + ", for an article titled " + title})
+	if err != nil {
+		return imagegen.Image{}, "", err
+	}
+	images, err := generateImages(imgBuiltPrompt.String(), 1, Post{AutoPost: autoPost})
+	if err != nil {
+		return imagegen.Image{}, "", err
+	}
+	if len(images) == 0 {
+		return imagegen.Image{}, "", errors.New("No image engine selected in IMG_MODE")
+	}
+	return images[0], "", nil
+}
+
+func generateIdeas(ideaCount string, builtConcept string, useGpt4 bool, sid int, ideaConcept string) {
+	prompt := Prompt{
+		IdeaCount:   ideaCount,
+		IdeaConcept: builtConcept,
+	}
+	aiApiKey := Settings["OPENAI_API_KEY"]
+	ideaTmpl := template.Must(template.New("idea-prompt").Parse(openai.IdeaTemplate))
+	ideaPrompt := new(bytes.Buffer)
+	err := ideaTmpl.Execute(ideaPrompt, prompt)
+	if err != nil {
+		util.Logger.Error().Err(err).Msg("Error executing idea template")
+	} else {
+		util.Logger.Info().Msg("Prompt is: " + ideaPrompt.String())
+		ideaResp, err := openai.GenerateIdeas(aiApiKey, useGpt4, ideaPrompt.String(), Templates["system-prompt"])
+		if err != nil {
+			util.Logger.Error().Err(err).Msg("Error generating ideas")
+			webhooks.Fire(webhooks.JobFailed, JobEvent{Job: "idea-generation", Error: err.Error()})
+		} else {
+			ideaResp = strings.ReplaceAll(ideaResp, "\n", "")
+			util.Logger.Info().Msg("Idea Brainstorm Results: " + ideaResp)
+			ideaList := strings.Split(ideaResp, "|")
+			for index, value := range ideaList {
+				util.Logger.Info().Msgf("Index: %d, Value: %s\n", index, value)
+				if strings.TrimSpace(value) != "" {
+					idea := models.Idea{
+						IdeaText:    strings.TrimSpace(value),
+						Status:      "NEW",
+						IdeaConcept: ideaConcept,
+						SeriesId:    sid,
+					}
+					ideaId, err := models.AddIdea(idea, "ai")
+					if err != nil {
+						util.Logger.Error().Err(err).Msg("Error adding idea")
+					} else {
+						idea.Id = int(ideaId)
+						webhooks.Fire(webhooks.IdeaCreated, idea)
+					}
+				}
+			}
+		}
+	}
+
+}
+
+func fullBrainstorm(ideaCount string, useGpt4 bool) {
+	conceptList := ""
+	builtTopic := ""
+	aiApiKey := Settings["OPENAI_API_KEY"]
+	concepts, _ := models.GetIdeaConcepts()
+	series, _ := models.GetSeries()
+	for _, concept := range concepts {
+		conceptList = conceptList + ", " + concept
+	}
+	for _, s := range series {
+		conceptList = conceptList + ", " + s.SeriesPrompt
+	}
+	builtTopic = builtTopic + " Previous topics used include: " + conceptList + "."
+	ideaTmpl := template.Must(template.New("topic-prompt").Parse(openai.TopicTemplate))
+	ideaPrompt := new(bytes.Buffer)
+	prompt := Prompt{
+		IdeaCount:   ideaCount,
+		IdeaConcept: builtTopic,
+	}
+	err := ideaTmpl.Execute(ideaPrompt, prompt)
 	if err != nil {
 		util.Logger.Error().Err(err).Msg("Error executing idea template")
 	} else {
 		util.Logger.Info().Msg("Topic Prompt is: " + ideaPrompt.String())
 		ideaResp, err := openai.GenerateTopics(aiApiKey, useGpt4, ideaPrompt.String(), Templates["system-prompt"])
 		if err != nil {
-			util.Logger.Error().Err(err).Msg("Error generating ideas")
-		} else {
-			ideaResp = strings.ReplaceAll(ideaResp, "\n", "")
-			util.Logger.Info().Msg("Idea Brainstorm Results: " + ideaResp)
-			ideaList := strings.Split(ideaResp, "|")
-			for _, value := range ideaList {
-				builtConcept := "The topic for the ideas is: \"" + value + "\"."
-				generateIdeas(ideaCount, builtConcept, useGpt4, 0, value)
+			util.Logger.Error().Err(err).Msg("Error generating ideas")
+			webhooks.Fire(webhooks.JobFailed, JobEvent{Job: "idea-generation", Error: err.Error()})
+		} else {
+			ideaResp = strings.ReplaceAll(ideaResp, "\n", "")
+			util.Logger.Info().Msg("Idea Brainstorm Results: " + ideaResp)
+			ideaList := strings.Split(ideaResp, "|")
+			for _, value := range ideaList {
+				builtConcept := "The topic for the ideas is: \"" + value + "\"."
+				generateIdeas(ideaCount, builtConcept, useGpt4, 0, value)
+			}
+		}
+	}
+}
+
+// pickIdea chooses the next idea to auto-post from the open ideas whose not before date has passed, or only the
+// APPROVED and SCHEDULED ones when AUTO_POST_REQUIRE_APPROVAL is on, taking only the next part of each series:
+//   - random picks any of them
+//   - priority takes the highest priority, the oldest of those first
+//   - oldest takes the oldest
+//   - round-robin takes the concept written about longest ago, then the priority pick within it
+//   - weighted picks at random with each idea priority+1 times as likely as one of priority 0
+//   - series takes the series written from longest ago and its next idea in order, or the priority pick without series ideas
+func pickIdea(strategy string) (models.Idea, error) {
+	statuses := []string{models.IdeaNew, models.IdeaApproved, models.IdeaScheduled}
+	if Settings["AUTO_POST_REQUIRE_APPROVAL"] == "true" {
+		statuses = []string{models.IdeaApproved, models.IdeaScheduled}
+	}
+	ideas, err := models.GetAutoPostIdeas(statuses)
+	if err != nil {
+		return models.Idea{}, err
+	}
+	ideas, err = nextSeriesParts(ideas)
+	if err != nil || len(ideas) == 0 {
+		return models.Idea{}, err
+	}
+	switch strategy {
+	case "priority":
+		return byPriority(ideas)[0], nil
+	case "oldest":
+		return ideas[0], nil
+	case "round-robin":
+		lastPosted, err := models.GetConceptsLastPosted()
+		if err != nil {
+			return models.Idea{}, err
+		}
+		return leastRecent(byPriority(ideas), lastPosted, func(idea models.Idea) string {
+			return idea.IdeaConcept
+		}), nil
+	case "weighted":
+		total := 0
+		for _, idea := range ideas {
+			total += ideaWeight(idea)
+		}
+		pick := rand.Intn(total)
+		for _, idea := range ideas {
+			pick -= ideaWeight(idea)
+			if pick < 0 {
+				return idea, nil
+			}
+		}
+	case "series":
+		seriesIdeas := make([]models.Idea, 0)
+		for _, idea := range ideas {
+			if idea.SeriesId > 0 {
+				seriesIdeas = append(seriesIdeas, idea)
+			}
+		}
+		if len(seriesIdeas) == 0 {
+			return byPriority(ideas)[0], nil
+		}
+		lastPosted, err := models.GetSeriesLastPosted()
+		if err != nil {
+			return models.Idea{}, err
+		}
+		return leastRecent(seriesIdeas, lastPosted, func(idea models.Idea) string {
+			return strconv.Itoa(idea.SeriesId)
+		}), nil
+	}
+	return ideas[rand.Intn(len(ideas))], nil
+}
+
+// byPriority orders ideas highest priority first, keeping the oldest first among equals
+func byPriority(ideas []models.Idea) []models.Idea {
+	sorted := append([]models.Idea{}, ideas...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Priority > sorted[j].Priority
+	})
+	return sorted
+}
+
+func ideaWeight(idea models.Idea) int {
+	if idea.Priority < 0 {
+		return 1
+	}
+	return idea.Priority + 1
+}
+
+// leastRecent returns the first idea of the group whose last article is the oldest. Groups never
+// written about come first, and ties go to the group whose first idea comes first.
+func leastRecent(ideas []models.Idea, lastPosted map[string]string, group func(models.Idea) string) models.Idea {
+	best := ideas[0]
+	for _, idea := range ideas[1:] {
+		if lastPosted[group(idea)] < lastPosted[group(best)] {
+			best = idea
+		}
+	}
+	return best
+}
+
+// clampPriority keeps a priority within 0 to 10
+func clampPriority(priority int) int {
+	if priority < 0 {
+		return 0
+	}
+	if priority > 10 {
+		return 10
+	}
+	return priority
+}
+
+// normalizeTags trims the comma separated tags and drops empty and repeated ones
+func normalizeTags(tags string) string {
+	seen := map[string]bool{}
+	kept := make([]string, 0)
+	for _, tag := range (models.Idea{Tags: tags}).TagList() {
+		if !seen[strings.ToLower(tag)] {
+			seen[strings.ToLower(tag)] = true
+			kept = append(kept, tag)
+		}
+	}
+	return strings.Join(kept, ",")
+}
+
+// ImportRow is a row of an idea import with the series it maps to and whether it will be added.
+// Status is new, duplicate, error or, once added, imported.
+type ImportRow struct {
+	bulk.Row
+	SeriesId   int
+	SeriesName string
+	Status     string
+	Note       string
+}
+
+// ImportDefaults fill in the concept, series and language of rows that don't give their own
+type ImportDefaults struct {
+	Concept  string
+	SeriesId int
+	Language string
+}
+
+// planImport maps rows to series by name or id and to languages by code or name, and flags rows that
+// duplicate an existing idea or an earlier row. Nothing is saved, so it is also the dry run.
+func planImport(rows []bulk.Row, defaults ImportDefaults) ([]ImportRow, error) {
+	ideas, err := models.GetIdeas()
+	if err != nil {
+		return nil, err
+	}
+	seriesList, err := models.GetSeries()
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]string{}
+	for _, idea := range ideas {
+		seen[bulk.Normalize(idea.IdeaText)] = "idea #" + strconv.Itoa(idea.Id)
+	}
+	seriesNames := map[int]string{}
+	for _, series := range seriesList {
+		seriesNames[series.Id] = series.SeriesName
+	}
+	plan := make([]ImportRow, 0, len(rows))
+	for _, row := range rows {
+		planned := ImportRow{Row: row, SeriesId: defaults.SeriesId, Status: "new"}
+		if planned.Concept == "" {
+			planned.Concept = defaults.Concept
+		}
+		if planned.Language == "" {
+			planned.Language = defaults.Language
+		} else if code, ok := languageCode(planned.Language); ok {
+			planned.Language = code
+		} else {
+			planned.Status = "error"
+			planned.Note = "Unknown language " + planned.Language
+		}
+		if planned.Series != "" {
+			planned.SeriesId = 0
+			for _, series := range seriesList {
+				if strings.EqualFold(series.SeriesName, planned.Series) || strconv.Itoa(series.Id) == planned.Series {
+					planned.SeriesId = series.Id
+					break
+				}
+			}
+			if planned.SeriesId == 0 {
+				planned.Status = "error"
+				planned.Note = "No series named " + planned.Series
+			}
+		}
+		planned.SeriesName = seriesNames[planned.SeriesId]
+		if planned.Priority != "" {
+			if priority, err := strconv.Atoi(planned.Priority); err != nil || clampPriority(priority) != priority {
+				planned.Status = "error"
+				planned.Note = "Priority " + planned.Priority + " is not a number from 0 to 10"
+			}
+		}
+		if planned.NotBefore != "" {
+			if _, err := time.Parse("2006-01-02", planned.NotBefore); err != nil {
+				planned.Status = "error"
+				planned.Note = "Not before " + planned.NotBefore + " is not a YYYY-MM-DD date"
+			}
+		}
+		if planned.Status == "new" {
+			key := bulk.Normalize(planned.Idea)
+			if match, ok := seen[key]; ok {
+				planned.Status = "duplicate"
+				planned.Note = "Same as " + match
+			} else {
+				seen[key] = "line " + strconv.Itoa(planned.Line)
+			}
+		}
+		plan = append(plan, planned)
+	}
+	return plan, nil
+}
+
+// languageCode accepts a language code or its name, such as es or Spanish
+func languageCode(language string) (string, bool) {
+	for code, name := range LanguageNames {
+		if strings.EqualFold(code, language) || strings.EqualFold(name, language) {
+			return code, true
+		}
+	}
+	return "", false
+}
+
+// importIdeas adds the new rows of a plan as ideas and marks them imported, returning how many were added
+func importIdeas(plan []ImportRow) int {
+	imported := 0
+	for i, row := range plan {
+		if row.Status != "new" {
+			continue
+		}
+		priority, _ := strconv.Atoi(row.Priority)
+		idea := models.Idea{
+			IdeaText:    row.Idea,
+			Status:      "NEW",
+			IdeaConcept: row.Concept,
+			SeriesId:    row.SeriesId,
+			Language:    row.Language,
+			Priority:    priority,
+			Tags:        normalizeTags(row.Tags),
+			Keyword:     row.Keyword,
+			NotBefore:   row.NotBefore,
+		}
+		ideaId, err := models.AddIdea(idea, "import")
+		if err != nil {
+			util.Logger.Error().Err(err).Msg("Error adding idea")
+			plan[i].Status = "error"
+			plan[i].Note = err.Error()
+			continue
+		}
+		idea.Id = int(ideaId)
+		webhooks.Fire(webhooks.IdeaCreated, idea)
+		plan[i].Status = "imported"
+		plan[i].Note = "Idea #" + strconv.Itoa(idea.Id)
+		imported++
+	}
+	return imported
+}
+
+// ExportData lists what can be exported
+var ExportData = []string{"ideas", "series", "articles"}
+
+// exportTable builds the export of ideas, series or articles. Ideas name their series so the file imports
+// back in, and articles leave out their content, which lives in WordPress.
+func exportTable(data string) (bulk.Table, error) {
+	switch data {
+	case "ideas":
+		ideas, err := models.GetIdeas()
+		if err != nil {
+			return bulk.Table{}, err
+		}
+		seriesList, err := models.GetSeries()
+		if err != nil {
+			return bulk.Table{}, err
+		}
+		seriesNames := map[int]string{}
+		for _, series := range seriesList {
+			seriesNames[series.Id] = series.SeriesName
+		}
+		table := bulk.Table{Columns: []string{"id", "idea", "concept", "series", "language", "priority", "tags", "keyword", "not_before", "status", "create_dt"}}
+		for _, idea := range ideas {
+			table.Rows = append(table.Rows, []string{strconv.Itoa(idea.Id), idea.IdeaText, idea.IdeaConcept, seriesNames[idea.SeriesId],
+				idea.Language, strconv.Itoa(idea.Priority), idea.Tags, idea.Keyword, idea.NotBefore, idea.Status, idea.CreateDate})
+		}
+		return table, nil
+	case "series":
+		seriesList, err := models.GetSeries()
+		if err != nil {
+			return bulk.Table{}, err
+		}
+		table := bulk.Table{Columns: []string{"id", "series_name", "series_prompt", "language", "publish_status", "article_length", "persona_id", "create_dt"}}
+		for _, series := range seriesList {
+			table.Rows = append(table.Rows, []string{strconv.Itoa(series.Id), series.SeriesName, series.SeriesPrompt, series.Language,
+				series.PublishStatus, strconv.Itoa(series.ArticleLength), strconv.Itoa(series.PersonaId), series.CreateDate})
+		}
+		return table, nil
+	case "articles":
+		articles, err := models.GetArticles()
+		if err != nil {
+			return bulk.Table{}, err
+		}
+		table := bulk.Table{Columns: []string{"id", "title", "description", "primary_keyword", "concept", "idea_id", "status", "publish_status",
+			"language", "wordpress_id", "wp_views", "create_dt", "update_dt"}}
+		for _, article := range articles {
+			table.Rows = append(table.Rows, []string{strconv.Itoa(article.Id), article.Title, article.Description, article.PrimaryKeyword,
+				article.Concept, article.IdeaId, article.Status, article.PublishStatus, article.Language, strconv.Itoa(article.WordPressId),
+				strconv.Itoa(article.WpViews), article.CreateDate, article.UpdateDate})
+		}
+		return table, nil
+	}
+	return bulk.Table{}, errors.New("Unknown export " + data)
+}
+
+// FeedIdeaData is what the feed-idea-prompt template is rendered with
+type FeedIdeaData struct {
+	Title   string
+	Summary string
+	Link    string
+	Feed    string
+}
+
+// feedCheckTimeout bounds downloading a feed
+const feedCheckTimeout = 30 * time.Second
+
+// checkFeeds turns the new entries of every enabled feed into ideas
+func checkFeeds() {
+	feedList, err := models.GetFeeds()
+	if err != nil {
+		util.Logger.Error().Err(err).Msg("Error getting feeds")
+		return
+	}
+	for _, feed := range feedList {
+		if !feed.Enabled {
+			continue
+		}
+		added, err := checkFeed(feed)
+		if err != nil {
+			util.Logger.Error().Err(err).Str("feed", feed.Name).Msg("Error checking feed")
+			webhooks.Fire(webhooks.JobFailed, JobEvent{Job: "feed-check", Error: err.Error(), Detail: feed.Name})
+			continue
+		}
+		util.Logger.Info().Msg("Feed " + feed.Name + " added " + strconv.Itoa(added) + " ideas")
+	}
+}
+
+// checkFeed reads a feed and records when it was checked and how that went, returning how many ideas it added
+func checkFeed(feed models.Feed) (int, error) {
+	added, err := readFeed(feed)
+	lastError := ""
+	if err != nil {
+		lastError = err.Error()
+	}
+	if updateErr := models.UpdateFeedChecked(feed.Id, lastError); updateErr != nil {
+		util.Logger.Error().Err(updateErr).Msg("Error updating feed")
+	}
+	return added, err
+}
+
+// readFeed makes ideas of up to FEED_MAX_ITEMS entries not seen before, newest first, with the feed's name as their
+// concept. Other new entries are only marked seen so a new feed doesn't flood the ideas with its back catalogue.
+// An entry whose rewrite fails stays unseen and is tried again on the next check.
+func readFeed(feed models.Feed) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), feedCheckTimeout)
+	defer cancel()
+	parsed, err := feeds.Fetch(ctx, feed.Url)
+	if err != nil {
+		return 0, err
+	}
+	maxItems, convErr := strconv.Atoi(Settings["FEED_MAX_ITEMS"])
+	if convErr != nil || maxItems < 0 {
+		maxItems = 5
+	}
+	items := parsed.Items
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Published.After(items[j].Published)
+	})
+	added := 0
+	for _, item := range items {
+		seen, err := models.HasFeedItem(feed.Id, item.Id)
+		if err != nil {
+			return added, err
+		}
+		if seen {
+			continue
+		}
+		feedItem := models.FeedItem{
+			FeedId: feed.Id,
+			Guid:   item.Id,
+			Title:  item.Title,
+			Link:   item.Link,
+		}
+		if added < maxItems && item.Title != "" {
+			ideaText, err := feedIdeaText(feed, item)
+			if err != nil {
+				return added, errors.New("Error rewriting \"" + item.Title + "\": " + err.Error())
+			}
+			idea := models.Idea{
+				IdeaText:    ideaText,
+				Status:      "NEW",
+				IdeaConcept: feed.Name,
+				Language:    feed.Language,
+			}
+			ideaId, err := models.AddIdea(idea, "feed: "+feed.Name)
+			if err != nil {
+				return added, err
 			}
+			idea.Id = int(ideaId)
+			feedItem.IdeaId = idea.Id
+			webhooks.Fire(webhooks.IdeaCreated, idea)
+			added++
 		}
+		if _, err := models.AddFeedItem(feedItem); err != nil {
+			return added, err
+		}
+	}
+	return added, nil
+}
+
+// feedIdeaText is the entry's headline, or with the feed's Rewrite on, the AI's own angle on it from feed-idea-prompt
+func feedIdeaText(feed models.Feed, item feeds.Item) (string, error) {
+	if !feed.Rewrite {
+		return item.Title, nil
+	}
+	language := resolveLanguage(Post{Language: feed.Language})
+	promptTmpl, err := texttemplate.New("feed-idea-prompt").Parse(localizedTemplate("feed-idea-prompt", language))
+	if err != nil {
+		return "", err
+	}
+	prompt := new(bytes.Buffer)
+	err = promptTmpl.Execute(prompt, FeedIdeaData{
+		Title:   item.Title,
+		Summary: item.Summary,
+		Link:    item.Link,
+		Feed:    feed.Name,
+	})
+	if err != nil {
+		return "", err
+	}
+	systemPrompt := addLanguageInstruction(localizedTemplate("system-prompt", language), language)
+	ideaText, err := openai.GenerateFeedIdea(Settings["OPENAI_API_KEY"], false, prompt.String(), systemPrompt)
+	if err != nil {
+		return "", err
+	}
+	ideaText = strings.Trim(strings.TrimSpace(ideaText), "\"")
+	if ideaText == "" {
+		return "", errors.New("The AI returned an empty idea")
 	}
+	return ideaText, nil
 }
 
 func getWpTitles() ([]string, error) {
@@ -749,8 +2318,8 @@ func doWordpressPost(endPoint string, postData map[string]interface{}) (int, err
 		postId = response.ID
 	}
 
-	// Check the response status code
-	if res.StatusCode != http.StatusCreated {
+	// Check the response status code, updates answer 200 rather than 201
+	if res.StatusCode != http.StatusCreated && res.StatusCode != http.StatusOK {
 		return -1, errors.New("Post creation failed. Status code:" + strconv.Itoa(res.StatusCode))
 	}
 	return postId, nil
@@ -786,129 +2355,640 @@ func setReqHeaders(req *http.Request, contentLength string, contentType string)
 }
 
 type MediaResponse struct {
-	ID   int    `json:"id"`
-	Link string `json:"link"`
+	ID        int    `json:"id"`
+	Link      string `json:"link"`
+	SourceUrl string `json:"source_url"`
+}
+
+func postImageToWordpress(imgBytes []byte, title string, description string, caption string) int {
+	return uploadImageToWordpress(processImage(imgBytes, true, title), description, caption).ID
+}
+
+// processImage converts, resizes and compresses an image before upload. Featured images are cropped to
+// FEATURED_IMG_WIDTH x FEATURED_IMG_HEIGHT and get the OVERLAY_ branding, inline images are only scaled down to the width.
+func processImage(imgBytes []byte, featured bool, title string) imageproc.Result {
+	width, _ := strconv.Atoi(Settings["FEATURED_IMG_WIDTH"])
+	height := 0
+	var overlay *imageproc.Overlay
+	if featured {
+		height, _ = strconv.Atoi(Settings["FEATURED_IMG_HEIGHT"])
+		overlay = imageOverlay(title)
+	}
+	return processSized(imgBytes, Settings["IMG_FORMAT"], width, height, overlay)
+}
+
+// processSized converts, resizes and compresses an image to the IMG_QUALITY and IMG_MAX_KB settings,
+// falling back to the image as it is when it can't be processed
+func processSized(imgBytes []byte, format string, width int, height int, overlay *imageproc.Overlay) imageproc.Result {
+	quality, _ := strconv.Atoi(Settings["IMG_QUALITY"])
+	maxKb, _ := strconv.Atoi(Settings["IMG_MAX_KB"])
+	if format == "webp" && !imageproc.WebPAvailable() {
+		util.Logger.Warn().Msg("cwebp not found, images will be uploaded as jpeg")
+	}
+	img, err := imageproc.Process(imgBytes, imageproc.Options{
+		Format:   format,
+		Width:    width,
+		Height:   height,
+		Quality:  quality,
+		MaxBytes: maxKb * 1024,
+		Overlay:  overlay,
+	})
+	if err != nil {
+		util.Logger.Error().Err(err).Msg("Error processing image, uploading it as is")
+		mimeType, extension := imageproc.Detect(imgBytes)
+		return imageproc.Result{Bytes: imgBytes, MimeType: mimeType, Extension: extension}
+	}
+	util.Logger.Info().Msg("Processed image: " + strconv.Itoa(len(imgBytes)) + " bytes to " + strconv.Itoa(len(img.Bytes)) + " bytes " + img.MimeType)
+	return img
+}
+
+// socialImageSizes are the share variants uploaded alongside the featured image. GenWidth and GenHeight are
+// what SOCIAL_IMG_MODE regenerate asks the image engine for, at the variant's aspect ratio.
+var socialImageSizes = []struct {
+	Name      string
+	Width     int
+	Height    int
+	GenWidth  int
+	GenHeight int
+}{
+	{Name: "opengraph", Width: 1200, Height: 630, GenWidth: 768, GenHeight: 400},
+	{Name: "square", Width: 1080, Height: 1080, GenWidth: 512, GenHeight: 512},
+}
+
+// socialImageMeta uploads the Open Graph and square variants of the featured image and returns the post meta
+// that points WP_SEO_PLUGIN at them. It returns nil when SOCIAL_IMG_ENABLE is off or there is no image.
+func socialImageMeta(post Post) map[string]interface{} {
+	if Settings["SOCIAL_IMG_ENABLE"] != "true" || len(post.Image) == 0 {
+		return nil
+	}
+	//The variants are only useful cropped, so they are never left in their original format
+	format := Settings["IMG_FORMAT"]
+	if format == "" || format == "original" {
+		format = "jpeg"
+	}
+	overlay := imageOverlay(post.Title)
+	uploaded := map[string]MediaResponse{}
+	for _, size := range socialImageSizes {
+		imgBytes := post.Image
+		if Settings["SOCIAL_IMG_MODE"] == "regenerate" {
+			regenerated, err := regenerateSocialImage(post, size.GenWidth, size.GenHeight)
+			if err != nil {
+				util.Logger.Error().Err(err).Msg("Error regenerating " + size.Name + " image, cropping the featured image instead")
+			} else if len(regenerated) > 0 {
+				imgBytes = regenerated
+			}
+		}
+		media := uploadImageToWordpress(processSized(imgBytes, format, size.Width, size.Height, overlay), post.Title+" ("+size.Name+")", post.ImageCredit)
+		if media.ID > 0 {
+			uploaded[size.Name] = media
+		}
+	}
+
+	meta := map[string]interface{}{}
+	if og, ok := uploaded["opengraph"]; ok {
+		switch Settings["WP_SEO_PLUGIN"] {
+		case "yoast":
+			meta["_yoast_wpseo_opengraph-image"] = og.SourceUrl
+			meta["_yoast_wpseo_opengraph-image-id"] = strconv.Itoa(og.ID)
+			meta["_yoast_wpseo_twitter-image"] = og.SourceUrl
+			meta["_yoast_wpseo_twitter-image-id"] = strconv.Itoa(og.ID)
+		case "rankmath":
+			meta["rank_math_facebook_image"] = og.SourceUrl
+			meta["rank_math_facebook_image_id"] = strconv.Itoa(og.ID)
+			meta["rank_math_twitter_use_facebook"] = "on"
+		}
+	}
+	if square, ok := uploaded["square"]; ok {
+		meta["blogotron_square_image"] = square.SourceUrl
+		meta["blogotron_square_image_id"] = strconv.Itoa(square.ID)
+	}
+	return meta
+}
+
+// regenerateSocialImage generates a new image from the featured image's prompt at the variant's aspect ratio,
+// returning nil when the featured image wasn't generated
+func regenerateSocialImage(post Post, width int, height int) ([]byte, error) {
+	if post.ImageGenPrompt == "" {
+		return nil, nil
+	}
+	priority := imagequeue.PriorityManual
+	if post.AutoPost {
+		priority = imagequeue.PriorityAuto
+	}
+	return generateSizedImage(post.ImgMode, post.ImageGenPrompt, width, height, priority)
 }
 
-func postImageToWordpress(imgBytes []byte, description string) int {
+// imageOverlay builds the logo watermark and title text for a featured image from the OVERLAY_ settings,
+// returning nil when neither is turned on. A logo or font that can't be loaded is logged and left out.
+func imageOverlay(title string) *imageproc.Overlay {
+	overlay := imageproc.Overlay{
+		LogoPosition: Settings["OVERLAY_LOGO_POSITION"],
+		TextPosition: Settings["OVERLAY_TEXT_POSITION"],
+	}
+	overlay.LogoSize, _ = strconv.Atoi(Settings["OVERLAY_LOGO_SIZE"])
+	overlay.LogoOpacity, _ = strconv.Atoi(Settings["OVERLAY_LOGO_OPACITY"])
+	overlay.FontSize, _ = strconv.Atoi(Settings["OVERLAY_FONT_SIZE"])
+	if Settings["OVERLAY_LOGO"] != "" {
+		logo, err := loadOverlayFile(Settings["OVERLAY_LOGO"])
+		if err != nil {
+			util.Logger.Error().Err(err).Msg("Error loading overlay logo")
+		}
+		overlay.Logo = logo
+	}
+	if Settings["OVERLAY_TITLE_ENABLE"] == "true" && title != "" {
+		overlay.Text = title
+		if Settings["OVERLAY_FONT"] != "" {
+			fontBytes, err := loadOverlayFile(Settings["OVERLAY_FONT"])
+			if err != nil {
+				util.Logger.Error().Err(err).Msg("Error loading overlay font, using the default")
+			}
+			overlay.Font = fontBytes
+		}
+		textColor, err := imageproc.ParseColor(Settings["OVERLAY_TEXT_COLOR"])
+		if err == nil {
+			overlay.TextColor = textColor
+		}
+		if Settings["OVERLAY_TEXT_BACKGROUND"] != "" {
+			background, err := imageproc.ParseColor(Settings["OVERLAY_TEXT_BACKGROUND"])
+			if err != nil {
+				util.Logger.Error().Err(err).Msg("Error reading OVERLAY_TEXT_BACKGROUND")
+			}
+			overlay.TextBackground = background
+		}
+	}
+	if len(overlay.Logo) == 0 && overlay.Text == "" {
+		return nil
+	}
+	return &overlay
+}
+
+// loadOverlayFile reads a logo or font from a local path or an http(s) url
+func loadOverlayFile(location string) ([]byte, error) {
+	if !strings.HasPrefix(location, "http://") && !strings.HasPrefix(location, "https://") {
+		return os.ReadFile(location)
+	}
+	response, err := http.Get(location)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != 200 {
+		return nil, errors.New("Bad response code downloading " + location + ": " + strconv.Itoa(response.StatusCode))
+	}
+	return io.ReadAll(response.Body)
+}
+
+// uploadImageToWordpress adds an image to the WordPress media library, returning an empty response on failure
+func uploadImageToWordpress(img imageproc.Result, description string, caption string) MediaResponse {
 	// Create a new multipart writer
 	body := &bytes.Buffer{}
 	writer := multipart.NewWriter(body)
 
-	// Create a new form file field for the image
-	part, err := writer.CreateFormFile("file", "image.jpg")
+	// Create a new form file field for the image, named for its real format so WordPress stores it correctly
+	partHeader := make(textproto.MIMEHeader)
+	partHeader.Set("Content-Disposition", `form-data; name="file"; filename="image`+img.Extension+`"`)
+	partHeader.Set("Content-Type", img.MimeType)
+	part, err := writer.CreatePart(partHeader)
 	if err != nil {
 		util.Logger.Error().Err(err).Msg("Error creating form file field")
-		return 0
+		return MediaResponse{}
+	}
+
+	// Copy the image bytes to the form file field
+	_, err = io.Copy(part, bytes.NewReader(img.Bytes))
+	if err != nil {
+		util.Logger.Error().Err(err).Msg("Error copying image bytes")
+		return MediaResponse{}
+	}
+
+	// Add the alt text as a form field
+	err = writer.WriteField("alt_text", description)
+	if err != nil {
+		util.Logger.Error().Err(err).Msg("Error writing alt text field")
+	}
+
+	// Add the photo credit as the caption
+	if caption != "" {
+		err = writer.WriteField("caption", caption)
+		if err != nil {
+			util.Logger.Error().Err(err).Msg("Error writing caption field")
+		}
+	}
+
+	// Close the multipart writer
+	err = writer.Close()
+	if err != nil {
+		util.Logger.Error().Err(err).Msg("Error closing multipart writer")
+		return MediaResponse{}
+	}
+	// Create an HTTP client
+	client := &http.Client{}
+
+	// Define the URL and request method
+	baseUrl := Settings["WP_URL"]
+	//IF baseUrl ends with a slash, remove it
+	if baseUrl[len(baseUrl)-1:] == "/" {
+		baseUrl = baseUrl[:len(baseUrl)-1]
+	}
+	url := baseUrl + "/wp-json/wp/v2/media"
+	method := "POST"
+
+	// Create a request with the multipart body
+	req, err := http.NewRequest(method, url, body)
+	var mediaResp MediaResponse
+	if err != nil {
+		util.Logger.Error().Err(err).Msg("Error creating request for image upload")
+		return MediaResponse{}
+	} else {
+		// Calculate the content length
+		contentLength := strconv.Itoa(body.Len())
+
+		req = setReqHeaders(req, contentLength, writer.FormDataContentType())
+		// Send the request
+		res, err := client.Do(req)
+		if err != nil {
+			util.Logger.Error().Err(err).Msg("Error sending request")
+			return MediaResponse{}
+		}
+		defer res.Body.Close()
+
+		// Read the response body
+		responseBody, err := ioutil.ReadAll(res.Body)
+		if err != nil {
+			util.Logger.Error().Err(err).Msg("Error reading response body")
+			return MediaResponse{}
+		}
+
+		// Check the response status code
+		if res.StatusCode != http.StatusCreated {
+			util.Logger.Error().Msg("Image upload failed with status code " + strconv.Itoa(res.StatusCode) + ". Response body: " + string(responseBody) + ".")
+			return MediaResponse{}
+		}
+
+		// Parse the response body to get the media ID
+		err = json.Unmarshal(responseBody, &mediaResp)
+		if err != nil {
+			util.Logger.Error().Err(err).Msg("Error parsing response body")
+			return MediaResponse{}
+		}
+
+		util.Logger.Info().Msg("Image uploaded successfully! Media ID:" + strconv.Itoa(mediaResp.ID))
+	}
+
+	return mediaResp
+}
+
+func postToWordpress(post Post) (int, int, error) {
+	postData := map[string]interface{}{}
+	mediaId := -1
+	if len(post.Image) > 0 {
+		util.Logger.Info().Msg("Processing Image Upload")
+		mediaId = postImageToWordpress(post.Image, post.Title, post.ImagePrompt, post.ImageCredit)
+		postData = map[string]interface{}{
+			"title":          post.Title,
+			"content":        post.Content,
+			"status":         post.PublishStatus,
+			"featured_media": mediaId,
+			"excerpt":        post.Description,
+		}
+	} else {
+		// Define the post data
+		postData = map[string]interface{}{
+			"title":   post.Title,
+			"content": post.Content,
+			"status":  post.PublishStatus,
+			"excerpt": post.Description,
+		}
+	}
+	if mediaId <= 0 && post.MediaId > 0 {
+		mediaId = post.MediaId
+		postData["featured_media"] = mediaId
+	}
+	if post.WpCategory > 0 {
+		postData["categories"] = []int{post.WpCategory}
+	}
+	meta := socialImageMeta(post)
+	endPoint := "/wp-json/wp/v2/posts"
+	if post.Language != "" {
+		switch Settings["WP_LANG_PLUGIN"] {
+		case "polylang":
+			postData["lang"] = post.Language
+			if len(post.Translations) > 0 {
+				postData["translations"] = post.Translations
+			}
+		case "wpml":
+			endPoint = endPoint + "?lang=" + post.Language
+		}
+	}
+	postId, err := doWordpressPost(endPoint, postData)
+	if err != nil {
+		util.Logger.Error().Err(err).Msg("Error creating post")
+		return -1, -1, err
+	}
+	util.Logger.Info().Msg("Post created successfully!")
+	//SEO meta goes in its own update, WordPress refuses the whole request when a key isn't writable over REST
+	if len(meta) > 0 {
+		if err := updateWordPressPostMeta(postId, meta); err != nil {
+			util.Logger.Error().Err(err).Msg("Error saving social image meta on post " + strconv.Itoa(postId))
+		}
+	}
+	return postId, mediaId, nil
+}
+
+// updateWordPressPostMeta saves post meta, failing when WordPress leaves out a key it didn't store
+func updateWordPressPostMeta(postId int, meta map[string]interface{}) error {
+	var response struct {
+		Meta json.RawMessage `json:"meta"`
+	}
+	err := postWordPressJSON("/wp-json/wp/v2/posts/"+strconv.Itoa(postId), map[string]interface{}{"meta": meta}, &response)
+	if err != nil {
+		return err
+	}
+	//With no meta registered for REST, WordPress answers with an empty array rather than an object
+	stored := map[string]interface{}{}
+	json.Unmarshal(response.Meta, &stored)
+	for key := range meta {
+		if _, ok := stored[key]; !ok {
+			return errors.New("Meta " + key + " was not saved, it must be registered with show_in_rest and an auth_callback")
+		}
+	}
+	return nil
+}
+
+// ArticleEvent is the data of the article.generated and article.published webhook events
+type ArticleEvent struct {
+	ArticleId     int    `json:"article_id"`
+	Title         string `json:"title"`
+	Description   string `json:"description"`
+	Keyword       string `json:"keyword"`
+	Status        string `json:"status"`
+	PublishStatus string `json:"publish_status"`
+	WordPressId   int    `json:"wordpress_id"`
+	Link          string `json:"link,omitempty"`
+	IdeaId        string `json:"idea_id"`
+	Language      string `json:"language"`
+	TranslationOf int    `json:"translation_of,omitempty"`
+}
+
+// JobEvent is the data of the job.failed webhook event, Job names the work that failed
+type JobEvent struct {
+	Job       string `json:"job"`
+	Error     string `json:"error"`
+	Detail    string `json:"detail,omitempty"`
+	ArticleId int    `json:"article_id,omitempty"`
+	IdeaId    int    `json:"idea_id,omitempty"`
+}
+
+// TestEvent is the data of the system.test.failed webhook event
+type TestEvent struct {
+	Test  string `json:"test"`
+	Error string `json:"error"`
+}
+
+func newArticleEvent(article models.Article, link string) ArticleEvent {
+	return ArticleEvent{
+		ArticleId:     article.Id,
+		Title:         article.Title,
+		Description:   article.Description,
+		Keyword:       article.PrimaryKeyword,
+		Status:        article.Status,
+		PublishStatus: article.PublishStatus,
+		WordPressId:   article.WordPressId,
+		Link:          link,
+		IdeaId:        article.IdeaId,
+		Language:      article.Language,
+		TranslationOf: article.TranslationOf,
+	}
+}
+
+// fireArticlePublished sends article.published along with the post's link, which is only looked up when a webhook wants it.
+// Drafts aren't published, so they don't fire it.
+func fireArticlePublished(article models.Article) {
+	if article.PublishStatus != "publish" || !webhooks.Subscribed(webhooks.ArticlePublished) {
+		return
+	}
+	link, err := getWordPressPostLink(article.WordPressId)
+	if err != nil {
+		util.Logger.Error().Err(err).Msg("Error getting post link")
+	}
+	webhooks.Fire(webhooks.ArticlePublished, newArticleEvent(article, link))
+}
+
+// SocialPostData is what the social-post templates are rendered with
+type SocialPostData struct {
+	Title       string
+	Description string
+	Link        string
+	Keyword     string
+	Hashtags    string
+	Channel     string
+}
+
+// socialMaxBytes keeps shared images under Bluesky's blob limit, the smallest of the channels
+const socialMaxBytes = 950 * 1024
+
+// socialPostTimeout bounds each channel so one that hangs doesn't hold up the rest
+const socialPostTimeout = 60 * time.Second
+
+// distributeArticle shares a published article on each of SOCIAL_CHANNELS, recording every attempt against it.
+// Channels the article was already posted to are skipped. imgBytes is the featured image, when nil it is fetched
+// from WordPress. Failures are returned joined together, none of them stop the other channels.
+func distributeArticle(article models.Article, imgBytes []byte) error {
+	var names []string
+	for _, name := range strings.Split(Settings["SOCIAL_CHANNELS"], ",") {
+		if name = strings.ToLower(strings.TrimSpace(name)); name == "" {
+			continue
+		}
+		posted, err := models.HasSocialPost(article.Id, name)
+		if err != nil {
+			util.Logger.Error().Err(err).Msg("Error checking social posts")
+		} else if !posted {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	if article.WordPressId <= 0 {
+		return errors.New("Article has not been posted to WordPress")
+	}
+	link, err := getWordPressPostLink(article.WordPressId)
+	if err != nil {
+		util.Logger.Error().Err(err).Msg("Error getting post link, sharing the short link instead")
+		link = strings.TrimRight(Settings["WP_URL"], "/") + "/?p=" + strconv.Itoa(article.WordPressId)
+	}
+	if imgBytes == nil && article.MediaId > 0 {
+		imgBytes, err = getWordPressMediaImage(article.MediaId)
+		if err != nil {
+			util.Logger.Error().Err(err).Msg("Error getting featured image, sharing without it")
+		}
+	}
+	msg := social.Message{
+		Title:       article.Title,
+		Description: article.Description,
+		Link:        link,
+		ImageAlt:    article.Title,
+	}
+	if len(imgBytes) > 0 {
+		quality, _ := strconv.Atoi(Settings["IMG_QUALITY"])
+		img, err := imageproc.Process(imgBytes, imageproc.Options{
+			Format:   "jpeg",
+			Width:    1200,
+			Quality:  quality,
+			MaxBytes: socialMaxBytes,
+			Overlay:  imageOverlay(article.Title),
+		})
+		if err != nil {
+			util.Logger.Error().Err(err).Msg("Error processing image, sharing without it")
+		} else {
+			msg.Image = img.Bytes
+			msg.ImageMime = img.MimeType
+		}
+	}
+
+	var errs []string
+	for _, name := range names {
+		socialPost := models.SocialPost{ArticleId: article.Id, Channel: name, Status: "failed"}
+		channel, ok := social.Get(name)
+		if !ok {
+			socialPost.Error = "Unknown channel " + name
+		} else {
+			channelMsg := msg
+			channelMsg.Text, err = socialPostText(channel, article, link)
+			socialPost.PostText = channelMsg.Text
+			if err != nil {
+				socialPost.Error = "Error rendering social-post template: " + err.Error()
+			} else {
+				ctx, cancel := context.WithTimeout(context.Background(), socialPostTimeout)
+				result, err := channel.Post(ctx, Settings, channelMsg)
+				cancel()
+				if err != nil {
+					socialPost.Error = err.Error()
+				} else {
+					socialPost.Status = "posted"
+					socialPost.RemoteId = result.Id
+					socialPost.RemoteUrl = result.Url
+					util.Logger.Info().Msg("Shared article " + strconv.Itoa(article.Id) + " on " + channel.Label())
+				}
+			}
+		}
+		if socialPost.Error != "" {
+			util.Logger.Error().Str("channel", name).Msg("Error sharing article: " + socialPost.Error)
+			errs = append(errs, name+": "+socialPost.Error)
+		}
+		if _, err := models.AddSocialPost(socialPost); err != nil {
+			util.Logger.Error().Err(err).Msg("Error recording social post")
+		}
+	}
+	if len(errs) > 0 {
+		err := errors.New("Sharing failed on " + strings.Join(errs, ", "))
+		webhooks.Fire(webhooks.JobFailed, JobEvent{Job: "social-share", Error: err.Error(), ArticleId: article.Id})
+		return err
+	}
+	return nil
+}
+
+// socialPostText renders the channel's social-post-<channel> template, or social-post when it has none,
+// and shortens it to fit the channel
+func socialPostText(channel social.Channel, article models.Article, link string) (string, error) {
+	text := localizedTemplate("social-post-"+channel.Name(), article.Language)
+	if strings.TrimSpace(text) == "" {
+		text = localizedTemplate("social-post", article.Language)
+	}
+	//text/template, the post is plain text and html escaping would show up in it
+	tmpl, err := texttemplate.New("social-post").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	rendered := new(bytes.Buffer)
+	err = tmpl.Execute(rendered, SocialPostData{
+		Title:       article.Title,
+		Description: article.Description,
+		Link:        link,
+		Keyword:     article.PrimaryKeyword,
+		Hashtags:    social.Hashtags(article.PrimaryKeyword),
+		Channel:     channel.Label(),
+	})
+	if err != nil {
+		return "", err
+	}
+	linkLength := 0
+	if counter, ok := channel.(social.LinkCounter); ok {
+		linkLength = counter.LinkLength()
 	}
+	return social.Fit(rendered.String(), link, channel.MaxLength(), linkLength), nil
+}
 
-	// Copy the image bytes to the form file field
-	_, err = io.Copy(part, bytes.NewReader(imgBytes))
+// getWordPressJSON sends an authenticated GET to the WordPress REST api and decodes the response into result
+func getWordPressJSON(endPoint string, result interface{}) error {
+	req, err := http.NewRequest("GET", strings.TrimRight(Settings["WP_URL"], "/")+endPoint, nil)
 	if err != nil {
-		util.Logger.Error().Err(err).Msg("Error copying image bytes")
-		return 0
+		return err
 	}
-
-	// Add the alt text as a form field
-	err = writer.WriteField("alt_text", description)
+	req = setReqHeaders(req, "", "application/json")
+	res, err := http.DefaultClient.Do(req)
 	if err != nil {
-		util.Logger.Error().Err(err).Msg("Error writing alt text field")
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return errors.New("WordPress lookup failed. Status code:" + strconv.Itoa(res.StatusCode))
 	}
+	return json.NewDecoder(res.Body).Decode(result)
+}
 
-	// Close the multipart writer
-	err = writer.Close()
+// postWordPressJSON sends body as an authenticated POST to the WordPress REST api and decodes the response into result
+func postWordPressJSON(endPoint string, body interface{}, result interface{}) error {
+	jsonData, err := json.Marshal(body)
 	if err != nil {
-		util.Logger.Error().Err(err).Msg("Error closing multipart writer")
-		return 0
+		return err
 	}
-	// Create an HTTP client
-	client := &http.Client{}
-
-	// Define the URL and request method
-	baseUrl := Settings["WP_URL"]
-	//IF baseUrl ends with a slash, remove it
-	if baseUrl[len(baseUrl)-1:] == "/" {
-		baseUrl = baseUrl[:len(baseUrl)-1]
+	req, err := http.NewRequest("POST", strings.TrimRight(Settings["WP_URL"], "/")+endPoint, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return err
 	}
-	url := baseUrl + "/wp-json/wp/v2/media"
-	method := "POST"
-
-	// Create a request with the multipart body
-	req, err := http.NewRequest(method, url, body)
-	mediaID := 0
+	req = setReqHeaders(req, strconv.Itoa(len(jsonData)), "application/json")
+	res, err := http.DefaultClient.Do(req)
 	if err != nil {
-		util.Logger.Error().Err(err).Msg("Error creating request for image upload")
-		return 0
-	} else {
-		// Calculate the content length
-		contentLength := strconv.Itoa(body.Len())
-
-		req = setReqHeaders(req, contentLength, writer.FormDataContentType())
-		// Send the request
-		res, err := client.Do(req)
-		if err != nil {
-			util.Logger.Error().Err(err).Msg("Error sending request")
-			return 0
-		}
-		defer res.Body.Close()
-
-		// Read the response body
-		responseBody, err := ioutil.ReadAll(res.Body)
-		if err != nil {
-			util.Logger.Error().Err(err).Msg("Error reading response body")
-			return 0
-		}
-
-		// Check the response status code
-		if res.StatusCode != http.StatusCreated {
-			util.Logger.Error().Msg("Image upload failed with status code " + strconv.Itoa(res.StatusCode) + ". Response body: " + string(responseBody) + ".")
-			return 0
-		}
-
-		// Parse the response body to get the media ID
-		var mediaResp MediaResponse
-		err = json.Unmarshal(responseBody, &mediaResp)
-		if err != nil {
-			util.Logger.Error().Err(err).Msg("Error parsing response body")
-			return 0
-		}
-
-		mediaID = mediaResp.ID
-		util.Logger.Info().Msg("Image uploaded successfully! Media ID:" + strconv.Itoa(mediaID))
+		return err
 	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusCreated {
+		respBody, _ := io.ReadAll(res.Body)
+		return errors.New("WordPress update failed. Status code:" + strconv.Itoa(res.StatusCode) + " " + string(respBody))
+	}
+	return json.NewDecoder(res.Body).Decode(result)
+}
 
-	return mediaID
+// getWordPressPostLink returns the permalink of a post
+func getWordPressPostLink(postId int) (string, error) {
+	var response MediaResponse
+	err := getWordPressJSON("/wp-json/wp/v2/posts/"+strconv.Itoa(postId), &response)
+	if err == nil && response.Link == "" {
+		err = errors.New("Post " + strconv.Itoa(postId) + " has no link")
+	}
+	return response.Link, err
 }
 
-func postToWordpress(post Post) (int, int, error) {
-	postData := map[string]interface{}{}
-	mediaId := -1
-	if len(post.Image) > 0 {
-		util.Logger.Info().Msg("Processing Image Upload")
-		mediaId = postImageToWordpress(post.Image, post.ImagePrompt)
-		postData = map[string]interface{}{
-			"title":          post.Title,
-			"content":        post.Content,
-			"status":         post.PublishStatus,
-			"featured_media": mediaId,
-			"excerpt":        post.Description,
-		}
-	} else {
-		// Define the post data
-		postData = map[string]interface{}{
-			"title":   post.Title,
-			"content": post.Content,
-			"status":  post.PublishStatus,
-			"excerpt": post.Description,
-		}
+// getWordPressMediaImage downloads the image file behind a media library item
+func getWordPressMediaImage(mediaId int) ([]byte, error) {
+	var response MediaResponse
+	err := getWordPressJSON("/wp-json/wp/v2/media/"+strconv.Itoa(mediaId), &response)
+	if err != nil {
+		return nil, err
 	}
-	postId, err := doWordpressPost("/wp-json/wp/v2/posts", postData)
+	if response.SourceUrl == "" {
+		return nil, errors.New("Media " + strconv.Itoa(mediaId) + " has no source url")
+	}
+	res, err := http.Get(response.SourceUrl)
 	if err != nil {
-		util.Logger.Error().Err(err).Msg("Error creating post")
-		return -1, -1, err
+		return nil, err
 	}
-	util.Logger.Info().Msg("Post created successfully!")
-	return postId, mediaId, nil
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, errors.New("Bad response code downloading " + response.SourceUrl + ": " + strconv.Itoa(res.StatusCode))
+	}
+	return io.ReadAll(res.Body)
 }
 
 func loadSettings() {
@@ -936,6 +3016,7 @@ func testWordPress() {
 	_, err := getWpTitles()
 	if err != nil {
 		util.Logger.Error().Err(err).Msg("Error getting WordPress titles")
+		webhooks.Fire(webhooks.SystemTestFailed, TestEvent{Test: "WordPress", Error: err.Error()})
 	} else {
 		util.Logger.Info().Msg("WordPress Connection Successful!")
 		WordPressStatus = true
@@ -952,6 +3033,7 @@ func testOpenAI() {
 	aiTestResp, err := openai.GenerateTestGreeting(Settings["OPENAI_API_KEY"], false, "You are running your start-up diagnostics, compose some humorous fake startup sequence events and a greeting as a sort of boot-up log and return them.  This response should be formatted an <ul> in HTML to be inserted into a status page.  Class \"font-monospace\" should be used on the text to give it a robotic feel.  The page already exists, we just need to drop in the HTML greeting inside the existing HTML page we have, so it should not include a body or head or close or open html tags, just the markup for the text itself within the page.", "You are Blog-o-Tron a sophisticated, AI-powered blogging robot.")
 	if err != nil {
 		util.Logger.Error().Err(err).Msg("Error testing OpenAI API")
+		webhooks.Fire(webhooks.SystemTestFailed, TestEvent{Test: "OpenAI", Error: err.Error()})
 	} else {
 		util.Logger.Info().Msg("OpenAI Connection Successful!")
 		OpenAiStatus = true
@@ -970,6 +3052,7 @@ func testUnsplash() {
 	_, err := unsplash.GetImageBySearch(Settings["UNSPLASH_ACCESS_KEY"], "robot")
 	if err != nil {
 		util.Logger.Error().Err(err).Msg("Error testing Unsplash API")
+		webhooks.Fire(webhooks.SystemTestFailed, TestEvent{Test: "Unsplash", Error: err.Error()})
 	} else {
 		util.Logger.Info().Msg("Unsplash Connection Successful!")
 		UnsplashStatus = true
@@ -979,21 +3062,39 @@ func testUnsplash() {
 	models.UpsertStatus("LastTestTime", LastTestTime)
 }
 
-func testStableDiffusion() {
+// imageTestTimeout bounds an engine's own connection check, a test that generates an image uses IMG_TIMEOUT
+const imageTestTimeout = 30 * time.Second
+
+func testImageEngine() {
 	SdStatus = false
-	//Test StableDiffusion Connection
-	if Settings["IMG_MODE"] == "sd" {
-		util.Logger.Info().Msg("Testing StableDiffusion Connection...")
-		imgResp, err := generateSizedImage("An selfie image of Blog-o-Tron the blog-writing robot sitting in front of a computer in a futuristic lab waving at the camera.  Centered and in focus. Photo-realistic, Hyper-realistic, Portrait, Well Lit", 512, 512)
+	//Test the image engine selected by IMG_MODE
+	generator, ok := imageEngine("")
+	if ok {
+		util.Logger.Info().Msg("Testing " + generator.Label() + " Connection...")
+		var err error
+		if tester, ok := generator.(imagegen.Tester); ok {
+			ctx, cancel := context.WithTimeout(context.Background(), imageTestTimeout)
+			err = tester.Test(ctx, Settings)
+			cancel()
+		} else {
+			var imgResp []byte
+			imgResp, err = generateSizedImage("", "An selfie image of Blog-o-Tron the blog-writing robot sitting in front of a computer in a futuristic lab waving at the camera.  Centered and in focus. Photo-realistic, Hyper-realistic, Portrait, Well Lit", 512, 512, imagequeue.PriorityAuto)
+			if err == nil && len(imgResp) == 0 {
+				err = errors.New("No image returned")
+			}
+			if err == nil {
+				Selfie = imgResp
+			}
+		}
 		if err != nil {
-			util.Logger.Error().Err(err).Msg("Error testing StableDiffusion API")
+			util.Logger.Error().Err(err).Msg("Error testing " + generator.Label())
+			webhooks.Fire(webhooks.SystemTestFailed, TestEvent{Test: generator.Label(), Error: err.Error()})
 		} else {
-			util.Logger.Info().Msg("StableDiffusion Connection Successful!")
+			util.Logger.Info().Msg(generator.Label() + " Connection Successful!")
 			SdStatus = true
-			Selfie = imgResp
 		}
 	} else {
-		util.Logger.Error().Msg("StableDiffusion is not enabled")
+		util.Logger.Error().Msg("No image engine is enabled")
 	}
 	models.UpsertStatus("StableDiffusion", strconv.FormatBool(SdStatus))
 	LastTestTime = time.Now().Format("Jan 2, 2006 at 3:04pm (MST)")
@@ -1005,7 +3106,7 @@ func runSystemTests() {
 	util.Logger.Info().Msg("Running system tests...")
 	testWordPress()
 	testOpenAI()
-	testStableDiffusion()
+	testImageEngine()
 	testUnsplash()
 	util.Logger.Info().Msg("System tests complete!")
 }
@@ -1081,3 +3182,413 @@ func getWordPressMediaUrlFromId(mediaID int) (string, error) {
 	}
 	return respUrl, nil
 }
+
+// getWordPressPostViews reads the view count a view counter plugin keeps on the post
+func getWordPressPostViews(wpPost map[string]interface{}, postId int, viewsField string) (int, error) {
+	//View counter plugins expose their count either as a top level field or as post meta
+	value, ok := wpPost[viewsField]
+	if !ok {
+		if meta, isMap := wpPost["meta"].(map[string]interface{}); isMap {
+			value, ok = meta[viewsField]
+		}
+	}
+	if !ok {
+		return 0, errors.New("Field " + viewsField + " not found on post " + strconv.Itoa(postId))
+	}
+	switch v := value.(type) {
+	case float64:
+		return int(v), nil
+	case string:
+		return strconv.Atoi(v)
+	case []interface{}:
+		if len(v) > 0 {
+			return strconv.Atoi(fmt.Sprint(v[0]))
+		}
+	}
+	return 0, errors.New("Field " + viewsField + " on post " + strconv.Itoa(postId) + " is not a number")
+}
+
+// getWordPressPostModified returns when a post was last saved, in GMT
+func getWordPressPostModified(postId int) (string, error) {
+	var wpPost struct {
+		ModifiedGmt string `json:"modified_gmt"`
+	}
+	err := getWordPressJSON("/wp-json/wp/v2/posts/"+strconv.Itoa(postId), &wpPost)
+	return wpPost.ModifiedGmt, err
+}
+
+// rememberWordPressModified records when the BOT last saved an article's post, so a later change to it can be told
+// apart as a manual edit
+func rememberWordPressModified(article models.Article) {
+	if article.Id <= 0 || article.WordPressId <= 0 {
+		return
+	}
+	modified, err := getWordPressPostModified(article.WordPressId)
+	if err != nil {
+		util.Logger.Error().Err(err).Msg("Error getting modified time of post " + strconv.Itoa(article.WordPressId))
+		return
+	}
+	_, err = models.SetArticleWpModified(article.Id, modified)
+	if err != nil {
+		util.Logger.Error().Err(err).Msg("Error saving modified time of post")
+	}
+}
+
+// refreshExperimentViews reads each experiment article's post for its view count, and marks it manually edited
+// when the post has been saved since the BOT last saved it
+func refreshExperimentViews() {
+	viewsField := Settings["WP_VIEWS_FIELD"]
+	articles, err := models.GetExperimentArticles()
+	if err != nil {
+		util.Logger.Error().Err(err).Msg("Error getting experiment articles")
+		return
+	}
+	for _, article := range articles {
+		var wpPost map[string]interface{}
+		err := getWordPressJSON("/wp-json/wp/v2/posts/"+strconv.Itoa(article.WordPressId), &wpPost)
+		if err != nil {
+			util.Logger.Error().Err(err).Msg("Error getting post " + strconv.Itoa(article.WordPressId))
+			continue
+		}
+		if modified, _ := wpPost["modified_gmt"].(string); modified != "" {
+			if article.WpModified == "" {
+				//Posted before edits were tracked, start from how the post is now
+				_, err = models.SetArticleWpModified(article.Id, modified)
+			} else if modified != article.WpModified {
+				_, err = models.SetArticleWpEdited(article.Id)
+			}
+			if err != nil {
+				util.Logger.Error().Err(err).Msg("Error updating edit tracking")
+			}
+		}
+		if viewsField == "" {
+			continue
+		}
+		views, err := getWordPressPostViews(wpPost, article.WordPressId, viewsField)
+		if err != nil {
+			util.Logger.Error().Err(err).Msg("Error getting view count for post " + strconv.Itoa(article.WordPressId))
+			continue
+		}
+		_, err = models.UpdateArticleViews(article.Id, views)
+		if err != nil {
+			util.Logger.Error().Err(err).Msg("Error updating view count")
+		}
+	}
+}
+
+// translateArticle writes a translated copy of an article, publishes it and links it to the original
+func translateArticle(articleId int, language string, publishStatus string) (models.Article, error) {
+	source, err := models.GetArticleById(articleId)
+	if err != nil {
+		return models.Article{}, err
+	}
+	languageName, ok := LanguageNames[language]
+	if !ok {
+		return models.Article{}, errors.New("Unknown language: " + language)
+	}
+	sourceLanguage := source.Language
+	if sourceLanguage == "" {
+		sourceLanguage = Settings["DEFAULT_LANGUAGE"]
+	}
+	if sourceLanguage == language {
+		return models.Article{}, errors.New("Article is already written in " + languageName)
+	}
+	aiApiKey := Settings["OPENAI_API_KEY"]
+	systemPrompt := addLanguageInstruction(localizedTemplate("system-prompt", language), language)
+	translatePrompt := "Translate your text into " + languageName + "."
+	title, err := openai.GenerateTranslation(aiApiKey, false, source.Title, translatePrompt, systemPrompt)
+	if err != nil {
+		return models.Article{}, err
+	}
+	description, err := openai.GenerateTranslation(aiApiKey, false, source.Description, translatePrompt, systemPrompt)
+	if err != nil {
+		return models.Article{}, err
+	}
+	//The series navigation links the original language's posts, so it is left out of the translation
+	content, err := openai.GenerateTranslation(aiApiKey, false, withSeriesNav(source.Content, ""), translatePrompt, systemPrompt)
+	if err != nil {
+		return models.Article{}, err
+	}
+	post := Post{
+		Title:         strings.Trim(title, "\""),
+		Content:       content,
+		Description:   description,
+		PublishStatus: publishStatus,
+		Language:      language,
+		MediaId:       source.MediaId,
+	}
+	if source.WordPressId > 0 && sourceLanguage != "" {
+		post.Translations = map[string]int{sourceLanguage: source.WordPressId}
+	}
+	postId, mediaId, err := postToWordpress(post)
+	if err != nil {
+		return models.Article{}, err
+	}
+	translation := models.Article{
+		Title:          post.Title,
+		Content:        post.Content,
+		Description:    post.Description,
+		PrimaryKeyword: source.PrimaryKeyword,
+		MediaId:        mediaId,
+		Prompt:         source.Prompt,
+		Concept:        source.Concept,
+		IdeaId:         source.IdeaId,
+		Status:         "translated",
+		Version:        1,
+		WordPressId:    postId,
+		PersonaId:      source.PersonaId,
+		Language:       language,
+		TranslationOf:  source.Id,
+		PublishStatus:  publishStatus,
+	}
+	translationId, err := models.UpsertArticle(translation)
+	if err != nil {
+		return models.Article{}, err
+	}
+	translation.Id = int(translationId)
+	rememberWordPressModified(translation)
+	fireArticlePublished(translation)
+	return translation, nil
+}
+
+// approveArticle posts a reviewed article to WordPress
+func approveArticle(articleId int, reviewer string, comment string) error {
+	article, err := models.GetArticleById(articleId)
+	if err != nil {
+		return err
+	}
+	if article.Status != "review" {
+		return errors.New("Article is not waiting for review")
+	}
+	article.Reviewer = reviewer
+	article.ReviewComment = comment
+	return publishHeldArticle(article)
+}
+
+// publishHeldArticle posts an article that was held back from WordPress, along with the image held for it
+func publishHeldArticle(article models.Article) error {
+	articleId := article.Id
+	reviewImg, err := models.GetArticleReviewImage(articleId)
+	if err != nil {
+		return err
+	}
+	imgBytes, err := base64.StdEncoding.DecodeString(reviewImg)
+	if err != nil {
+		return err
+	}
+	publishStatus := article.PublishStatus
+	if publishStatus == "" {
+		publishStatus = "draft"
+	}
+	post := Post{
+		Title:         article.Title,
+		Content:       addSeriesNav(addImageCredit(addSectionImages(article.Content, article.Title, false), article.ImgCredit), article.IdeaId, article.Language),
+		Description:   article.Description,
+		PublishStatus: publishStatus,
+		WpCategory:    article.WpCategory,
+		Language:      article.Language,
+		Image:         imgBytes,
+		ImagePrompt:   article.ImgPrompt,
+		ImageCredit:   article.ImgCredit,
+	}
+	//Stock photos and downloads have nothing to regenerate social images from
+	if article.ImgSearch == "" && article.ImgSrcUrl == "" && article.ImgCredit == "" {
+		post.ImageGenPrompt = article.ImgPrompt
+	}
+	postId, mediaId, err := postToWordpress(post)
+	if err != nil {
+		return err
+	}
+	changedBy := article.Reviewer
+	if changedBy == "" {
+		changedBy = "web"
+	}
+	setIdeaPosted(article.IdeaId, publishStatus, changedBy, postId)
+	article.WordPressId = postId
+	article.MediaId = mediaId
+	article.Content = post.Content
+	article.Status = "written"
+	_, err = models.UpsertArticle(article)
+	if err != nil {
+		return err
+	}
+	rememberWordPressModified(article)
+	fireArticlePublished(article)
+	relinkSeries(article.IdeaId)
+	if publishStatus == "publish" {
+		if err := distributeArticle(article, imgBytes); err != nil {
+			util.Logger.Error().Err(err).Msg("Error sharing article")
+		}
+	}
+	_, err = models.SetArticleReviewImage(articleId, "")
+	return err
+}
+
+// rejectArticle removes an article from the review queue and frees its idea to be written again
+func rejectArticle(articleId int, reviewer string, comment string) error {
+	article, err := models.GetArticleById(articleId)
+	if err != nil {
+		return err
+	}
+	if article.Status != "review" {
+		return errors.New("Article is not waiting for review")
+	}
+	article.Status = "rejected"
+	article.Reviewer = reviewer
+	article.ReviewComment = comment
+	_, err = models.UpsertArticle(article)
+	if err != nil {
+		return err
+	}
+	if article.IdeaId != "" {
+		changedBy := reviewer
+		if changedBy == "" {
+			changedBy = "web"
+		}
+		_, err = models.SetIdeaStatus(article.IdeaId, models.IdeaNew, changedBy, "Article #"+strconv.Itoa(articleId)+" rejected")
+		if err != nil {
+			util.Logger.Error().Err(err).Msg("Error freeing rejected article's idea")
+		}
+	}
+	_, err = models.SetArticleReviewImage(articleId, "")
+	return err
+}
+
+// rewriteArticle revises an article using the reviewer's comment as instructions and returns it to the queue
+func rewriteArticle(articleId int, reviewer string, comment string) error {
+	if strings.TrimSpace(comment) == "" {
+		return errors.New("Please add a comment describing the rewrite")
+	}
+	article, err := models.GetArticleById(articleId)
+	if err != nil {
+		return err
+	}
+	if article.Status != "review" {
+		return errors.New("Article is not waiting for review")
+	}
+	systemPrompt := addLanguageInstruction(localizedTemplate("system-prompt", article.Language), article.Language)
+	if article.PersonaId > 0 {
+		persona, err := models.GetPersonaById(strconv.Itoa(article.PersonaId))
+		if err != nil {
+			util.Logger.Error().Err(err).Msg("Error getting persona for rewrite")
+		} else if persona.Id > 0 {
+			systemPrompt = buildPersonaPrompt(systemPrompt, persona)
+		}
+	}
+	rewritePrompt := "Rewrite the article following this feedback from an editor: " + comment
+	revision, err := openai.GenerateRevision(Settings["OPENAI_API_KEY"], false, article.Content, rewritePrompt, systemPrompt)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(revision) == "" {
+		return errors.New("Rewrite came back empty")
+	}
+	article.Content = revision
+	article.Version = article.Version + 1
+	article.Status = "review"
+	article.Reviewer = reviewer
+	article.ReviewComment = comment
+	_, err = models.UpsertArticle(article)
+	return err
+}
+
+// pickArticleImage makes one of an article's candidate images its featured image and sends it on
+// to the review queue, or straight to WordPress when reviews are off
+func pickArticleImage(articleId int, candidateId int) error {
+	article, err := models.GetArticleById(articleId)
+	if err != nil {
+		return err
+	}
+	if article.Status != "pending-image" {
+		return errors.New("Article is not waiting for an image")
+	}
+	candidates, err := models.GetImageCandidates(articleId)
+	if err != nil {
+		return err
+	}
+	chosen := models.ImageCandidate{}
+	for _, candidate := range candidates {
+		if candidate.Id == candidateId {
+			chosen = candidate
+		}
+	}
+	if chosen.ImageData == "" {
+		return errors.New("Image candidate not found")
+	}
+	if chosen.Credit != "" {
+		article.ImgCredit = chosen.Credit
+		article.ImgLicense = chosen.License
+		trackStockDownload(stockimg.Photo{Provider: chosen.Source, DownloadLocation: chosen.DownloadUrl})
+	}
+	_, err = models.SetArticleReviewImage(articleId, chosen.ImageData)
+	if err != nil {
+		return err
+	}
+	_, err = models.DeleteImageCandidates(articleId)
+	if err != nil {
+		util.Logger.Error().Err(err).Msg("Error removing image candidates")
+	}
+	if Settings["REVIEW_ENABLE"] == "true" {
+		article.Status = "review"
+		_, err = models.UpsertArticle(article)
+		return err
+	}
+	return publishHeldArticle(article)
+}
+
+// mediaDir holds the files of the local image library
+const mediaDir = "data/media"
+
+// saveToLibrary writes an image into the media directory and records it in the library, returning 0 when it couldn't be kept
+func saveToLibrary(imgBytes []byte, media models.Media) int {
+	if len(imgBytes) == 0 {
+		return 0
+	}
+	err := os.MkdirAll(mediaDir, 0755)
+	if err != nil {
+		util.Logger.Error().Err(err).Msg("Error creating media directory")
+		return 0
+	}
+	mimeType, extension := imageproc.Detect(imgBytes)
+	media.MimeType = mimeType
+	media.FileName = strconv.FormatInt(time.Now().UnixNano(), 10) + extension
+	err = os.WriteFile(filepath.Join(mediaDir, media.FileName), imgBytes, 0644)
+	if err != nil {
+		util.Logger.Error().Err(err).Msg("Error writing media file")
+		return 0
+	}
+	mediaId, err := models.AddMedia(media)
+	if err != nil {
+		util.Logger.Error().Err(err).Msg("Error adding media to library")
+		return 0
+	}
+	return int(mediaId)
+}
+
+func loadFromLibrary(mediaId int) ([]byte, models.Media, error) {
+	media, err := models.GetMediaById(mediaId)
+	if err != nil {
+		return nil, media, err
+	}
+	if media.Id == 0 {
+		return nil, media, errors.New("Library image not found: " + strconv.Itoa(mediaId))
+	}
+	imgBytes, err := os.ReadFile(filepath.Join(mediaDir, media.FileName))
+	return imgBytes, media, err
+}
+
+func deleteFromLibrary(mediaId int) error {
+	media, err := models.GetMediaById(mediaId)
+	if err != nil {
+		return err
+	}
+	if media.Id == 0 {
+		return nil
+	}
+	err = os.Remove(filepath.Join(mediaDir, media.FileName))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	_, err = models.DeleteMedia(mediaId)
+	return err
+}
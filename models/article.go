@@ -1,34 +1,51 @@
 package models
 
 import (
+	"database/sql"
 	_ "modernc.org/sqlite"
 )
 
 type Article struct {
-	Id             int    `json:"id"`
-	Title          string `json:"title"`
-	Content        string `json:"content"`
-	Description    string `json:"description"`
-	PrimaryKeyword string `json:"primary_keyword"`
-	MediaId        int    `json:"media_id"`
-	Prompt         string `json:"prompt"`
-	YtUrl          string `json:"yt_url"`
-	ImgPrompt      string `json:"img_prompt"`
-	ImgSearch      string `json:"img_search"`
-	ImgSrcUrl      string `json:"img_src_url"`
-	Concept        string `json:"concept"`
-	IdeaId         string `json:"idea_id"`
-	Status         string `json:"status"`
-	Version        int    `json:"version"`
-	CreateDate     string `json:"create_dt"`
-	UpdateDate     string `json:"update_dt"`
-	WordPressId    int    `json:"wordpress_id"`
+	Id              int    `json:"id"`
+	Title           string `json:"title"`
+	Content         string `json:"content"`
+	Description     string `json:"description"`
+	PrimaryKeyword  string `json:"primary_keyword"`
+	MediaId         int    `json:"media_id"`
+	Prompt          string `json:"prompt"`
+	YtUrl           string `json:"yt_url"`
+	ImgPrompt       string `json:"img_prompt"`
+	ImgSearch       string `json:"img_search"`
+	ImgSrcUrl       string `json:"img_src_url"`
+	Concept         string `json:"concept"`
+	IdeaId          string `json:"idea_id"`
+	Status          string `json:"status"`
+	Version         int    `json:"version"`
+	CreateDate      string `json:"create_dt"`
+	UpdateDate      string `json:"update_dt"`
+	WordPressId     int    `json:"wordpress_id"`
+	VariantId       int    `json:"variant_id"`
+	WpViews         int    `json:"wp_views"`
+	WpModified      string `json:"wp_modified"`
+	WpEdited        bool   `json:"wp_edited"`
+	PersonaId       int    `json:"persona_id"`
+	PersonaFlags    string `json:"persona_flags"`
+	Language        string `json:"language"`
+	TranslationOf   int    `json:"translation_of"`
+	FactCheckRisk   string `json:"fact_check_risk"`
+	FactCheckReport string `json:"fact_check_report"`
+	PublishStatus   string `json:"publish_status"`
+	WpCategory      int    `json:"wp_category"`
+	Reviewer        string `json:"reviewer"`
+	ReviewComment   string `json:"review_comment"`
+	ImgCredit       string `json:"img_credit"`
+	ImgLicense      string `json:"img_license"`
 }
 
 func GetArticles() ([]Article, error) {
 
 	rows, err := DB.Query("SELECT id, wordpress_id, title, content, description, primary_keyword, media_id, prompt, yt_url, img_prompt, " +
-		"img_search, img_src_url, concept, idea_id, status, version, create_dt, update_dt from articles ")
+		"img_search, img_src_url, concept, idea_id, status, version, create_dt, update_dt, variant_id, wp_views, persona_id, persona_flags, language, translation_of, fact_check_risk, fact_check_report, publish_status, wp_category, reviewer, review_comment, img_credit, img_license, wp_modified, wp_edited from articles ")
 
 	if err != nil {
 		return nil, err
@@ -44,7 +61,8 @@ func GetArticles() ([]Article, error) {
 			&singleEntry.PrimaryKeyword, &singleEntry.MediaId, &singleEntry.Prompt, &singleEntry.YtUrl,
 			&singleEntry.ImgPrompt, &singleEntry.ImgSearch, &singleEntry.ImgSrcUrl, &singleEntry.Concept,
 			&singleEntry.IdeaId, &singleEntry.Status, &singleEntry.Version,
-			&singleEntry.CreateDate, &singleEntry.UpdateDate)
+			&singleEntry.CreateDate, &singleEntry.UpdateDate, &singleEntry.VariantId, &singleEntry.WpViews, &singleEntry.PersonaId, &singleEntry.PersonaFlags, &singleEntry.Language, &singleEntry.TranslationOf, &singleEntry.FactCheckRisk, &singleEntry.FactCheckReport,
+			&singleEntry.PublishStatus, &singleEntry.WpCategory, &singleEntry.Reviewer, &singleEntry.ReviewComment, &singleEntry.ImgCredit, &singleEntry.ImgLicense, &singleEntry.WpModified, &singleEntry.WpEdited)
 
 		if err != nil {
 			return nil, err
@@ -64,44 +82,287 @@ func GetArticles() ([]Article, error) {
 func GetArticleById(id int) (Article, error) {
 
 	row := DB.QueryRow("SELECT id, wordpress_id, title, content, description, primary_keyword, media_id, prompt, yt_url, img_prompt, "+
-		"img_search, img_src_url, concept, idea_id, status, version, create_dt, update_dt from articles where id = ?", id)
+		"img_search, img_src_url, concept, idea_id, status, version, create_dt, update_dt, variant_id, wp_views, persona_id, persona_flags, language, translation_of, fact_check_risk, fact_check_report, publish_status, wp_category, reviewer, review_comment, img_credit, img_license, wp_modified, wp_edited from articles where id = ?", id)
 
 	singleEntry := Article{}
 	err := row.Scan(&singleEntry.Id, &singleEntry.WordPressId, &singleEntry.Title, &singleEntry.Content, &singleEntry.Description,
 		&singleEntry.PrimaryKeyword, &singleEntry.MediaId, &singleEntry.Prompt, &singleEntry.YtUrl,
 		&singleEntry.ImgPrompt, &singleEntry.ImgSearch, &singleEntry.ImgSrcUrl, &singleEntry.Concept,
 		&singleEntry.IdeaId, &singleEntry.Status, &singleEntry.Version,
-		&singleEntry.CreateDate, &singleEntry.UpdateDate)
+		&singleEntry.CreateDate, &singleEntry.UpdateDate, &singleEntry.VariantId, &singleEntry.WpViews, &singleEntry.PersonaId, &singleEntry.PersonaFlags, &singleEntry.Language, &singleEntry.TranslationOf, &singleEntry.FactCheckRisk, &singleEntry.FactCheckReport,
+		&singleEntry.PublishStatus, &singleEntry.WpCategory, &singleEntry.Reviewer, &singleEntry.ReviewComment, &singleEntry.ImgCredit, &singleEntry.ImgLicense, &singleEntry.WpModified, &singleEntry.WpEdited)
 
 	return singleEntry, err
 }
 
+// UpsertArticle inserts a new article, or updates the existing one when article.Id is set
 func UpsertArticle(article Article) (int64, error) {
 
-	stmt, err := DB.Prepare("INSERT INTO articles (wordpress_id, title, content, description, primary_keyword, media_id, prompt, yt_url, img_prompt, " +
-		"img_search, img_src_url, concept, idea_id, status, version, create_dt, update_dt) " +
-		"VALUES (?, ?, ?, ?, ?, ?,?, ?, ?, ?, ?, ?, ?, ?, ?, current_timestamp, current_timestamp) " +
+	stmt, err := DB.Prepare("INSERT INTO articles (id, wordpress_id, title, content, description, primary_keyword, media_id, prompt, yt_url, img_prompt, " +
+		"img_search, img_src_url, concept, idea_id, status, version, variant_id, persona_id, persona_flags, language, translation_of, fact_check_risk, fact_check_report, " +
+		"publish_status, wp_category, reviewer, review_comment, img_credit, img_license, create_dt, update_dt) " +
+		"VALUES (?, ?, ?, ?, ?, ?, ?,?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, current_timestamp, current_timestamp) " +
 		"ON CONFLICT(id) DO UPDATE SET wordpress_id = ?, title = ?, content = ?, description = ?, primary_keyword = ?, media_id = ?, prompt = ?, yt_url = ?, img_prompt = ?, " +
-		"img_search = ?, img_src_url = ?, concept = ?, idea_id = ?, status = ?, version = ?, update_dt = current_timestamp")
+		"img_search = ?, img_src_url = ?, concept = ?, idea_id = ?, status = ?, version = ?, variant_id = ?, persona_id = ?, persona_flags = ?, language = ?, translation_of = ?, " +
+		"fact_check_risk = ?, fact_check_report = ?, publish_status = ?, wp_category = ?, reviewer = ?, review_comment = ?, img_credit = ?, img_license = ?, update_dt = current_timestamp")
 
 	if err != nil {
 		return -1, err
 	}
 
-	res, err := stmt.Exec(article.WordPressId, article.Title, article.Content, article.Description, article.PrimaryKeyword, article.MediaId, article.Prompt, article.YtUrl,
-		article.ImgPrompt, article.ImgSearch, article.ImgSrcUrl, article.Concept, article.IdeaId, article.Status, article.Version,
+	//A nil id lets sqlite assign the next one for new articles
+	var id interface{}
+	if article.Id > 0 {
+		id = article.Id
+	}
+
+	res, err := stmt.Exec(id, article.WordPressId, article.Title, article.Content, article.Description, article.PrimaryKeyword, article.MediaId, article.Prompt, article.YtUrl,
+		article.ImgPrompt, article.ImgSearch, article.ImgSrcUrl, article.Concept, article.IdeaId, article.Status, article.Version, article.VariantId, article.PersonaId, article.PersonaFlags, article.Language, article.TranslationOf,
+		article.FactCheckRisk, article.FactCheckReport, article.PublishStatus, article.WpCategory, article.Reviewer, article.ReviewComment, article.ImgCredit, article.ImgLicense,
 		article.WordPressId, article.Title, article.Content, article.Description, article.PrimaryKeyword, article.MediaId, article.Prompt, article.YtUrl,
-		article.ImgPrompt, article.ImgSearch, article.ImgSrcUrl, article.Concept, article.IdeaId, article.Status, article.Version)
+		article.ImgPrompt, article.ImgSearch, article.ImgSrcUrl, article.Concept, article.IdeaId, article.Status, article.Version, article.VariantId, article.PersonaId, article.PersonaFlags, article.Language, article.TranslationOf,
+		article.FactCheckRisk, article.FactCheckReport, article.PublishStatus, article.WpCategory, article.Reviewer, article.ReviewComment, article.ImgCredit, article.ImgLicense)
 
 	if err != nil {
 		return -1, err
 	}
 
-	id, err := res.LastInsertId()
+	if article.Id > 0 {
+		return int64(article.Id), nil
+	}
+
+	lastId, err := res.LastInsertId()
 
 	if err != nil {
 		return -1, err
 	}
 
+	return lastId, err
+}
+
+func UpdateArticleViews(id int, views int) (bool, error) {
+
+	tx, err := DB.Begin()
+	if err != nil {
+		return false, err
+	}
+
+	stmt, err := tx.Prepare("UPDATE articles SET wp_views = ? WHERE id = ?")
+
+	if err != nil {
+		return false, err
+	}
+
+	defer stmt.Close()
+
+	_, err = stmt.Exec(views, id)
+
+	if err != nil {
+		return false, err
+	}
+
+	tx.Commit()
+
+	return true, nil
+}
+
+// SetArticleWpModified records the modified time of the article's WordPress post after the BOT saved it
+func SetArticleWpModified(id int, modified string) (bool, error) {
+
+	tx, err := DB.Begin()
+	if err != nil {
+		return false, err
+	}
+
+	stmt, err := tx.Prepare("UPDATE articles SET wp_modified = ? WHERE id = ?")
+
+	if err != nil {
+		return false, err
+	}
+
+	defer stmt.Close()
+
+	_, err = stmt.Exec(modified, id)
+
+	if err != nil {
+		return false, err
+	}
+
+	tx.Commit()
+
+	return true, nil
+}
+
+// SetArticleWpEdited marks an article whose WordPress post was changed after the BOT last saved it
+func SetArticleWpEdited(id int) (bool, error) {
+
+	tx, err := DB.Begin()
+	if err != nil {
+		return false, err
+	}
+
+	stmt, err := tx.Prepare("UPDATE articles SET wp_edited = 1 WHERE id = ?")
+
+	if err != nil {
+		return false, err
+	}
+
+	defer stmt.Close()
+
+	_, err = stmt.Exec(id)
+
+	if err != nil {
+		return false, err
+	}
+
+	tx.Commit()
+
+	return true, nil
+}
+
+// GetPublishedIdeaArticleId returns the latest article written from an idea that was posted to WordPress,
+// leaving out translations, or 0 when there is none
+func GetPublishedIdeaArticleId(ideaId int) (int, error) {
+	var id int
+	err := DB.QueryRow("SELECT id from articles WHERE idea_id = ? AND wordpress_id > 0 AND coalesce(translation_of, 0) = 0 ORDER BY id DESC LIMIT 1", ideaId).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
 	return id, err
 }
+
+// GetArticleTranslations returns the translated copies of an article
+func GetArticleTranslations(id int) ([]Article, error) {
+
+	rows, err := DB.Query("SELECT id, wordpress_id, title, language, status, create_dt from articles WHERE translation_of = ?", id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	articles := make([]Article, 0)
+
+	for rows.Next() {
+		singleEntry := Article{}
+		err = rows.Scan(&singleEntry.Id, &singleEntry.WordPressId, &singleEntry.Title, &singleEntry.Language, &singleEntry.Status, &singleEntry.CreateDate)
+
+		if err != nil {
+			return nil, err
+		}
+
+		articles = append(articles, singleEntry)
+	}
+
+	err = rows.Err()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return articles, err
+}
+
+// GetIdeaArticles returns every article written from an idea, translations included, newest first
+func GetIdeaArticles(ideaId int) ([]Article, error) {
+
+	rows, err := DB.Query("SELECT id, wordpress_id, title, language, status, coalesce(publish_status, ''), coalesce(translation_of, 0), create_dt from articles WHERE idea_id = ? ORDER BY id DESC", ideaId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	articles := make([]Article, 0)
+
+	for rows.Next() {
+		singleEntry := Article{}
+		err = rows.Scan(&singleEntry.Id, &singleEntry.WordPressId, &singleEntry.Title, &singleEntry.Language, &singleEntry.Status,
+			&singleEntry.PublishStatus, &singleEntry.TranslationOf, &singleEntry.CreateDate)
+
+		if err != nil {
+			return nil, err
+		}
+
+		articles = append(articles, singleEntry)
+	}
+
+	err = rows.Err()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return articles, err
+}
+
+// GetReviewArticles returns the articles waiting in the review queue, oldest first
+func GetReviewArticles() ([]Article, error) {
+
+	rows, err := DB.Query("SELECT id, title, content, description, primary_keyword, concept, idea_id, version, language, persona_flags, fact_check_risk, " +
+		"publish_status, reviewer, review_comment, create_dt, update_dt from articles WHERE status = 'review' ORDER BY create_dt")
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	articles := make([]Article, 0)
+
+	for rows.Next() {
+		singleEntry := Article{}
+		err = rows.Scan(&singleEntry.Id, &singleEntry.Title, &singleEntry.Content, &singleEntry.Description, &singleEntry.PrimaryKeyword,
+			&singleEntry.Concept, &singleEntry.IdeaId, &singleEntry.Version, &singleEntry.Language, &singleEntry.PersonaFlags, &singleEntry.FactCheckRisk,
+			&singleEntry.PublishStatus, &singleEntry.Reviewer, &singleEntry.ReviewComment, &singleEntry.CreateDate, &singleEntry.UpdateDate)
+
+		if err != nil {
+			return nil, err
+		}
+
+		articles = append(articles, singleEntry)
+	}
+
+	err = rows.Err()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return articles, err
+}
+
+// GetArticleReviewImage returns the base64 image held for an article until it is approved
+func GetArticleReviewImage(id int) (string, error) {
+	var reviewImg string
+	err := DB.QueryRow("SELECT review_img from articles WHERE id = ?", id).Scan(&reviewImg)
+	return reviewImg, err
+}
+
+func SetArticleReviewImage(id int, reviewImg string) (bool, error) {
+
+	tx, err := DB.Begin()
+	if err != nil {
+		return false, err
+	}
+
+	stmt, err := tx.Prepare("UPDATE articles SET review_img = ? WHERE id = ?")
+
+	if err != nil {
+		return false, err
+	}
+
+	defer stmt.Close()
+
+	_, err = stmt.Exec(reviewImg, id)
+
+	if err != nil {
+		return false, err
+	}
+
+	tx.Commit()
+
+	return true, nil
+}
@@ -0,0 +1,103 @@
+package models
+
+import (
+	_ "modernc.org/sqlite"
+)
+
+// ImageCandidate is one of the images held for an article until the featured image is picked
+type ImageCandidate struct {
+	Id          int    `json:"id"`
+	ArticleId   int    `json:"article_id"`
+	ImageData   string `json:"image_data"`
+	Source      string `json:"source"`
+	Credit      string `json:"credit"`
+	DownloadUrl string `json:"download_url"`
+	License     string `json:"license"`
+	LicenseUrl  string `json:"license_url"`
+	CreateDate  string `json:"create_dt"`
+}
+
+func GetImageCandidates(articleId int) ([]ImageCandidate, error) {
+
+	rows, err := DB.Query("SELECT id, article_id, image_data, source, credit, download_url, license, license_url, create_dt from image_candidates WHERE article_id = ? ORDER BY id", articleId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	candidates := make([]ImageCandidate, 0)
+
+	for rows.Next() {
+		singleCandidate := ImageCandidate{}
+		err = rows.Scan(&singleCandidate.Id, &singleCandidate.ArticleId, &singleCandidate.ImageData, &singleCandidate.Source, &singleCandidate.Credit, &singleCandidate.DownloadUrl, &singleCandidate.License, &singleCandidate.LicenseUrl, &singleCandidate.CreateDate)
+
+		if err != nil {
+			return nil, err
+		}
+
+		candidates = append(candidates, singleCandidate)
+	}
+
+	err = rows.Err()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return candidates, err
+}
+
+func AddImageCandidate(newCandidate ImageCandidate) (bool, error) {
+
+	tx, err := DB.Begin()
+	if err != nil {
+		return false, err
+	}
+
+	stmt, err := tx.Prepare("INSERT INTO image_candidates (article_id, image_data, source, credit, download_url, license, license_url, create_dt) VALUES (?, ?, ?, ?, ?, ?, ?, current_timestamp)")
+
+	if err != nil {
+		return false, err
+	}
+
+	defer stmt.Close()
+
+	_, err = stmt.Exec(newCandidate.ArticleId, newCandidate.ImageData, newCandidate.Source, newCandidate.Credit, newCandidate.DownloadUrl, newCandidate.License, newCandidate.LicenseUrl)
+
+	if err != nil {
+		return false, err
+	}
+
+	tx.Commit()
+
+	return true, nil
+}
+
+func DeleteImageCandidates(articleId int) (bool, error) {
+
+	tx, err := DB.Begin()
+
+	if err != nil {
+		return false, err
+	}
+
+	stmt, err := tx.Prepare("DELETE from image_candidates where article_id = ?")
+
+	if err != nil {
+		return false, err
+	}
+
+	defer stmt.Close()
+
+	_, err = stmt.Exec(articleId)
+
+	if err != nil {
+		return false, err
+	}
+
+	tx.Commit()
+
+	return true, nil
+}
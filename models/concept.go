@@ -0,0 +1,108 @@
+package models
+
+import (
+	"database/sql"
+	_ "modernc.org/sqlite"
+)
+
+// ConceptOverride holds the optional overrides applied to articles written from ideas sharing a concept
+type ConceptOverride struct {
+	Concept       string `json:"concept"`
+	SystemPrompt  string `json:"system_prompt"`
+	ArticlePrompt string `json:"article_prompt"`
+	ArticleLength int    `json:"article_length"`
+	ImgEngine     string `json:"img_engine"`
+	ImgMode       string `json:"img_mode"`
+	PublishStatus string `json:"publish_status"`
+	WpCategory    int    `json:"wp_category"`
+	CreateDate    string `json:"create_dt"`
+	UpdateDate    string `json:"update_dt"`
+}
+
+// GetConceptOverrides returns an entry for every known concept, including those without overrides
+func GetConceptOverrides() ([]ConceptOverride, error) {
+
+	rows, err := DB.Query("SELECT c.concept, coalesce(o.system_prompt, ''), coalesce(o.article_prompt, ''), coalesce(o.article_length, 0), " +
+		"coalesce(o.img_engine, ''), coalesce(o.img_mode, ''), coalesce(o.publish_status, ''), coalesce(o.wp_category, 0), coalesce(o.create_dt, ''), coalesce(o.update_dt, '') " +
+		"FROM (SELECT DISTINCT idea_concept AS concept FROM idea WHERE idea_concept != '' UNION SELECT concept FROM concept_overrides) c " +
+		"LEFT JOIN concept_overrides o ON o.concept = c.concept ORDER BY c.concept")
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	overrides := make([]ConceptOverride, 0)
+
+	for rows.Next() {
+		singleEntry := ConceptOverride{}
+		err = rows.Scan(&singleEntry.Concept, &singleEntry.SystemPrompt, &singleEntry.ArticlePrompt, &singleEntry.ArticleLength,
+			&singleEntry.ImgEngine, &singleEntry.ImgMode, &singleEntry.PublishStatus, &singleEntry.WpCategory, &singleEntry.CreateDate, &singleEntry.UpdateDate)
+
+		if err != nil {
+			return nil, err
+		}
+
+		overrides = append(overrides, singleEntry)
+	}
+
+	err = rows.Err()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return overrides, err
+}
+
+func GetConceptOverride(concept string) (ConceptOverride, error) {
+
+	stmt, err := DB.Prepare("SELECT concept, system_prompt, article_prompt, article_length, img_engine, img_mode, publish_status, wp_category, create_dt, update_dt from concept_overrides WHERE concept = ?")
+
+	if err != nil {
+		return ConceptOverride{}, err
+	}
+
+	override := ConceptOverride{}
+
+	sqlErr := stmt.QueryRow(concept).Scan(&override.Concept, &override.SystemPrompt, &override.ArticlePrompt, &override.ArticleLength,
+		&override.ImgEngine, &override.ImgMode, &override.PublishStatus, &override.WpCategory, &override.CreateDate, &override.UpdateDate)
+
+	if sqlErr != nil {
+		if sqlErr == sql.ErrNoRows {
+			return ConceptOverride{Concept: concept}, nil
+		}
+		return ConceptOverride{}, sqlErr
+	}
+	return override, nil
+}
+
+func UpsertConceptOverride(override ConceptOverride) (bool, error) {
+
+	tx, err := DB.Begin()
+	if err != nil {
+		return false, err
+	}
+
+	stmt, err := tx.Prepare("INSERT INTO concept_overrides (concept, system_prompt, article_prompt, article_length, img_engine, img_mode, publish_status, wp_category, create_dt, update_dt) " +
+		"VALUES (?, ?, ?, ?, ?, ?, ?, ?, current_timestamp, current_timestamp) " +
+		"ON CONFLICT(concept) DO UPDATE SET system_prompt = ?, article_prompt = ?, article_length = ?, img_engine = ?, img_mode = ?, publish_status = ?, wp_category = ?, update_dt = current_timestamp")
+
+	if err != nil {
+		return false, err
+	}
+
+	defer stmt.Close()
+
+	_, err = stmt.Exec(override.Concept, override.SystemPrompt, override.ArticlePrompt, override.ArticleLength, override.ImgEngine, override.ImgMode, override.PublishStatus, override.WpCategory,
+		override.SystemPrompt, override.ArticlePrompt, override.ArticleLength, override.ImgEngine, override.ImgMode, override.PublishStatus, override.WpCategory)
+
+	if err != nil {
+		return false, err
+	}
+
+	tx.Commit()
+
+	return true, nil
+}
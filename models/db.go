@@ -13,7 +13,7 @@ import (
 )
 
 var DB *sql.DB
-var targetVersion = 6
+var targetVersion = 32
 
 func ConnectDatabase(dbName string) error {
 	db, err := sql.Open("sqlite", dbName)
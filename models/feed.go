@@ -0,0 +1,241 @@
+package models
+
+import (
+	"database/sql"
+	_ "modernc.org/sqlite"
+)
+
+// Feed is an RSS or Atom feed watched for ideas, Rewrite has the AI turn each entry into an original angle
+type Feed struct {
+	Id          int    `json:"id"`
+	Name        string `json:"name"`
+	Url         string `json:"url"`
+	Rewrite     bool   `json:"rewrite"`
+	Language    string `json:"language"`
+	Enabled     bool   `json:"enabled"`
+	LastChecked string `json:"last_checked"`
+	LastError   string `json:"last_error"`
+	CreateDate  string `json:"create_dt"`
+	UpdateDate  string `json:"update_dt"`
+}
+
+// FeedItem is a feed entry that has been seen, IdeaId is 0 when it was skipped rather than made into an idea
+type FeedItem struct {
+	Id         int    `json:"id"`
+	FeedId     int    `json:"feed_id"`
+	Guid       string `json:"guid"`
+	Title      string `json:"title"`
+	Link       string `json:"link"`
+	IdeaId     int    `json:"idea_id"`
+	CreateDate string `json:"create_dt"`
+}
+
+func GetFeeds() ([]Feed, error) {
+
+	rows, err := DB.Query("SELECT id, name, url, rewrite, language, enabled, last_checked, last_error, create_dt, update_dt from feeds ORDER BY name")
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	feeds := make([]Feed, 0)
+
+	for rows.Next() {
+		singleFeed := Feed{}
+		err = rows.Scan(&singleFeed.Id, &singleFeed.Name, &singleFeed.Url, &singleFeed.Rewrite, &singleFeed.Language, &singleFeed.Enabled,
+			&singleFeed.LastChecked, &singleFeed.LastError, &singleFeed.CreateDate, &singleFeed.UpdateDate)
+
+		if err != nil {
+			return nil, err
+		}
+
+		feeds = append(feeds, singleFeed)
+	}
+
+	err = rows.Err()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return feeds, err
+}
+
+func GetFeedById(id int) (Feed, error) {
+
+	stmt, err := DB.Prepare("SELECT id, name, url, rewrite, language, enabled, last_checked, last_error, create_dt, update_dt from feeds WHERE id = ?")
+
+	if err != nil {
+		return Feed{}, err
+	}
+
+	feed := Feed{}
+
+	sqlErr := stmt.QueryRow(id).Scan(&feed.Id, &feed.Name, &feed.Url, &feed.Rewrite, &feed.Language, &feed.Enabled,
+		&feed.LastChecked, &feed.LastError, &feed.CreateDate, &feed.UpdateDate)
+
+	if sqlErr != nil {
+		if sqlErr == sql.ErrNoRows {
+			return Feed{}, nil
+		}
+		return Feed{}, sqlErr
+	}
+	return feed, nil
+}
+
+func AddFeed(newFeed Feed) (bool, error) {
+
+	tx, err := DB.Begin()
+	if err != nil {
+		return false, err
+	}
+
+	stmt, err := tx.Prepare("INSERT INTO feeds (name, url, rewrite, language, enabled, create_dt, update_dt) VALUES (?, ?, ?, ?, ?, current_timestamp, current_timestamp)")
+
+	if err != nil {
+		return false, err
+	}
+
+	defer stmt.Close()
+
+	_, err = stmt.Exec(newFeed.Name, newFeed.Url, newFeed.Rewrite, newFeed.Language, newFeed.Enabled)
+
+	if err != nil {
+		return false, err
+	}
+
+	tx.Commit()
+
+	return true, nil
+}
+
+func UpdateFeed(ourFeed Feed) (bool, error) {
+
+	tx, err := DB.Begin()
+	if err != nil {
+		return false, err
+	}
+
+	stmt, err := tx.Prepare("UPDATE feeds SET name = ?, url = ?, rewrite = ?, language = ?, enabled = ?, update_dt = current_timestamp WHERE id = ?")
+
+	if err != nil {
+		return false, err
+	}
+
+	defer stmt.Close()
+
+	_, err = stmt.Exec(ourFeed.Name, ourFeed.Url, ourFeed.Rewrite, ourFeed.Language, ourFeed.Enabled, ourFeed.Id)
+
+	if err != nil {
+		return false, err
+	}
+
+	tx.Commit()
+
+	return true, nil
+}
+
+// UpdateFeedChecked records when a feed was last checked and the error it failed with, empty when it worked
+func UpdateFeedChecked(feedId int, lastError string) error {
+	_, err := DB.Exec("UPDATE feeds SET last_checked = current_timestamp, last_error = ? WHERE id = ?", lastError, feedId)
+	return err
+}
+
+// DeleteFeed removes a feed along with its seen entries, the ideas made from them are kept
+func DeleteFeed(feedId int) (bool, error) {
+
+	tx, err := DB.Begin()
+
+	if err != nil {
+		return false, err
+	}
+
+	_, err = tx.Exec("DELETE from feed_items where feed_id = ?", feedId)
+
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	_, err = tx.Exec("DELETE from feeds where id = ?", feedId)
+
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	tx.Commit()
+
+	return true, nil
+}
+
+// GetFeedItems returns the latest entries seen on a feed first
+func GetFeedItems(feedId int, limit int) ([]FeedItem, error) {
+
+	rows, err := DB.Query("SELECT id, feed_id, guid, title, link, idea_id, create_dt from feed_items WHERE feed_id = ? ORDER BY id DESC LIMIT ?", feedId, limit)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	items := make([]FeedItem, 0)
+
+	for rows.Next() {
+		singleItem := FeedItem{}
+		err = rows.Scan(&singleItem.Id, &singleItem.FeedId, &singleItem.Guid, &singleItem.Title, &singleItem.Link, &singleItem.IdeaId, &singleItem.CreateDate)
+
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, singleItem)
+	}
+
+	err = rows.Err()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return items, err
+}
+
+// HasFeedItem reports whether an entry was already seen on the feed, so it only becomes an idea once
+func HasFeedItem(feedId int, guid string) (bool, error) {
+	var count int
+	err := DB.QueryRow("SELECT count(*) from feed_items WHERE feed_id = ? AND guid = ?", feedId, guid).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func AddFeedItem(newItem FeedItem) (bool, error) {
+
+	tx, err := DB.Begin()
+	if err != nil {
+		return false, err
+	}
+
+	stmt, err := tx.Prepare("INSERT INTO feed_items (feed_id, guid, title, link, idea_id, create_dt) VALUES (?, ?, ?, ?, ?, current_timestamp)")
+
+	if err != nil {
+		return false, err
+	}
+
+	defer stmt.Close()
+
+	_, err = stmt.Exec(newItem.FeedId, newItem.Guid, newItem.Title, newItem.Link, newItem.IdeaId)
+
+	if err != nil {
+		return false, err
+	}
+
+	tx.Commit()
+
+	return true, nil
+}
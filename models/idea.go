@@ -2,7 +2,10 @@ package models
 
 import (
 	"database/sql"
+	"errors"
 	_ "modernc.org/sqlite"
+	"strconv"
+	"strings"
 )
 
 type Idea struct {
@@ -11,22 +14,158 @@ type Idea struct {
 	Status      string `json:"status"`
 	IdeaConcept string `json:"idea_concept"`
 	SeriesId    int    `json:"series_id"`
+	Language    string `json:"language"`
+	// Priority runs from 0 to 10, higher is written sooner by the priority and weighted strategies
+	Priority int    `json:"priority"`
+	Tags     string `json:"tags"`
+	// Keyword is used as the article's primary keyword instead of asking the AI for one
+	Keyword string `json:"keyword"`
+	// NotBefore is a YYYY-MM-DD date the idea isn't auto-posted before
+	NotBefore string `json:"not_before"`
+	// SeriesOrder places the idea among the parts of its series, the lowest is part 1
+	SeriesOrder int    `json:"series_order"`
 	CreateDate  string `json:"create_dt"`
 	UpdateDate  string `json:"update_dt"`
 }
 
-func GetRandomIdea() Idea {
-	var idea Idea
-	err := DB.QueryRow("SELECT id, idea_text, status, idea_concept, series_id, create_dt, update_dt from idea WHERE status = 'NEW' ORDER BY RANDOM() LIMIT 1").Scan(&idea.Id, &idea.IdeaText, &idea.Status, &idea.IdeaConcept, &idea.SeriesId, &idea.CreateDate, &idea.UpdateDate)
+// The states of an idea's lifecycle
+const (
+	IdeaNew        = "NEW"
+	IdeaApproved   = "APPROVED"
+	IdeaScheduled  = "SCHEDULED"
+	IdeaInProgress = "IN-PROGRESS"
+	IdeaWritten    = "WRITTEN"
+	IdeaPublished  = "PUBLISHED"
+	IdeaRejected   = "REJECTED"
+	IdeaArchived   = "ARCHIVED"
+)
+
+// IdeaStatuses lists the states in lifecycle order
+var IdeaStatuses = []string{IdeaNew, IdeaApproved, IdeaScheduled, IdeaInProgress, IdeaWritten, IdeaPublished, IdeaRejected, IdeaArchived}
+
+// IdeaTransitions maps each state to the states an idea can move to from it. IN-PROGRESS is entered while an
+// article is written and held for an image or review, and left for WRITTEN when it is posted as a draft,
+// PUBLISHED when it is posted live, or back to where it was when writing fails or the article is rejected.
+var IdeaTransitions = map[string][]string{
+	IdeaNew:        {IdeaApproved, IdeaScheduled, IdeaInProgress, IdeaRejected, IdeaArchived},
+	IdeaApproved:   {IdeaNew, IdeaScheduled, IdeaInProgress, IdeaRejected, IdeaArchived},
+	IdeaScheduled:  {IdeaApproved, IdeaInProgress, IdeaRejected, IdeaArchived},
+	IdeaInProgress: {IdeaNew, IdeaApproved, IdeaScheduled, IdeaWritten, IdeaPublished, IdeaRejected},
+	IdeaWritten:    {IdeaInProgress, IdeaPublished, IdeaArchived},
+	IdeaPublished:  {IdeaInProgress, IdeaArchived},
+	IdeaRejected:   {IdeaNew, IdeaArchived},
+	IdeaArchived:   {IdeaNew},
+}
+
+// IdeaOpenStatuses are the states of ideas still waiting to be written or being written
+var IdeaOpenStatuses = []string{IdeaNew, IdeaApproved, IdeaScheduled, IdeaInProgress}
+
+// CanChangeIdeaStatus reports whether an idea can move from one state to the other
+func CanChangeIdeaStatus(from string, to string) bool {
+	for _, next := range IdeaTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// IdeaDone reports whether an idea is finished with, written, published or set aside
+func IdeaDone(status string) bool {
+	return status == IdeaWritten || status == IdeaPublished || status == IdeaRejected || status == IdeaArchived
+}
+
+// statusIn builds the placeholders and arguments of an IN list of statuses
+func statusIn(statuses []string) (string, []interface{}) {
+	args := make([]interface{}, len(statuses))
+	for i, status := range statuses {
+		args[i] = status
+	}
+	return "(" + strings.TrimSuffix(strings.Repeat("?,", len(statuses)), ",") + ")", args
+}
+
+// TagList splits the comma separated tags
+func (i Idea) TagList() []string {
+	tags := make([]string, 0)
+	for _, tag := range strings.Split(i.Tags, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// GetAutoPostIdeas returns the ideas in one of the statuses whose not before date has passed, oldest first,
+// for auto-post to choose from
+func GetAutoPostIdeas(statuses []string) ([]Idea, error) {
+
+	in, args := statusIn(statuses)
+	rows, err := DB.Query("SELECT id, idea_text, status, idea_concept, series_id, language, priority, tags, keyword, not_before, series_order, create_dt, update_dt from idea "+
+		"WHERE status IN "+in+" AND (not_before = '' OR not_before <= date('now')) ORDER BY id", args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	idea := make([]Idea, 0)
+
+	for rows.Next() {
+		singleIdea := Idea{}
+		err = rows.Scan(&singleIdea.Id, &singleIdea.IdeaText, &singleIdea.Status, &singleIdea.IdeaConcept, &singleIdea.SeriesId, &singleIdea.Language, &singleIdea.Priority, &singleIdea.Tags, &singleIdea.Keyword, &singleIdea.NotBefore, &singleIdea.SeriesOrder, &singleIdea.CreateDate, &singleIdea.UpdateDate)
+
+		if err != nil {
+			return nil, err
+		}
+
+		idea = append(idea, singleIdea)
+	}
+
+	err = rows.Err()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return idea, err
+}
+
+// GetConceptsLastPosted returns when an article was last written for each concept that has one
+func GetConceptsLastPosted() (map[string]string, error) {
+	return lastPosted("SELECT concept, max(create_dt) from articles WHERE concept != '' GROUP BY concept")
+}
+
+// GetSeriesLastPosted returns when an article was last written from each series that has one, keyed by series id
+func GetSeriesLastPosted() (map[string]string, error) {
+	return lastPosted("SELECT i.series_id, max(a.create_dt) from articles a JOIN idea i ON i.id = a.idea_id WHERE i.series_id > 0 GROUP BY i.series_id")
+}
+
+func lastPosted(query string) (map[string]string, error) {
+	rows, err := DB.Query(query)
 	if err != nil {
-		return Idea{}
+		return nil, err
+	}
+	defer rows.Close()
+
+	posted := map[string]string{}
+
+	for rows.Next() {
+		var key, date string
+		if err = rows.Scan(&key, &date); err != nil {
+			return nil, err
+		}
+		posted[key] = date
 	}
-	return idea
+
+	return posted, rows.Err()
 }
 
+// GetOpenIdeaCount counts the ideas outside a series still waiting to be written or being written
 func GetOpenIdeaCount() int {
 	var count int
-	err := DB.QueryRow("SELECT count(*) from idea WHERE status = 'NEW' and series_id = 0").Scan(&count)
+	in, args := statusIn(IdeaOpenStatuses)
+	err := DB.QueryRow("SELECT count(*) from idea WHERE status IN "+in+" and series_id = 0", args...).Scan(&count)
 	if err != nil {
 		return 0
 	}
@@ -35,7 +174,7 @@ func GetOpenIdeaCount() int {
 
 func GetIdeas() ([]Idea, error) {
 
-	rows, err := DB.Query("SELECT id, idea_text, status, idea_concept, series_id, create_dt, update_dt from idea ")
+	rows, err := DB.Query("SELECT id, idea_text, status, idea_concept, series_id, language, priority, tags, keyword, not_before, series_order, create_dt, update_dt from idea ")
 
 	if err != nil {
 		return nil, err
@@ -47,7 +186,7 @@ func GetIdeas() ([]Idea, error) {
 
 	for rows.Next() {
 		singleIdea := Idea{}
-		err = rows.Scan(&singleIdea.Id, &singleIdea.IdeaText, &singleIdea.Status, &singleIdea.IdeaConcept, &singleIdea.SeriesId, &singleIdea.CreateDate, &singleIdea.UpdateDate)
+		err = rows.Scan(&singleIdea.Id, &singleIdea.IdeaText, &singleIdea.Status, &singleIdea.IdeaConcept, &singleIdea.SeriesId, &singleIdea.Language, &singleIdea.Priority, &singleIdea.Tags, &singleIdea.Keyword, &singleIdea.NotBefore, &singleIdea.SeriesOrder, &singleIdea.CreateDate, &singleIdea.UpdateDate)
 
 		if err != nil {
 			return nil, err
@@ -65,9 +204,11 @@ func GetIdeas() ([]Idea, error) {
 	return idea, err
 }
 
+// GetOpenIdeas returns the ideas outside a series that are waiting to be written or being written
 func GetOpenIdeas() ([]Idea, error) {
 
-	rows, err := DB.Query("SELECT id, idea_text, status, idea_concept, series_id, create_dt, update_dt from idea WHERE status = 'NEW' and series_id = 0")
+	in, args := statusIn(IdeaOpenStatuses)
+	rows, err := DB.Query("SELECT id, idea_text, status, idea_concept, series_id, language, priority, tags, keyword, not_before, series_order, create_dt, update_dt from idea WHERE status IN "+in+" and series_id = 0", args...)
 
 	if err != nil {
 		return nil, err
@@ -79,7 +220,40 @@ func GetOpenIdeas() ([]Idea, error) {
 
 	for rows.Next() {
 		singleIdea := Idea{}
-		err = rows.Scan(&singleIdea.Id, &singleIdea.IdeaText, &singleIdea.Status, &singleIdea.IdeaConcept, &singleIdea.SeriesId, &singleIdea.CreateDate, &singleIdea.UpdateDate)
+		err = rows.Scan(&singleIdea.Id, &singleIdea.IdeaText, &singleIdea.Status, &singleIdea.IdeaConcept, &singleIdea.SeriesId, &singleIdea.Language, &singleIdea.Priority, &singleIdea.Tags, &singleIdea.Keyword, &singleIdea.NotBefore, &singleIdea.SeriesOrder, &singleIdea.CreateDate, &singleIdea.UpdateDate)
+
+		if err != nil {
+			return nil, err
+		}
+
+		idea = append(idea, singleIdea)
+	}
+
+	err = rows.Err()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return idea, err
+}
+
+// GetIdeasByStatus returns every idea in a status, series ideas included
+func GetIdeasByStatus(status string) ([]Idea, error) {
+
+	rows, err := DB.Query("SELECT id, idea_text, status, idea_concept, series_id, language, priority, tags, keyword, not_before, series_order, create_dt, update_dt from idea WHERE status = ? ORDER BY update_dt DESC", status)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	idea := make([]Idea, 0)
+
+	for rows.Next() {
+		singleIdea := Idea{}
+		err = rows.Scan(&singleIdea.Id, &singleIdea.IdeaText, &singleIdea.Status, &singleIdea.IdeaConcept, &singleIdea.SeriesId, &singleIdea.Language, &singleIdea.Priority, &singleIdea.Tags, &singleIdea.Keyword, &singleIdea.NotBefore, &singleIdea.SeriesOrder, &singleIdea.CreateDate, &singleIdea.UpdateDate)
 
 		if err != nil {
 			return nil, err
@@ -98,12 +272,13 @@ func GetOpenIdeas() ([]Idea, error) {
 }
 
 func GetOpenSeriesIdeas(id string) ([]Idea, error) {
-	stmt, err := DB.Prepare("SELECT id, idea_text, status, idea_concept, series_id, create_dt, update_dt from idea WHERE status = 'NEW' and series_id = ?")
+	in, args := statusIn(IdeaOpenStatuses)
+	stmt, err := DB.Prepare("SELECT id, idea_text, status, idea_concept, series_id, language, priority, tags, keyword, not_before, series_order, create_dt, update_dt from idea WHERE status IN " + in + " and series_id = ? ORDER BY series_order, id")
 	if err != nil {
 		return nil, err
 	}
 
-	rows, err := stmt.Query(id)
+	rows, err := stmt.Query(append(args, id)...)
 	if err != nil {
 		return nil, err
 	}
@@ -113,7 +288,7 @@ func GetOpenSeriesIdeas(id string) ([]Idea, error) {
 
 	for rows.Next() {
 		singleIdea := Idea{}
-		err = rows.Scan(&singleIdea.Id, &singleIdea.IdeaText, &singleIdea.Status, &singleIdea.IdeaConcept, &singleIdea.SeriesId, &singleIdea.CreateDate, &singleIdea.UpdateDate)
+		err = rows.Scan(&singleIdea.Id, &singleIdea.IdeaText, &singleIdea.Status, &singleIdea.IdeaConcept, &singleIdea.SeriesId, &singleIdea.Language, &singleIdea.Priority, &singleIdea.Tags, &singleIdea.Keyword, &singleIdea.NotBefore, &singleIdea.SeriesOrder, &singleIdea.CreateDate, &singleIdea.UpdateDate)
 
 		if err != nil {
 			return nil, err
@@ -161,7 +336,7 @@ func GetIdeaConcepts() ([]string, error) {
 }
 
 func GetIdeasByConcept(concept string) ([]Idea, error) {
-	stmt, err := DB.Prepare("SELECT id, idea_text, status, idea_concept, series_id, create_dt, update_dt from idea WHERE idea_concept = ?")
+	stmt, err := DB.Prepare("SELECT id, idea_text, status, idea_concept, series_id, language, priority, tags, keyword, not_before, series_order, create_dt, update_dt from idea WHERE idea_concept = ?")
 	if err != nil {
 		return nil, err
 	}
@@ -176,7 +351,7 @@ func GetIdeasByConcept(concept string) ([]Idea, error) {
 
 	for rows.Next() {
 		singleIdea := Idea{}
-		err = rows.Scan(&singleIdea.Id, &singleIdea.IdeaText, &singleIdea.Status, &singleIdea.IdeaConcept, &singleIdea.SeriesId, &singleIdea.CreateDate, &singleIdea.UpdateDate)
+		err = rows.Scan(&singleIdea.Id, &singleIdea.IdeaText, &singleIdea.Status, &singleIdea.IdeaConcept, &singleIdea.SeriesId, &singleIdea.Language, &singleIdea.Priority, &singleIdea.Tags, &singleIdea.Keyword, &singleIdea.NotBefore, &singleIdea.SeriesOrder, &singleIdea.CreateDate, &singleIdea.UpdateDate)
 
 		if err != nil {
 			return nil, err
@@ -195,7 +370,7 @@ func GetIdeasByConcept(concept string) ([]Idea, error) {
 }
 
 func GetSeriesIdeas(id string) ([]Idea, error) {
-	stmt, err := DB.Prepare("SELECT id, idea_text, status, idea_concept, series_id, create_dt, update_dt from idea WHERE series_id = ?")
+	stmt, err := DB.Prepare("SELECT id, idea_text, status, idea_concept, series_id, language, priority, tags, keyword, not_before, series_order, create_dt, update_dt from idea WHERE series_id = ? ORDER BY series_order, id")
 	if err != nil {
 		return nil, err
 	}
@@ -210,7 +385,7 @@ func GetSeriesIdeas(id string) ([]Idea, error) {
 
 	for rows.Next() {
 		singleIdea := Idea{}
-		err = rows.Scan(&singleIdea.Id, &singleIdea.IdeaText, &singleIdea.Status, &singleIdea.IdeaConcept, &singleIdea.SeriesId, &singleIdea.CreateDate, &singleIdea.UpdateDate)
+		err = rows.Scan(&singleIdea.Id, &singleIdea.IdeaText, &singleIdea.Status, &singleIdea.IdeaConcept, &singleIdea.SeriesId, &singleIdea.Language, &singleIdea.Priority, &singleIdea.Tags, &singleIdea.Keyword, &singleIdea.NotBefore, &singleIdea.SeriesOrder, &singleIdea.CreateDate, &singleIdea.UpdateDate)
 
 		if err != nil {
 			return nil, err
@@ -230,7 +405,7 @@ func GetSeriesIdeas(id string) ([]Idea, error) {
 
 func GetIdeaById(id string) (Idea, error) {
 
-	stmt, err := DB.Prepare("SELECT id, idea_text, status, idea_concept, series_id, create_dt, update_dt from idea WHERE id = ?")
+	stmt, err := DB.Prepare("SELECT id, idea_text, status, idea_concept, series_id, language, priority, tags, keyword, not_before, series_order, create_dt, update_dt from idea WHERE id = ?")
 
 	if err != nil {
 		return Idea{}, err
@@ -238,7 +413,7 @@ func GetIdeaById(id string) (Idea, error) {
 
 	idea := Idea{}
 
-	sqlErr := stmt.QueryRow(id).Scan(&idea.Id, &idea.IdeaText, &idea.Status, &idea.IdeaConcept, &idea.SeriesId, &idea.CreateDate, &idea.UpdateDate)
+	sqlErr := stmt.QueryRow(id).Scan(&idea.Id, &idea.IdeaText, &idea.Status, &idea.IdeaConcept, &idea.SeriesId, &idea.Language, &idea.Priority, &idea.Tags, &idea.Keyword, &idea.NotBefore, &idea.SeriesOrder, &idea.CreateDate, &idea.UpdateDate)
 
 	if sqlErr != nil {
 		if sqlErr == sql.ErrNoRows {
@@ -249,58 +424,163 @@ func GetIdeaById(id string) (Idea, error) {
 	return idea, nil
 }
 
-func AddIdea(newIdea Idea) (bool, error) {
+// AddIdea inserts a new idea, returning its id. Every idea starts out NEW, whatever status it was given, and the
+// start of its history records who created it.
+func AddIdea(newIdea Idea, createdBy string) (int64, error) {
+
+	newIdea.Status = IdeaNew
 
 	tx, err := DB.Begin()
 	if err != nil {
-		return false, err
+		return 0, err
 	}
 
-	stmt, err := tx.Prepare("INSERT INTO idea (idea_text, status, idea_concept, series_id, create_dt, update_dt) VALUES (?,?,?,?, current_timestamp, current_timestamp)")
+	//Series ideas are added as the last part of their series
+	stmt, err := tx.Prepare("INSERT INTO idea (idea_text, status, idea_concept, series_id, language, priority, tags, keyword, not_before, series_order, create_dt, update_dt) " +
+		"VALUES (?,?,?,?,?,?,?,?,?, (SELECT coalesce(max(series_order), 0) + 1 FROM idea WHERE series_id = ? AND series_id > 0), current_timestamp, current_timestamp)")
 
 	if err != nil {
-		return false, err
+		return 0, err
 	}
 
 	defer stmt.Close()
 
-	_, err = stmt.Exec(newIdea.IdeaText, newIdea.Status, newIdea.IdeaConcept, newIdea.SeriesId)
+	res, err := stmt.Exec(newIdea.IdeaText, newIdea.Status, newIdea.IdeaConcept, newIdea.SeriesId, newIdea.Language, newIdea.Priority, newIdea.Tags, newIdea.Keyword, newIdea.NotBefore, newIdea.SeriesId)
 
 	if err != nil {
-		return false, err
+		tx.Rollback()
+		return 0, err
 	}
 
-	tx.Commit()
+	id, err := res.LastInsertId()
 
-	return true, nil
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	_, err = tx.Exec("INSERT INTO idea_history (idea_id, from_status, to_status, changed_by, note, create_dt) VALUES (?, '', ?, ?, 'Created', current_timestamp)",
+		id, newIdea.Status, createdBy)
+
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return id, nil
 }
 
-func SetIdeaWritten(ideaId string) (bool, error) {
+// SetIdeaStatus moves an idea to a new status and records who moved it in its history, refusing moves the
+// lifecycle doesn't allow. Moving an idea to the status it already has changes nothing and returns false.
+func SetIdeaStatus(ideaId string, status string, changedBy string, note string) (bool, error) {
 
 	tx, err := DB.Begin()
 	if err != nil {
 		return false, err
 	}
 
-	stmt, err := tx.Prepare("UPDATE idea SET status = 'WRITTEN', update_dt = current_timestamp WHERE Id = ?")
+	changed, err := changeIdeaStatus(tx, ideaId, status, changedBy, note)
+
+	if err != nil || !changed {
+		tx.Rollback()
+		return false, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// changeIdeaStatus does the work of SetIdeaStatus in a transaction, leaving the commit or rollback to the caller
+func changeIdeaStatus(tx *sql.Tx, ideaId string, status string, changedBy string, note string) (bool, error) {
+
+	var current, notBefore string
+	err := tx.QueryRow("SELECT status, not_before from idea WHERE id = ?", ideaId).Scan(&current, &notBefore)
 
 	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, errors.New("Idea " + ideaId + " not found")
+		}
 		return false, err
 	}
 
-	defer stmt.Close()
+	if current == status {
+		return false, nil
+	}
 
-	_, err = stmt.Exec(ideaId)
+	if !CanChangeIdeaStatus(current, status) {
+		return false, errors.New("An idea can't go from " + current + " to " + status)
+	}
+
+	if status == IdeaScheduled && notBefore == "" {
+		return false, errors.New("Give the idea a not before date to schedule it")
+	}
+
+	_, err = tx.Exec("UPDATE idea SET status = ?, update_dt = current_timestamp WHERE id = ?", status, ideaId)
 
 	if err != nil {
 		return false, err
 	}
 
-	tx.Commit()
+	_, err = tx.Exec("INSERT INTO idea_history (idea_id, from_status, to_status, changed_by, note, create_dt) VALUES (?, ?, ?, ?, ?, current_timestamp)",
+		ideaId, current, status, changedBy, note)
+
+	if err != nil {
+		return false, err
+	}
 
 	return true, nil
 }
 
+// MoveSeriesIdea swaps an idea with the part before it in its series, or the part after it when up is false
+func MoveSeriesIdea(ideaId int, up bool) error {
+	idea, err := GetIdeaById(strconv.Itoa(ideaId))
+	if err != nil || idea.SeriesId == 0 {
+		return err
+	}
+	parts, err := GetSeriesIdeas(strconv.Itoa(idea.SeriesId))
+	if err != nil {
+		return err
+	}
+	for i, part := range parts {
+		if part.Id != ideaId {
+			continue
+		}
+		other := i + 1
+		if up {
+			other = i - 1
+		}
+		if other < 0 || other >= len(parts) {
+			return nil
+		}
+		//Renumber the whole series so ideas sharing an order from before parts were ordered get their own
+		parts[i], parts[other] = parts[other], parts[i]
+		break
+	}
+	tx, err := DB.Begin()
+	if err != nil {
+		return err
+	}
+	for i, part := range parts {
+		_, err = tx.Exec("UPDATE idea SET series_order = ? WHERE id = ?", i+1, part.Id)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// seriesOrderOnMove keeps an idea's place while its series stays the same, and makes it the last part of the
+// series it moves to, like AddIdea does. It takes the new series id twice.
+const seriesOrderOnMove = "CASE WHEN series_id = ? THEN series_order ELSE (SELECT coalesce(max(series_order), 0) + 1 FROM idea WHERE series_id = ? AND series_id > 0) END"
+
 func UpdateIdea(ourIdea Idea, id int) (bool, error) {
 
 	tx, err := DB.Begin()
@@ -308,7 +588,8 @@ func UpdateIdea(ourIdea Idea, id int) (bool, error) {
 		return false, err
 	}
 
-	stmt, err := tx.Prepare("UPDATE idea SET idea_text = ?, status = ?, idea_concept = ?, series_id = ?, update_dt = current_timestamp WHERE Id = ?")
+	//The status is left alone, it only changes through SetIdeaStatus
+	stmt, err := tx.Prepare("UPDATE idea SET idea_text = ?, idea_concept = ?, series_id = ?, series_order = " + seriesOrderOnMove + ", language = ?, priority = ?, tags = ?, keyword = ?, not_before = ?, update_dt = current_timestamp WHERE Id = ?")
 
 	if err != nil {
 		return false, err
@@ -316,7 +597,7 @@ func UpdateIdea(ourIdea Idea, id int) (bool, error) {
 
 	defer stmt.Close()
 
-	_, err = stmt.Exec(ourIdea.IdeaText, ourIdea.Status, ourIdea.IdeaConcept, ourIdea.SeriesId, ourIdea.Id)
+	_, err = stmt.Exec(ourIdea.IdeaText, ourIdea.IdeaConcept, ourIdea.SeriesId, ourIdea.SeriesId, ourIdea.SeriesId, ourIdea.Language, ourIdea.Priority, ourIdea.Tags, ourIdea.Keyword, ourIdea.NotBefore, ourIdea.Id)
 
 	if err != nil {
 		return false, err
@@ -327,6 +608,38 @@ func UpdateIdea(ourIdea Idea, id int) (bool, error) {
 	return true, nil
 }
 
+// UpdateIdeaAndStatus saves an idea and, when it has a status, moves it there through SetIdeaStatus's checks.
+// A refused move saves nothing.
+func UpdateIdeaAndStatus(ourIdea Idea, changedBy string) (bool, error) {
+
+	tx, err := DB.Begin()
+	if err != nil {
+		return false, err
+	}
+
+	_, err = tx.Exec("UPDATE idea SET idea_text = ?, idea_concept = ?, series_id = ?, series_order = "+seriesOrderOnMove+", language = ?, priority = ?, tags = ?, keyword = ?, not_before = ?, update_dt = current_timestamp WHERE Id = ?",
+		ourIdea.IdeaText, ourIdea.IdeaConcept, ourIdea.SeriesId, ourIdea.SeriesId, ourIdea.SeriesId, ourIdea.Language, ourIdea.Priority, ourIdea.Tags, ourIdea.Keyword, ourIdea.NotBefore, ourIdea.Id)
+
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	if ourIdea.Status != "" {
+		_, err = changeIdeaStatus(tx, strconv.Itoa(ourIdea.Id), ourIdea.Status, changedBy, "")
+		if err != nil {
+			tx.Rollback()
+			return false, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
 func DeleteIdea(ideaId int) (bool, error) {
 
 	tx, err := DB.Begin()
@@ -335,7 +648,14 @@ func DeleteIdea(ideaId int) (bool, error) {
 		return false, err
 	}
 
-	stmt, err := DB.Prepare("DELETE from idea where id = ?")
+	_, err = tx.Exec("DELETE from idea_history where idea_id = ?", ideaId)
+
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	stmt, err := tx.Prepare("DELETE from idea where id = ?")
 
 	if err != nil {
 		return false, err
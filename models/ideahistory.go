@@ -0,0 +1,50 @@
+package models
+
+import (
+	_ "modernc.org/sqlite"
+)
+
+// IdeaHistory is one move of an idea from one status to another
+type IdeaHistory struct {
+	Id         int    `json:"id"`
+	IdeaId     int    `json:"idea_id"`
+	FromStatus string `json:"from_status"`
+	ToStatus   string `json:"to_status"`
+	ChangedBy  string `json:"changed_by"`
+	Note       string `json:"note"`
+	CreateDate string `json:"create_dt"`
+}
+
+// GetIdeaHistory returns the status changes of an idea, newest first
+func GetIdeaHistory(ideaId int) ([]IdeaHistory, error) {
+
+	rows, err := DB.Query("SELECT id, idea_id, from_status, to_status, changed_by, note, create_dt from idea_history WHERE idea_id = ? ORDER BY id DESC", ideaId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	history := make([]IdeaHistory, 0)
+
+	for rows.Next() {
+		singleHistory := IdeaHistory{}
+		err = rows.Scan(&singleHistory.Id, &singleHistory.IdeaId, &singleHistory.FromStatus, &singleHistory.ToStatus,
+			&singleHistory.ChangedBy, &singleHistory.Note, &singleHistory.CreateDate)
+
+		if err != nil {
+			return nil, err
+		}
+
+		history = append(history, singleHistory)
+	}
+
+	err = rows.Err()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return history, err
+}
@@ -0,0 +1,159 @@
+package models
+
+import (
+	"database/sql"
+	_ "modernc.org/sqlite"
+)
+
+// Media is an image kept in the local library, the file itself lives under data/media
+type Media struct {
+	Id         int    `json:"id"`
+	FileName   string `json:"file_name"`
+	MimeType   string `json:"mime_type"`
+	Prompt     string `json:"prompt"`
+	Seed       int64  `json:"seed"`
+	Engine     string `json:"engine"`
+	SourceUrl  string `json:"source_url"`
+	Credit     string `json:"credit"`
+	License    string `json:"license"`
+	LicenseUrl string `json:"license_url"`
+	// GenInfo is the engine's generation parameters, enough to reproduce the image
+	GenInfo    string `json:"gen_info"`
+	ArticleId  int    `json:"article_id"`
+	CreateDate string `json:"create_dt"`
+	UpdateDate string `json:"update_dt"`
+}
+
+func GetMedia() ([]Media, error) {
+
+	rows, err := DB.Query("SELECT id, file_name, mime_type, prompt, seed, engine, source_url, credit, license, license_url, gen_info, article_id, create_dt, update_dt from media ORDER BY id DESC")
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	media := make([]Media, 0)
+
+	for rows.Next() {
+		singleMedia := Media{}
+		err = rows.Scan(&singleMedia.Id, &singleMedia.FileName, &singleMedia.MimeType, &singleMedia.Prompt, &singleMedia.Seed,
+			&singleMedia.Engine, &singleMedia.SourceUrl, &singleMedia.Credit, &singleMedia.License, &singleMedia.LicenseUrl, &singleMedia.GenInfo, &singleMedia.ArticleId, &singleMedia.CreateDate, &singleMedia.UpdateDate)
+
+		if err != nil {
+			return nil, err
+		}
+
+		media = append(media, singleMedia)
+	}
+
+	err = rows.Err()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return media, err
+}
+
+func GetMediaById(id int) (Media, error) {
+
+	stmt, err := DB.Prepare("SELECT id, file_name, mime_type, prompt, seed, engine, source_url, credit, license, license_url, gen_info, article_id, create_dt, update_dt from media WHERE id = ?")
+
+	if err != nil {
+		return Media{}, err
+	}
+
+	media := Media{}
+
+	sqlErr := stmt.QueryRow(id).Scan(&media.Id, &media.FileName, &media.MimeType, &media.Prompt, &media.Seed,
+		&media.Engine, &media.SourceUrl, &media.Credit, &media.License, &media.LicenseUrl, &media.GenInfo, &media.ArticleId, &media.CreateDate, &media.UpdateDate)
+
+	if sqlErr != nil {
+		if sqlErr == sql.ErrNoRows {
+			return Media{}, nil
+		}
+		return Media{}, sqlErr
+	}
+	return media, nil
+}
+
+func AddMedia(newMedia Media) (int64, error) {
+
+	tx, err := DB.Begin()
+	if err != nil {
+		return -1, err
+	}
+
+	stmt, err := tx.Prepare("INSERT INTO media (file_name, mime_type, prompt, seed, engine, source_url, credit, license, license_url, gen_info, article_id, create_dt, update_dt) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, current_timestamp, current_timestamp)")
+
+	if err != nil {
+		return -1, err
+	}
+
+	defer stmt.Close()
+
+	res, err := stmt.Exec(newMedia.FileName, newMedia.MimeType, newMedia.Prompt, newMedia.Seed, newMedia.Engine, newMedia.SourceUrl, newMedia.Credit, newMedia.License, newMedia.LicenseUrl, newMedia.GenInfo, newMedia.ArticleId)
+
+	if err != nil {
+		return -1, err
+	}
+
+	tx.Commit()
+
+	return res.LastInsertId()
+}
+
+func SetMediaArticle(mediaId int, articleId int) (bool, error) {
+
+	tx, err := DB.Begin()
+	if err != nil {
+		return false, err
+	}
+
+	stmt, err := tx.Prepare("UPDATE media SET article_id = ?, update_dt = current_timestamp WHERE id = ?")
+
+	if err != nil {
+		return false, err
+	}
+
+	defer stmt.Close()
+
+	_, err = stmt.Exec(articleId, mediaId)
+
+	if err != nil {
+		return false, err
+	}
+
+	tx.Commit()
+
+	return true, nil
+}
+
+func DeleteMedia(mediaId int) (bool, error) {
+
+	tx, err := DB.Begin()
+
+	if err != nil {
+		return false, err
+	}
+
+	stmt, err := tx.Prepare("DELETE from media where id = ?")
+
+	if err != nil {
+		return false, err
+	}
+
+	defer stmt.Close()
+
+	_, err = stmt.Exec(mediaId)
+
+	if err != nil {
+		return false, err
+	}
+
+	tx.Commit()
+
+	return true, nil
+}
@@ -0,0 +1,151 @@
+package models
+
+import (
+	"database/sql"
+	_ "modernc.org/sqlite"
+)
+
+type Persona struct {
+	Id            int    `json:"id"`
+	PersonaName   string `json:"persona_name"`
+	Voice         string `json:"voice"`
+	StyleRules    string `json:"style_rules"`
+	BannedPhrases string `json:"banned_phrases"`
+	Examples      string `json:"examples"`
+	CreateDate    string `json:"create_dt"`
+	UpdateDate    string `json:"update_dt"`
+}
+
+func GetPersonas() ([]Persona, error) {
+
+	rows, err := DB.Query("SELECT id, persona_name, voice, style_rules, banned_phrases, examples, create_dt, update_dt from personas ORDER BY persona_name")
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	personas := make([]Persona, 0)
+
+	for rows.Next() {
+		singlePersona := Persona{}
+		err = rows.Scan(&singlePersona.Id, &singlePersona.PersonaName, &singlePersona.Voice, &singlePersona.StyleRules,
+			&singlePersona.BannedPhrases, &singlePersona.Examples, &singlePersona.CreateDate, &singlePersona.UpdateDate)
+
+		if err != nil {
+			return nil, err
+		}
+
+		personas = append(personas, singlePersona)
+	}
+
+	err = rows.Err()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return personas, err
+}
+
+func GetPersonaById(id string) (Persona, error) {
+
+	stmt, err := DB.Prepare("SELECT id, persona_name, voice, style_rules, banned_phrases, examples, create_dt, update_dt from personas WHERE id = ?")
+
+	if err != nil {
+		return Persona{}, err
+	}
+
+	persona := Persona{}
+
+	sqlErr := stmt.QueryRow(id).Scan(&persona.Id, &persona.PersonaName, &persona.Voice, &persona.StyleRules,
+		&persona.BannedPhrases, &persona.Examples, &persona.CreateDate, &persona.UpdateDate)
+
+	if sqlErr != nil {
+		if sqlErr == sql.ErrNoRows {
+			return Persona{}, nil
+		}
+		return Persona{}, sqlErr
+	}
+	return persona, nil
+}
+
+func AddPersona(newPersona Persona) (bool, error) {
+
+	tx, err := DB.Begin()
+	if err != nil {
+		return false, err
+	}
+
+	stmt, err := tx.Prepare("INSERT INTO personas (persona_name, voice, style_rules, banned_phrases, examples, create_dt, update_dt) VALUES (?, ?, ?, ?, ?, current_timestamp, current_timestamp)")
+
+	if err != nil {
+		return false, err
+	}
+
+	defer stmt.Close()
+
+	_, err = stmt.Exec(newPersona.PersonaName, newPersona.Voice, newPersona.StyleRules, newPersona.BannedPhrases, newPersona.Examples)
+
+	if err != nil {
+		return false, err
+	}
+
+	tx.Commit()
+
+	return true, nil
+}
+
+func UpdatePersona(ourPersona Persona) (bool, error) {
+
+	tx, err := DB.Begin()
+	if err != nil {
+		return false, err
+	}
+
+	stmt, err := tx.Prepare("UPDATE personas SET persona_name = ?, voice = ?, style_rules = ?, banned_phrases = ?, examples = ?, update_dt = current_timestamp WHERE id = ?")
+
+	if err != nil {
+		return false, err
+	}
+
+	defer stmt.Close()
+
+	_, err = stmt.Exec(ourPersona.PersonaName, ourPersona.Voice, ourPersona.StyleRules, ourPersona.BannedPhrases, ourPersona.Examples, ourPersona.Id)
+
+	if err != nil {
+		return false, err
+	}
+
+	tx.Commit()
+
+	return true, nil
+}
+
+func DeletePersona(personaId int) (bool, error) {
+
+	tx, err := DB.Begin()
+
+	if err != nil {
+		return false, err
+	}
+
+	stmt, err := tx.Prepare("DELETE from personas where id = ?")
+
+	if err != nil {
+		return false, err
+	}
+
+	defer stmt.Close()
+
+	_, err = stmt.Exec(personaId)
+
+	if err != nil {
+		return false, err
+	}
+
+	tx.Commit()
+
+	return true, nil
+}
@@ -9,13 +9,31 @@ type Series struct {
 	Id           int    `json:"id"`
 	SeriesName   string `json:"series_name"`
 	SeriesPrompt string `json:"series_prompt"`
-	CreateDate   string `json:"create_dt"`
-	UpdateDate   string `json:"update_dt"`
+	// Optional overrides applied to articles written from this series
+	SystemPrompt  string `json:"system_prompt"`
+	ArticlePrompt string `json:"article_prompt"`
+	ArticleLength int    `json:"article_length"`
+	ImgEngine     string `json:"img_engine"`
+	ImgMode       string `json:"img_mode"`
+	PublishStatus string `json:"publish_status"`
+	WpCategory    int    `json:"wp_category"`
+	PersonaId     int    `json:"persona_id"`
+	Language      string `json:"language"`
+	// Stable Diffusion branding so every image in the series shares a look
+	SdModel    string `json:"sd_model"`
+	SdStyle    string `json:"sd_style"`
+	SdLora     string `json:"sd_lora"`
+	RefMediaId int    `json:"ref_media_id"`
+	// PartTitles adds "Part N of M" to each article's title, NavLinks adds links to the previous and next parts
+	PartTitles bool   `json:"part_titles"`
+	NavLinks   bool   `json:"nav_links"`
+	CreateDate string `json:"create_dt"`
+	UpdateDate string `json:"update_dt"`
 }
 
 func GetSeries() ([]Series, error) {
 
-	rows, err := DB.Query("SELECT id, series_name, series_prompt, create_dt, update_dt from series")
+	rows, err := DB.Query("SELECT id, series_name, series_prompt, system_prompt, article_prompt, article_length, img_engine, img_mode, publish_status, wp_category, persona_id, language, sd_model, sd_style, sd_lora, ref_media_id, part_titles, nav_links, create_dt, update_dt from series")
 
 	if err != nil {
 		return nil, err
@@ -27,7 +45,9 @@ func GetSeries() ([]Series, error) {
 
 	for rows.Next() {
 		singleSeries := Series{}
-		err = rows.Scan(&singleSeries.Id, &singleSeries.SeriesName, &singleSeries.SeriesPrompt, &singleSeries.CreateDate, &singleSeries.UpdateDate)
+		err = rows.Scan(&singleSeries.Id, &singleSeries.SeriesName, &singleSeries.SeriesPrompt, &singleSeries.SystemPrompt, &singleSeries.ArticlePrompt,
+			&singleSeries.ArticleLength, &singleSeries.ImgEngine, &singleSeries.ImgMode, &singleSeries.PublishStatus, &singleSeries.WpCategory, &singleSeries.PersonaId, &singleSeries.Language,
+			&singleSeries.SdModel, &singleSeries.SdStyle, &singleSeries.SdLora, &singleSeries.RefMediaId, &singleSeries.PartTitles, &singleSeries.NavLinks, &singleSeries.CreateDate, &singleSeries.UpdateDate)
 
 		if err != nil {
 			return nil, err
@@ -47,7 +67,7 @@ func GetSeries() ([]Series, error) {
 
 func GetSeriesById(id string) (Series, error) {
 
-	stmt, err := DB.Prepare("SELECT id, series_name, series_prompt, create_dt, update_dt from series WHERE id = ?")
+	stmt, err := DB.Prepare("SELECT id, series_name, series_prompt, system_prompt, article_prompt, article_length, img_engine, img_mode, publish_status, wp_category, persona_id, language, sd_model, sd_style, sd_lora, ref_media_id, part_titles, nav_links, create_dt, update_dt from series WHERE id = ?")
 
 	if err != nil {
 		return Series{}, err
@@ -55,7 +75,9 @@ func GetSeriesById(id string) (Series, error) {
 
 	series := Series{}
 
-	sqlErr := stmt.QueryRow(id).Scan(&series.Id, &series.SeriesName, &series.SeriesPrompt, &series.CreateDate, &series.UpdateDate)
+	sqlErr := stmt.QueryRow(id).Scan(&series.Id, &series.SeriesName, &series.SeriesPrompt, &series.SystemPrompt, &series.ArticlePrompt,
+		&series.ArticleLength, &series.ImgEngine, &series.ImgMode, &series.PublishStatus, &series.WpCategory, &series.PersonaId, &series.Language,
+		&series.SdModel, &series.SdStyle, &series.SdLora, &series.RefMediaId, &series.PartTitles, &series.NavLinks, &series.CreateDate, &series.UpdateDate)
 
 	if sqlErr != nil {
 		if sqlErr == sql.ErrNoRows {
@@ -73,7 +95,7 @@ func AddSeriesReturningId(newSeries Series) (int, error) {
 		return 0, err
 	}
 
-	stmt, err := tx.Prepare("INSERT INTO series (series_name, series_prompt, create_dt, update_dt) VALUES (?, ?, current_timestamp, current_timestamp) RETURNING id")
+	stmt, err := tx.Prepare("INSERT INTO series (series_name, series_prompt, system_prompt, article_prompt, article_length, img_engine, img_mode, publish_status, wp_category, persona_id, language, sd_model, sd_style, sd_lora, ref_media_id, part_titles, nav_links, create_dt, update_dt) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, current_timestamp, current_timestamp) RETURNING id")
 
 	if err != nil {
 		return 0, err
@@ -81,7 +103,9 @@ func AddSeriesReturningId(newSeries Series) (int, error) {
 
 	defer stmt.Close()
 
-	err = stmt.QueryRow(newSeries.SeriesName, newSeries.SeriesPrompt).Scan(&id)
+	err = stmt.QueryRow(newSeries.SeriesName, newSeries.SeriesPrompt, newSeries.SystemPrompt, newSeries.ArticlePrompt,
+		newSeries.ArticleLength, newSeries.ImgEngine, newSeries.ImgMode, newSeries.PublishStatus, newSeries.WpCategory, newSeries.PersonaId, newSeries.Language,
+		newSeries.SdModel, newSeries.SdStyle, newSeries.SdLora, newSeries.RefMediaId, newSeries.PartTitles, newSeries.NavLinks).Scan(&id)
 
 	if err != nil {
 		return 0, err
@@ -98,7 +122,7 @@ func AddSeries(newSeries Series) (bool, error) {
 		return false, err
 	}
 
-	stmt, err := tx.Prepare("INSERT INTO series (series_name, series_prompt, create_dt, update_dt) VALUES (?, ?, current_timestamp, current_timestamp)")
+	stmt, err := tx.Prepare("INSERT INTO series (series_name, series_prompt, system_prompt, article_prompt, article_length, img_engine, img_mode, publish_status, wp_category, persona_id, language, sd_model, sd_style, sd_lora, ref_media_id, part_titles, nav_links, create_dt, update_dt) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, current_timestamp, current_timestamp)")
 
 	if err != nil {
 		return false, err
@@ -106,7 +130,9 @@ func AddSeries(newSeries Series) (bool, error) {
 
 	defer stmt.Close()
 
-	_, err = stmt.Exec(newSeries.SeriesName, newSeries.SeriesPrompt)
+	_, err = stmt.Exec(newSeries.SeriesName, newSeries.SeriesPrompt, newSeries.SystemPrompt, newSeries.ArticlePrompt,
+		newSeries.ArticleLength, newSeries.ImgEngine, newSeries.ImgMode, newSeries.PublishStatus, newSeries.WpCategory, newSeries.PersonaId, newSeries.Language,
+		newSeries.SdModel, newSeries.SdStyle, newSeries.SdLora, newSeries.RefMediaId, newSeries.PartTitles, newSeries.NavLinks)
 
 	if err != nil {
 		return false, err
@@ -124,7 +150,8 @@ func UpdateSeries(ourSeries Series, id int) (bool, error) {
 		return false, err
 	}
 
-	stmt, err := tx.Prepare("UPDATE series SET series_name = ?, series_prompt = ?, update_dt = current_timestamp WHERE Id = ?")
+	stmt, err := tx.Prepare("UPDATE series SET series_name = ?, series_prompt = ?, system_prompt = ?, article_prompt = ?, article_length = ?, img_engine = ?, img_mode = ?, " +
+		"publish_status = ?, wp_category = ?, persona_id = ?, language = ?, sd_model = ?, sd_style = ?, sd_lora = ?, ref_media_id = ?, part_titles = ?, nav_links = ?, update_dt = current_timestamp WHERE Id = ?")
 
 	if err != nil {
 		return false, err
@@ -132,7 +159,9 @@ func UpdateSeries(ourSeries Series, id int) (bool, error) {
 
 	defer stmt.Close()
 
-	_, err = stmt.Exec(ourSeries.SeriesName, ourSeries.SeriesPrompt, ourSeries.Id)
+	_, err = stmt.Exec(ourSeries.SeriesName, ourSeries.SeriesPrompt, ourSeries.SystemPrompt, ourSeries.ArticlePrompt,
+		ourSeries.ArticleLength, ourSeries.ImgEngine, ourSeries.ImgMode, ourSeries.PublishStatus, ourSeries.WpCategory, ourSeries.PersonaId, ourSeries.Language,
+		ourSeries.SdModel, ourSeries.SdStyle, ourSeries.SdLora, ourSeries.RefMediaId, ourSeries.PartTitles, ourSeries.NavLinks, ourSeries.Id)
 
 	if err != nil {
 		return false, err
@@ -0,0 +1,86 @@
+package models
+
+import (
+	_ "modernc.org/sqlite"
+)
+
+// SocialPost records one attempt to share an article on a social channel, Status is posted or failed
+type SocialPost struct {
+	Id         int    `json:"id"`
+	ArticleId  int    `json:"article_id"`
+	Channel    string `json:"channel"`
+	Status     string `json:"status"`
+	RemoteId   string `json:"remote_id"`
+	RemoteUrl  string `json:"remote_url"`
+	PostText   string `json:"post_text"`
+	Error      string `json:"error"`
+	CreateDate string `json:"create_dt"`
+}
+
+func GetSocialPosts(articleId int) ([]SocialPost, error) {
+
+	rows, err := DB.Query("SELECT id, article_id, channel, status, remote_id, remote_url, post_text, error, create_dt from social_posts WHERE article_id = ? ORDER BY id DESC", articleId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	socialPosts := make([]SocialPost, 0)
+
+	for rows.Next() {
+		singlePost := SocialPost{}
+		err = rows.Scan(&singlePost.Id, &singlePost.ArticleId, &singlePost.Channel, &singlePost.Status, &singlePost.RemoteId, &singlePost.RemoteUrl, &singlePost.PostText, &singlePost.Error, &singlePost.CreateDate)
+
+		if err != nil {
+			return nil, err
+		}
+
+		socialPosts = append(socialPosts, singlePost)
+	}
+
+	err = rows.Err()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return socialPosts, err
+}
+
+// HasSocialPost reports whether the article was already shared on the channel, so it isn't posted there twice
+func HasSocialPost(articleId int, channel string) (bool, error) {
+	var count int
+	err := DB.QueryRow("SELECT count(*) from social_posts WHERE article_id = ? AND channel = ? AND status = 'posted'", articleId, channel).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func AddSocialPost(newPost SocialPost) (bool, error) {
+
+	tx, err := DB.Begin()
+	if err != nil {
+		return false, err
+	}
+
+	stmt, err := tx.Prepare("INSERT INTO social_posts (article_id, channel, status, remote_id, remote_url, post_text, error, create_dt) VALUES (?, ?, ?, ?, ?, ?, ?, current_timestamp)")
+
+	if err != nil {
+		return false, err
+	}
+
+	defer stmt.Close()
+
+	_, err = stmt.Exec(newPost.ArticleId, newPost.Channel, newPost.Status, newPost.RemoteId, newPost.RemoteUrl, newPost.PostText, newPost.Error)
+
+	if err != nil {
+		return false, err
+	}
+
+	tx.Commit()
+
+	return true, nil
+}
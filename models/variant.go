@@ -0,0 +1,259 @@
+package models
+
+import (
+	"database/sql"
+	_ "modernc.org/sqlite"
+)
+
+type PromptVariant struct {
+	Id            int    `json:"id"`
+	VariantName   string `json:"variant_name"`
+	SystemPrompt  string `json:"system_prompt"`
+	ArticlePrompt string `json:"article_prompt"`
+	Active        bool   `json:"active"`
+	CreateDate    string `json:"create_dt"`
+	UpdateDate    string `json:"update_dt"`
+}
+
+// VariantStats summarizes the outcomes of the articles written with a variant
+type VariantStats struct {
+	VariantId     int     `json:"variant_id"`
+	VariantName   string  `json:"variant_name"`
+	Active        bool    `json:"active"`
+	ArticleCount  int     `json:"article_count"`
+	AvgLength     float64 `json:"avg_length"`
+	Regenerated   int     `json:"regenerated"`
+	Rewritten     int     `json:"rewritten"`
+	Edited        int     `json:"edited"`
+	AvgViews      float64 `json:"avg_views"`
+	RegenRate     float64 `json:"regen_rate"`
+	RewriteRate   float64 `json:"rewrite_rate"`
+	EditRate      float64 `json:"edit_rate"`
+	ViewsReported bool    `json:"views_reported"`
+}
+
+func GetPromptVariants() ([]PromptVariant, error) {
+
+	rows, err := DB.Query("SELECT id, variant_name, system_prompt, article_prompt, active, create_dt, update_dt from prompt_variants")
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	variants := make([]PromptVariant, 0)
+
+	for rows.Next() {
+		singleVariant := PromptVariant{}
+		err = rows.Scan(&singleVariant.Id, &singleVariant.VariantName, &singleVariant.SystemPrompt, &singleVariant.ArticlePrompt, &singleVariant.Active, &singleVariant.CreateDate, &singleVariant.UpdateDate)
+
+		if err != nil {
+			return nil, err
+		}
+
+		variants = append(variants, singleVariant)
+	}
+
+	err = rows.Err()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return variants, err
+}
+
+func GetPromptVariantById(id string) (PromptVariant, error) {
+
+	stmt, err := DB.Prepare("SELECT id, variant_name, system_prompt, article_prompt, active, create_dt, update_dt from prompt_variants WHERE id = ?")
+
+	if err != nil {
+		return PromptVariant{}, err
+	}
+
+	variant := PromptVariant{}
+
+	sqlErr := stmt.QueryRow(id).Scan(&variant.Id, &variant.VariantName, &variant.SystemPrompt, &variant.ArticlePrompt, &variant.Active, &variant.CreateDate, &variant.UpdateDate)
+
+	if sqlErr != nil {
+		if sqlErr == sql.ErrNoRows {
+			return PromptVariant{}, nil
+		}
+		return PromptVariant{}, sqlErr
+	}
+	return variant, nil
+}
+
+// GetRandomActiveVariant assigns an experiment variant, returning an empty variant when none are active
+func GetRandomActiveVariant() PromptVariant {
+	var variant PromptVariant
+	err := DB.QueryRow("SELECT id, variant_name, system_prompt, article_prompt, active, create_dt, update_dt from prompt_variants WHERE active = 1 ORDER BY RANDOM() LIMIT 1").Scan(&variant.Id, &variant.VariantName, &variant.SystemPrompt, &variant.ArticlePrompt, &variant.Active, &variant.CreateDate, &variant.UpdateDate)
+	if err != nil {
+		return PromptVariant{}
+	}
+	return variant
+}
+
+func AddPromptVariant(newVariant PromptVariant) (bool, error) {
+
+	tx, err := DB.Begin()
+	if err != nil {
+		return false, err
+	}
+
+	stmt, err := tx.Prepare("INSERT INTO prompt_variants (variant_name, system_prompt, article_prompt, active, create_dt, update_dt) VALUES (?, ?, ?, ?, current_timestamp, current_timestamp)")
+
+	if err != nil {
+		return false, err
+	}
+
+	defer stmt.Close()
+
+	_, err = stmt.Exec(newVariant.VariantName, newVariant.SystemPrompt, newVariant.ArticlePrompt, newVariant.Active)
+
+	if err != nil {
+		return false, err
+	}
+
+	tx.Commit()
+
+	return true, nil
+}
+
+func UpdatePromptVariant(ourVariant PromptVariant) (bool, error) {
+
+	tx, err := DB.Begin()
+	if err != nil {
+		return false, err
+	}
+
+	stmt, err := tx.Prepare("UPDATE prompt_variants SET variant_name = ?, system_prompt = ?, article_prompt = ?, active = ?, update_dt = current_timestamp WHERE id = ?")
+
+	if err != nil {
+		return false, err
+	}
+
+	defer stmt.Close()
+
+	_, err = stmt.Exec(ourVariant.VariantName, ourVariant.SystemPrompt, ourVariant.ArticlePrompt, ourVariant.Active, ourVariant.Id)
+
+	if err != nil {
+		return false, err
+	}
+
+	tx.Commit()
+
+	return true, nil
+}
+
+func DeletePromptVariant(variantId int) (bool, error) {
+
+	tx, err := DB.Begin()
+
+	if err != nil {
+		return false, err
+	}
+
+	stmt, err := tx.Prepare("DELETE from prompt_variants where id = ?")
+
+	if err != nil {
+		return false, err
+	}
+
+	defer stmt.Close()
+
+	_, err = stmt.Exec(variantId)
+
+	if err != nil {
+		return false, err
+	}
+
+	tx.Commit()
+
+	return true, nil
+}
+
+// GetVariantStats compares outcomes per variant. An article counts as regenerated when an earlier
+// article exists for the same idea, as rewritten once a reviewer's rewrite has moved its version past 1, and as
+// manually edited once the view refresh has seen its WordPress post change after the BOT last saved it.
+func GetVariantStats() ([]VariantStats, error) {
+
+	rows, err := DB.Query("SELECT v.id, v.variant_name, v.active, count(a.id), " +
+		"coalesce(avg(length(a.content)), 0), " +
+		"coalesce(sum(CASE WHEN EXISTS (SELECT 1 FROM articles b WHERE b.idea_id = a.idea_id AND b.id < a.id AND CAST(a.idea_id AS INTEGER) > 0) THEN 1 ELSE 0 END), 0), " +
+		"coalesce(sum(CASE WHEN a.version > 1 THEN 1 ELSE 0 END), 0), " +
+		"coalesce(sum(CASE WHEN a.wp_edited = 1 THEN 1 ELSE 0 END), 0), " +
+		"coalesce(avg(a.wp_views), 0), " +
+		"coalesce(max(a.wp_views), 0) " +
+		"FROM prompt_variants v LEFT JOIN articles a ON a.variant_id = v.id GROUP BY v.id, v.variant_name, v.active ORDER BY v.id")
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	stats := make([]VariantStats, 0)
+
+	for rows.Next() {
+		singleEntry := VariantStats{}
+		maxViews := 0
+		err = rows.Scan(&singleEntry.VariantId, &singleEntry.VariantName, &singleEntry.Active, &singleEntry.ArticleCount,
+			&singleEntry.AvgLength, &singleEntry.Regenerated, &singleEntry.Rewritten, &singleEntry.Edited, &singleEntry.AvgViews, &maxViews)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if singleEntry.ArticleCount > 0 {
+			singleEntry.RegenRate = float64(singleEntry.Regenerated) / float64(singleEntry.ArticleCount) * 100
+			singleEntry.RewriteRate = float64(singleEntry.Rewritten) / float64(singleEntry.ArticleCount) * 100
+			singleEntry.EditRate = float64(singleEntry.Edited) / float64(singleEntry.ArticleCount) * 100
+		}
+		singleEntry.ViewsReported = maxViews > 0
+
+		stats = append(stats, singleEntry)
+	}
+
+	err = rows.Err()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return stats, err
+}
+
+// GetExperimentArticles returns the published articles that were written with a variant
+func GetExperimentArticles() ([]Article, error) {
+
+	rows, err := DB.Query("SELECT id, wordpress_id, variant_id, wp_modified from articles WHERE variant_id > 0 AND wordpress_id > 0")
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	articles := make([]Article, 0)
+
+	for rows.Next() {
+		singleEntry := Article{}
+		err = rows.Scan(&singleEntry.Id, &singleEntry.WordPressId, &singleEntry.VariantId, &singleEntry.WpModified)
+
+		if err != nil {
+			return nil, err
+		}
+
+		articles = append(articles, singleEntry)
+	}
+
+	err = rows.Err()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return articles, err
+}
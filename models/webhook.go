@@ -0,0 +1,325 @@
+package models
+
+import (
+	"database/sql"
+	_ "modernc.org/sqlite"
+)
+
+// Webhook is a url events are posted to. Events is the comma separated filter, empty for every event.
+type Webhook struct {
+	Id         int    `json:"id"`
+	Name       string `json:"name"`
+	Url        string `json:"url"`
+	Secret     string `json:"secret"`
+	Events     string `json:"events"`
+	Enabled    bool   `json:"enabled"`
+	CreateDate string `json:"create_dt"`
+	UpdateDate string `json:"update_dt"`
+}
+
+// WebhookDelivery is one event sent to a webhook, Status is pending, retrying, delivered or failed
+type WebhookDelivery struct {
+	Id         int    `json:"id"`
+	WebhookId  int    `json:"webhook_id"`
+	Event      string `json:"event"`
+	Payload    string `json:"payload"`
+	Status     string `json:"status"`
+	Attempts   int    `json:"attempts"`
+	StatusCode int    `json:"status_code"`
+	Response   string `json:"response"`
+	Error      string `json:"error"`
+	CreateDate string `json:"create_dt"`
+	UpdateDate string `json:"update_dt"`
+}
+
+func GetWebhooks() ([]Webhook, error) {
+
+	rows, err := DB.Query("SELECT id, name, url, secret, events, enabled, create_dt, update_dt from webhooks ORDER BY name")
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	webhooks := make([]Webhook, 0)
+
+	for rows.Next() {
+		singleWebhook := Webhook{}
+		err = rows.Scan(&singleWebhook.Id, &singleWebhook.Name, &singleWebhook.Url, &singleWebhook.Secret, &singleWebhook.Events,
+			&singleWebhook.Enabled, &singleWebhook.CreateDate, &singleWebhook.UpdateDate)
+
+		if err != nil {
+			return nil, err
+		}
+
+		webhooks = append(webhooks, singleWebhook)
+	}
+
+	err = rows.Err()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return webhooks, err
+}
+
+func GetWebhookById(id int) (Webhook, error) {
+
+	stmt, err := DB.Prepare("SELECT id, name, url, secret, events, enabled, create_dt, update_dt from webhooks WHERE id = ?")
+
+	if err != nil {
+		return Webhook{}, err
+	}
+
+	webhook := Webhook{}
+
+	sqlErr := stmt.QueryRow(id).Scan(&webhook.Id, &webhook.Name, &webhook.Url, &webhook.Secret, &webhook.Events,
+		&webhook.Enabled, &webhook.CreateDate, &webhook.UpdateDate)
+
+	if sqlErr != nil {
+		if sqlErr == sql.ErrNoRows {
+			return Webhook{}, nil
+		}
+		return Webhook{}, sqlErr
+	}
+	return webhook, nil
+}
+
+func AddWebhook(newWebhook Webhook) (bool, error) {
+
+	tx, err := DB.Begin()
+	if err != nil {
+		return false, err
+	}
+
+	stmt, err := tx.Prepare("INSERT INTO webhooks (name, url, secret, events, enabled, create_dt, update_dt) VALUES (?, ?, ?, ?, ?, current_timestamp, current_timestamp)")
+
+	if err != nil {
+		return false, err
+	}
+
+	defer stmt.Close()
+
+	_, err = stmt.Exec(newWebhook.Name, newWebhook.Url, newWebhook.Secret, newWebhook.Events, newWebhook.Enabled)
+
+	if err != nil {
+		return false, err
+	}
+
+	tx.Commit()
+
+	return true, nil
+}
+
+func UpdateWebhook(ourWebhook Webhook) (bool, error) {
+
+	tx, err := DB.Begin()
+	if err != nil {
+		return false, err
+	}
+
+	stmt, err := tx.Prepare("UPDATE webhooks SET name = ?, url = ?, secret = ?, events = ?, enabled = ?, update_dt = current_timestamp WHERE id = ?")
+
+	if err != nil {
+		return false, err
+	}
+
+	defer stmt.Close()
+
+	_, err = stmt.Exec(ourWebhook.Name, ourWebhook.Url, ourWebhook.Secret, ourWebhook.Events, ourWebhook.Enabled, ourWebhook.Id)
+
+	if err != nil {
+		return false, err
+	}
+
+	tx.Commit()
+
+	return true, nil
+}
+
+// DeleteWebhook removes a webhook along with its delivery log
+func DeleteWebhook(webhookId int) (bool, error) {
+
+	tx, err := DB.Begin()
+
+	if err != nil {
+		return false, err
+	}
+
+	_, err = tx.Exec("DELETE from webhook_deliveries where webhook_id = ?", webhookId)
+
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	_, err = tx.Exec("DELETE from webhooks where id = ?", webhookId)
+
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	tx.Commit()
+
+	return true, nil
+}
+
+// GetWebhookDeliveries returns the latest deliveries first, for one webhook or for all of them when webhookId is 0
+func GetWebhookDeliveries(webhookId int, limit int) ([]WebhookDelivery, error) {
+
+	rows, err := DB.Query("SELECT id, webhook_id, event, payload, status, attempts, status_code, response, error, create_dt, update_dt from webhook_deliveries "+
+		"WHERE webhook_id = ? OR ? = 0 ORDER BY id DESC LIMIT ?", webhookId, webhookId, limit)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	deliveries := make([]WebhookDelivery, 0)
+
+	for rows.Next() {
+		singleDelivery := WebhookDelivery{}
+		err = rows.Scan(&singleDelivery.Id, &singleDelivery.WebhookId, &singleDelivery.Event, &singleDelivery.Payload, &singleDelivery.Status,
+			&singleDelivery.Attempts, &singleDelivery.StatusCode, &singleDelivery.Response, &singleDelivery.Error, &singleDelivery.CreateDate, &singleDelivery.UpdateDate)
+
+		if err != nil {
+			return nil, err
+		}
+
+		deliveries = append(deliveries, singleDelivery)
+	}
+
+	err = rows.Err()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return deliveries, err
+}
+
+// GetRetryingWebhookDeliveries returns the deliveries waiting for another attempt, oldest first
+func GetRetryingWebhookDeliveries() ([]WebhookDelivery, error) {
+
+	rows, err := DB.Query("SELECT id, webhook_id, event, payload, status, attempts, status_code, response, error, create_dt, update_dt from webhook_deliveries " +
+		"WHERE status = 'retrying' ORDER BY id")
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	deliveries := make([]WebhookDelivery, 0)
+
+	for rows.Next() {
+		singleDelivery := WebhookDelivery{}
+		err = rows.Scan(&singleDelivery.Id, &singleDelivery.WebhookId, &singleDelivery.Event, &singleDelivery.Payload, &singleDelivery.Status,
+			&singleDelivery.Attempts, &singleDelivery.StatusCode, &singleDelivery.Response, &singleDelivery.Error, &singleDelivery.CreateDate, &singleDelivery.UpdateDate)
+
+		if err != nil {
+			return nil, err
+		}
+
+		deliveries = append(deliveries, singleDelivery)
+	}
+
+	err = rows.Err()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return deliveries, err
+}
+
+func GetWebhookDeliveryById(id int) (WebhookDelivery, error) {
+
+	stmt, err := DB.Prepare("SELECT id, webhook_id, event, payload, status, attempts, status_code, response, error, create_dt, update_dt from webhook_deliveries WHERE id = ?")
+
+	if err != nil {
+		return WebhookDelivery{}, err
+	}
+
+	delivery := WebhookDelivery{}
+
+	sqlErr := stmt.QueryRow(id).Scan(&delivery.Id, &delivery.WebhookId, &delivery.Event, &delivery.Payload, &delivery.Status,
+		&delivery.Attempts, &delivery.StatusCode, &delivery.Response, &delivery.Error, &delivery.CreateDate, &delivery.UpdateDate)
+
+	if sqlErr != nil {
+		if sqlErr == sql.ErrNoRows {
+			return WebhookDelivery{}, nil
+		}
+		return WebhookDelivery{}, sqlErr
+	}
+	return delivery, nil
+}
+
+// AddWebhookDelivery logs a new delivery, returning its id
+func AddWebhookDelivery(newDelivery WebhookDelivery) (int64, error) {
+
+	tx, err := DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+
+	stmt, err := tx.Prepare("INSERT INTO webhook_deliveries (webhook_id, event, payload, status, attempts, status_code, response, error, create_dt, update_dt) " +
+		"VALUES (?, ?, ?, ?, ?, ?, ?, ?, current_timestamp, current_timestamp)")
+
+	if err != nil {
+		return 0, err
+	}
+
+	defer stmt.Close()
+
+	res, err := stmt.Exec(newDelivery.WebhookId, newDelivery.Event, newDelivery.Payload, newDelivery.Status, newDelivery.Attempts,
+		newDelivery.StatusCode, newDelivery.Response, newDelivery.Error)
+
+	if err != nil {
+		return 0, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return res.LastInsertId()
+}
+
+func UpdateWebhookDelivery(ourDelivery WebhookDelivery) (bool, error) {
+
+	tx, err := DB.Begin()
+	if err != nil {
+		return false, err
+	}
+
+	stmt, err := tx.Prepare("UPDATE webhook_deliveries SET status = ?, attempts = ?, status_code = ?, response = ?, error = ?, update_dt = current_timestamp WHERE id = ?")
+
+	if err != nil {
+		return false, err
+	}
+
+	defer stmt.Close()
+
+	_, err = stmt.Exec(ourDelivery.Status, ourDelivery.Attempts, ourDelivery.StatusCode, ourDelivery.Response, ourDelivery.Error, ourDelivery.Id)
+
+	if err != nil {
+		return false, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// PruneWebhookDeliveries keeps the newest keep deliveries and removes the rest
+func PruneWebhookDeliveries(keep int) error {
+	_, err := DB.Exec("DELETE FROM webhook_deliveries WHERE id NOT IN (SELECT id FROM webhook_deliveries ORDER BY id DESC LIMIT ?)", keep)
+	return err
+}
@@ -76,26 +76,70 @@ func GenerateImageSearch(apiKey string, useGpt4 bool, article string, prompt str
 	return
 }
 
-func GenerateImg(p string, apiKey string) ([]byte, error) {
+func GenerateRevision(apiKey string, useGpt4 bool, article string, prompt string, systemPrompt string) (revision string, err error) {
+	hardRevisionRules := " Return the full revised article alone, keeping the existing HTML markup, no other text or commentary."
+	revision, err = generate(apiKey, useGpt4, prompt+hardRevisionRules, systemPrompt, article)
+	util.Logger.Info().Msg("Generated revision: " + strconv.Itoa(len(revision)) + " characters")
+	return
+}
+
+func GenerateTranslation(apiKey string, useGpt4 bool, text string, prompt string, systemPrompt string) (translation string, err error) {
+	hardTranslationRules := " Return the translation alone, keeping any existing HTML markup, no other text or commentary."
+	translation, err = generate(apiKey, useGpt4, prompt+hardTranslationRules, systemPrompt, text)
+	util.Logger.Info().Msg("Generated translation: " + strconv.Itoa(len(translation)) + " characters")
+	return
+}
+
+func GenerateFactCheck(apiKey string, useGpt4 bool, article string, prompt string, systemPrompt string) (report string, err error) {
+	hardFactCheckRules := " Return the results as JSON alone, no other text or markup, in the form {\"risk\": \"low|medium|high\", \"claims\": [{\"claim\": \"...\", \"verifiable\": true, \"note\": \"...\"}]}."
+	report, err = generate(apiKey, useGpt4, prompt+hardFactCheckRules, systemPrompt, article)
+	util.Logger.Info().Msg("Generated fact check: " + report)
+	return
+}
+
+func GenerateFeedIdea(apiKey string, useGpt4 bool, prompt string, systemPrompt string) (idea string, err error) {
+	hardFeedIdeaRules := " Return the idea alone as a single sentence, no other text, quotes or commentary."
+	idea, err = generate(apiKey, useGpt4, prompt+hardFeedIdeaRules, systemPrompt)
+	util.Logger.Info().Msg("Generated feed idea: " + idea)
+	return
+}
+
+func GenerateImg(ctx context.Context, p string, apiKey string, size string, n int) ([][]byte, error) {
 	client := openai.NewClient(apiKey)
-	ctx := context.Background()
+	if size == "" {
+		size = openai.CreateImageSize256x256
+	}
+	if n < 1 {
+		n = 1
+	}
 	reqBase64 := openai.ImageRequest{
 		Prompt:         p,
-		Size:           openai.CreateImageSize256x256,
+		Size:           size,
 		ResponseFormat: openai.CreateImageResponseFormatB64JSON,
-		N:              1,
+		N:              n,
 	}
 	respBase64, err := client.CreateImage(ctx, reqBase64)
 	if err != nil {
 		return nil, err
 	}
 
-	imgBytes, err := base64.StdEncoding.DecodeString(respBase64.Data[0].B64JSON)
-	if err != nil {
-		return nil, err
+	var images [][]byte
+	for _, data := range respBase64.Data {
+		imgBytes, err := base64.StdEncoding.DecodeString(data.B64JSON)
+		if err != nil {
+			return nil, err
+		}
+		images = append(images, imgBytes)
 	}
 
-	return imgBytes, nil
+	return images, nil
+}
+
+// CheckAccess lists the models the key can use, a cheap way to tell the key works without generating anything
+func CheckAccess(ctx context.Context, apiKey string) error {
+	client := openai.NewClient(apiKey)
+	_, err := client.ListModels(ctx)
+	return err
 }
 
 func generate(apiKey string, useGpt4 bool, prompt string, systemPrompt string, article ...string) (string, error) {
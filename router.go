@@ -4,54 +4,110 @@ import (
 	"bytes"
 	"context"
 	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"golang/bulk"
+	"golang/imagegen"
+	"golang/imagequeue"
 	"golang/models"
-	"golang/stablediffusion"
+	"golang/social"
+	"golang/stockimg"
 	"golang/util"
+	"golang/webhooks"
 	"html/template"
+	"io"
 	"net/http"
+	"net/url"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type Post struct {
-	Title          string `json:"title"`
-	Content        string `json:"content"`
-	Description    string `json:"description"`
-	Image          []byte `json:"image"`
-	Prompt         string `json:"prompt"`
-	ImagePrompt    string `json:"image-prompt"`
-	Error          string `json:"error"`
-	ImageB64       string `json:"image64"`
-	Length         int    `json:"article-length"`
-	PublishStatus  string `json:"publish-status"`
-	UseGpt4        bool   `json:"use-gpt4"`
-	ConceptAsTitle bool   `json:"concept-as-title"`
-	IncludeYt      bool   `json:"include-yt"`
-	YtUrl          string `json:"yt-url"`
-	GenerateImg    bool   `json:"generate-img"`
-	DownloadImg    bool   `json:"download-img"`
-	ImgUrl         string `json:"img-url"`
-	UnsplashImg    bool   `json:"unsplash-img"`
-	IdeaId         string `json:"idea-id"`
-	UnsplashSearch string `json:"unsplash-search"`
-	Keyword        string `json:"keyword"`
-	Concept        string `json:"concept"`
-	ArticleId      int    `json:"article-id"`
-	WordPressId    int    `json:"post-id"`
+	Title           string           `json:"title"`
+	Content         string           `json:"content"`
+	Description     string           `json:"description"`
+	Image           []byte           `json:"image"`
+	Prompt          string           `json:"prompt"`
+	ImagePrompt     string           `json:"image-prompt"`
+	ImageGenPrompt  string           `json:"-"`
+	Error           string           `json:"error"`
+	ImageB64        string           `json:"image64"`
+	Length          int              `json:"article-length"`
+	PublishStatus   string           `json:"publish-status"`
+	UseGpt4         bool             `json:"use-gpt4"`
+	ConceptAsTitle  bool             `json:"concept-as-title"`
+	IncludeYt       bool             `json:"include-yt"`
+	YtUrl           string           `json:"yt-url"`
+	GenerateImg     bool             `json:"generate-img"`
+	DownloadImg     bool             `json:"download-img"`
+	ImgUrl          string           `json:"img-url"`
+	StockImg        bool             `json:"stock-img"`
+	IdeaId          string           `json:"idea-id"`
+	StockSearch     string           `json:"stock-search"`
+	Keyword         string           `json:"keyword"`
+	Concept         string           `json:"concept"`
+	ArticleId       int              `json:"article-id"`
+	WordPressId     int              `json:"post-id"`
+	VariantId       int              `json:"variant-id"`
+	SeriesId        int              `json:"series-id"`
+	WpCategory      int              `json:"wp-category"`
+	PersonaId       int              `json:"persona-id"`
+	PersonaFlags    string           `json:"persona-flags"`
+	Language        string           `json:"language"`
+	MediaId         int              `json:"media-id"`
+	Translations    map[string]int   `json:"translations"`
+	FactCheckRisk   string           `json:"fact-check-risk"`
+	FactCheckReport string           `json:"fact-check-report"`
+	InReview        bool             `json:"in-review"`
+	AutoPost        bool             `json:"auto-post"`
+	PickImage       bool             `json:"pick-image"`
+	ImageCandidates [][]byte         `json:"-"`
+	LibraryMediaId  int              `json:"library-media-id"`
+	LibraryIds      []int            `json:"-"`
+	ImageCredit     string           `json:"image-credit"`
+	ImageLicense    string           `json:"image-license"`
+	StockPhotos     []stockimg.Photo `json:"-"`
+	GeneratedImages []imagegen.Image `json:"-"`
+	ImgModel        string           `json:"img-model"`
+	ImgStyle        string           `json:"img-style"`
+	ImgLora         string           `json:"img-lora"`
+	RefMediaId      int              `json:"ref-media-id"`
+	ImgSource       string           `json:"img-source"`
+	ImgMode         string           `json:"img-mode"`
 }
 
+// FactCheckReport is the review report stored with an article by the fact-check stage
+type FactCheckReport struct {
+	Risk   string           `json:"risk"`
+	Claims []FactCheckClaim `json:"claims"`
+	// Error is why there is no report when the check failed
+	Error string `json:"error,omitempty"`
+}
+type FactCheckClaim struct {
+	Claim      string `json:"claim"`
+	Verifiable bool   `json:"verifiable"`
+	Note       string `json:"note"`
+}
 type WriteData struct {
-	ErrorCode   string `json:"error-code"`
-	GPT4Enabled bool   `json:"gpt4-enabled"`
-	IdeaText    string `json:"idea-text"`
-	IdeaId      string `json:"idea-id"`
+	ErrorCode   string            `json:"error-code"`
+	GPT4Enabled bool              `json:"gpt4-enabled"`
+	IdeaText    string            `json:"idea-text"`
+	IdeaId      string            `json:"idea-id"`
+	Personas    []models.Persona  `json:"personas"`
+	Languages   map[string]string `json:"languages"`
+	Language    string            `json:"language"`
+	Media       models.Media      `json:"media"`
 }
 
 type PlanData struct {
 	ErrorCode string          `json:"error-code"`
 	Ideas     []models.Idea   `json:"ideas"`
 	Series    []models.Series `json:"series"`
+	Tag       string          `json:"tag"`
+	Status    string          `json:"status"`
+	Statuses  []string        `json:"statuses"`
 }
 
 type ArticleListData struct {
@@ -66,23 +122,131 @@ type Prompt struct {
 }
 
 type SeriesData struct {
-	ErrorCode string
-	Series    interface{}
-	Ideas     []models.Idea
+	ErrorCode    string
+	Series       interface{}
+	Parts        []SeriesPart
+	Personas     []models.Persona
+	Languages    map[string]string
+	SdOptions    map[string][]string
+	ImageEngines map[string]string
+}
+
+// SeriesPart is an idea listed on the series screen with its part number
+type SeriesPart struct {
+	models.Idea
+	Part int
 }
 type IdeaData struct {
+	ErrorCode   string
+	Idea        interface{}
+	Languages   map[string]string
+	Transitions []string
+	History     []models.IdeaHistory
+	Articles    []models.Article
+}
+type ExperimentData struct {
 	ErrorCode string
-	Idea      interface{}
+	Enabled   bool
+	Stats     []models.VariantStats
 }
-type SettingsData struct {
+type ConceptListData struct {
 	ErrorCode string
-	Settings  map[string]models.Setting
-	Upscalers map[string]stablediffusion.Upscaler
-	Samplers  map[string]stablediffusion.Algorithm
+	Concepts  []models.ConceptOverride
 }
-type TemplatesData struct {
+type ConceptData struct {
+	ErrorCode    string
+	Concept      models.ConceptOverride
+	ImageEngines map[string]string
+}
+type PersonaListData struct {
+	ErrorCode string
+	Personas  []models.Persona
+}
+type PersonaData struct {
+	ErrorCode string
+	Persona   models.Persona
+}
+type ImportData struct {
+	ErrorCode  string
+	Content    string
+	Format     string
+	Formats    []string
+	Defaults   ImportDefaults
+	Series     []models.Series
+	Languages  map[string]string
+	Rows       []ImportRow
+	Counts     map[string]int
+	Imported   bool
+	ExportData []string
+}
+type FeedListData struct {
+	ErrorCode string
+	Feeds     []models.Feed
+}
+type FeedData struct {
 	ErrorCode string
-	Templates map[string]models.Template
+	Feed      models.Feed
+	Languages map[string]string
+	Items     []models.FeedItem
+}
+type WebhookListData struct {
+	ErrorCode    string
+	Webhooks     []models.Webhook
+	Deliveries   []models.WebhookDelivery
+	WebhookNames map[int]string
+}
+type WebhookData struct {
+	ErrorCode  string
+	Webhook    models.Webhook
+	Events     []string
+	Subscribed map[string]bool
+	Deliveries []models.WebhookDelivery
+}
+type VariantData struct {
+	ErrorCode string
+	Variant   interface{}
+}
+type ReviewData struct {
+	ErrorCode string
+	Articles  []models.Article
+}
+type ImagePickData struct {
+	ErrorCode  string
+	Article    models.Article
+	Candidates []models.ImageCandidate
+}
+type MediaListData struct {
+	ErrorCode string
+	Media     []models.Media
+}
+type ImageQueueData struct {
+	ErrorCode string
+	Jobs      []imagequeue.Job
+}
+type SettingsData struct {
+	ErrorCode      string
+	Settings       map[string]models.Setting
+	Personas       []models.Persona
+	Languages      map[string]string
+	ImageEngines   []ImageEngineSettings
+	SocialChannels []SocialChannelSettings
+}
+type ImageEngineSettings struct {
+	Name   string
+	Label  string
+	Fields []imagegen.SettingField
+}
+type SocialChannelSettings struct {
+	Name   string
+	Label  string
+	Fields []social.SettingField
+}
+type TemplatesData struct {
+	ErrorCode          string
+	Templates          map[string]models.Template
+	LocalizedTemplates map[string]models.Template
+	Languages          map[string]string
+	SocialChannels     []SocialChannelSettings
 }
 type IndexData struct {
 	ErrorCode       string
@@ -113,6 +277,21 @@ var templatesTpl = template.Must(template.ParseFiles(tmplPath("templates.html"),
 var restartTpl = template.Must(template.ParseFiles(tmplPath("restart.html"), tmplPath("base.html")))
 var articleListTpl = template.Must(template.ParseFiles(tmplPath("articleList.html"), tmplPath("base.html")))
 var articleTpl = template.Must(template.ParseFiles(tmplPath("article.html"), tmplPath("base.html")))
+var experimentsTpl = template.Must(template.ParseFiles(tmplPath("experiments.html"), tmplPath("base.html")))
+var conceptListTpl = template.Must(template.ParseFiles(tmplPath("conceptList.html"), tmplPath("base.html")))
+var conceptTpl = template.Must(template.ParseFiles(tmplPath("concept.html"), tmplPath("base.html")))
+var personaListTpl = template.Must(template.ParseFiles(tmplPath("personaList.html"), tmplPath("base.html")))
+var personaTpl = template.Must(template.ParseFiles(tmplPath("persona.html"), tmplPath("base.html")))
+var ideaImportTpl = template.Must(template.ParseFiles(tmplPath("ideaImport.html"), tmplPath("base.html")))
+var feedListTpl = template.Must(template.ParseFiles(tmplPath("feedList.html"), tmplPath("base.html")))
+var feedTpl = template.Must(template.ParseFiles(tmplPath("feed.html"), tmplPath("base.html")))
+var webhookListTpl = template.Must(template.ParseFiles(tmplPath("webhookList.html"), tmplPath("base.html")))
+var webhookTpl = template.Must(template.ParseFiles(tmplPath("webhook.html"), tmplPath("base.html")))
+var variantTpl = template.Must(template.ParseFiles(tmplPath("variant.html"), tmplPath("base.html")))
+var reviewTpl = template.Must(template.ParseFiles(tmplPath("review.html"), tmplPath("base.html")))
+var imagePickTpl = template.Must(template.ParseFiles(tmplPath("imagePick.html"), tmplPath("base.html")))
+var mediaListTpl = template.Must(template.ParseFiles(tmplPath("mediaList.html"), tmplPath("base.html")))
+var imageQueueTpl = template.Must(template.ParseFiles(tmplPath("imageQueue.html"), tmplPath("base.html")))
 
 func indexHandler(w http.ResponseWriter, _ *http.Request) {
 	settings, err := models.GetSettings()
@@ -152,12 +331,14 @@ func writeHandler(w http.ResponseWriter, r *http.Request) {
 		id = 0
 	}
 	ideaText := ""
+	language := ""
 	if id > 0 {
 		idea, err := models.GetIdeaById(ideaId)
 		if err != nil {
 			util.Logger.Error().Err(err).Msg("Error getting idea by id")
 		}
 		ideaText = idea.IdeaText
+		language = idea.Language
 	}
 
 	gpt4 := Settings["ENABLE_GPT4"]
@@ -165,11 +346,27 @@ func writeHandler(w http.ResponseWriter, r *http.Request) {
 	if gpt4 == "true" {
 		gpt4enabled = true
 	}
+	personas, err := models.GetPersonas()
+	if err != nil {
+		util.Logger.Error().Err(err).Msg("Error getting personas")
+	}
+	media := models.Media{}
+	mediaId, convErr := strconv.Atoi(r.FormValue("mediaId"))
+	if convErr == nil && mediaId > 0 {
+		media, err = models.GetMediaById(mediaId)
+		if err != nil {
+			util.Logger.Error().Err(err).Msg("Error getting library image")
+		}
+	}
 	writeData := WriteData{
 		ErrorCode:   "",
 		GPT4Enabled: gpt4enabled,
 		IdeaText:    ideaText,
 		IdeaId:      ideaId,
+		Personas:    personas,
+		Languages:   LanguageNames,
+		Language:    language,
+		Media:       media,
 	}
 	buf := &bytes.Buffer{}
 	renderErr := writeTpl.Execute(buf, writeData)
@@ -182,15 +379,39 @@ func writeHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func ideaListHandler(w http.ResponseWriter, _ *http.Request) {
-	ideas, err := models.GetOpenIdeas()
+func ideaListHandler(w http.ResponseWriter, r *http.Request) {
+	//Without a status the open ideas are listed, with one every idea in it, series ideas included
+	status := strings.ToUpper(strings.TrimSpace(r.URL.Query().Get("status")))
+	var ideas []models.Idea
+	var err error
+	if status != "" {
+		ideas, err = models.GetIdeasByStatus(status)
+	} else {
+		ideas, err = models.GetOpenIdeas()
+	}
 	if err != nil {
 		util.Logger.Error().Err(err).Msg("Error getting open ideas")
 	}
+	tag := strings.TrimSpace(r.URL.Query().Get("tag"))
+	if tag != "" {
+		tagged := make([]models.Idea, 0)
+		for _, idea := range ideas {
+			for _, ideaTag := range idea.TagList() {
+				if strings.EqualFold(ideaTag, tag) {
+					tagged = append(tagged, idea)
+					break
+				}
+			}
+		}
+		ideas = tagged
+	}
 	planData := PlanData{
 		ErrorCode: "",
 		Ideas:     ideas,
 		Series:    nil,
+		Tag:       tag,
+		Status:    status,
+		Statuses:  models.IdeaStatuses,
 	}
 	buf := &bytes.Buffer{}
 	renderErr := ideaListTpl.Execute(buf, planData)
@@ -249,10 +470,15 @@ func articleListHandler(w http.ResponseWriter, _ *http.Request) {
 }
 
 type ArticleData struct {
-	ErrorCode string
-	Article   models.Article
-	ArticleId string
-	MediaUrl  string
+	ErrorCode    string
+	Article      models.Article
+	ArticleId    string
+	MediaUrl     string
+	Translations []models.Article
+	Languages    map[string]string
+	FactCheck    FactCheckReport
+	ImgCredit    template.HTML
+	SocialPosts  []models.SocialPost
 }
 
 func articleHandler(w http.ResponseWriter, r *http.Request) {
@@ -263,9 +489,10 @@ func articleHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	var article models.Article
 	articleData := ArticleData{
-		ErrorCode: "",
+		ErrorCode: r.FormValue("error"),
 		ArticleId: articleId,
 		MediaUrl:  "",
+		Languages: LanguageNames,
 	}
 	if id > 0 {
 		article, err = models.GetArticleById(id)
@@ -273,6 +500,26 @@ func articleHandler(w http.ResponseWriter, r *http.Request) {
 			util.Logger.Error().Err(err).Msg("Error getting article by id")
 		} else {
 			articleData.Article = article
+			//The credit is built by the unsplash package with its values escaped
+			articleData.ImgCredit = template.HTML(article.ImgCredit)
+		}
+		if article.FactCheckReport != "" {
+			err = json.Unmarshal([]byte(article.FactCheckReport), &articleData.FactCheck)
+			if err != nil {
+				util.Logger.Error().Err(err).Msg("Error reading fact check report")
+			}
+		}
+		translations, err := models.GetArticleTranslations(id)
+		if err != nil {
+			util.Logger.Error().Err(err).Msg("Error getting article translations")
+		} else {
+			articleData.Translations = translations
+		}
+		socialPosts, err := models.GetSocialPosts(id)
+		if err != nil {
+			util.Logger.Error().Err(err).Msg("Error getting social posts")
+		} else {
+			articleData.SocialPosts = socialPosts
 		}
 	}
 	if articleData.Article.MediaId > 0 {
@@ -313,9 +560,21 @@ func ideaHandler(w http.ResponseWriter, r *http.Request) {
 		if err != nil {
 			util.Logger.Error().Err(err).Msg("Error getting idea by id")
 		}
+		history, err := models.GetIdeaHistory(id)
+		if err != nil {
+			util.Logger.Error().Err(err).Msg("Error getting idea history")
+		}
+		articles, err := models.GetIdeaArticles(id)
+		if err != nil {
+			util.Logger.Error().Err(err).Msg("Error getting idea articles")
+		}
 		ideaData = IdeaData{
-			ErrorCode: "",
-			Idea:      idea,
+			ErrorCode:   r.FormValue("error"),
+			Idea:        idea,
+			Languages:   LanguageNames,
+			Transitions: models.IdeaTransitions[idea.Status],
+			History:     history,
+			Articles:    articles,
 		}
 	} else {
 		ideaData = IdeaData{
@@ -323,6 +582,7 @@ func ideaHandler(w http.ResponseWriter, r *http.Request) {
 			Idea: models.Idea{
 				SeriesId: sid,
 			},
+			Languages: LanguageNames,
 		}
 	}
 	buf := &bytes.Buffer{}
@@ -348,22 +608,26 @@ func seriesHandler(w http.ResponseWriter, r *http.Request) {
 		if err != nil {
 			util.Logger.Error().Err(err).Msg("Error getting series by id")
 		}
-		ideas, err := models.GetOpenSeriesIdeas(seriesId)
-		if err != nil {
-			util.Logger.Error().Err(err).Msg("Error getting ideas for series")
-		}
 		seriesData = SeriesData{
-			ErrorCode: "",
+			ErrorCode: r.FormValue("error"),
 			Series:    series,
-			Ideas:     ideas,
+			Parts:     seriesParts(seriesId),
 		}
 	} else {
 		seriesData = SeriesData{
 			ErrorCode: "",
-			Series:    models.Series{},
-			Ideas:     nil,
+			Series:    models.Series{NavLinks: true},
+			Parts:     nil,
 		}
 	}
+	personas, err := models.GetPersonas()
+	if err != nil {
+		util.Logger.Error().Err(err).Msg("Error getting personas")
+	}
+	seriesData.Personas = personas
+	seriesData.Languages = LanguageNames
+	seriesData.SdOptions = sdOptions()
+	seriesData.ImageEngines = imageEngineLabels()
 
 	buf := &bytes.Buffer{}
 	renderErr := seriesTpl.Execute(buf, seriesData)
@@ -441,6 +705,7 @@ func ideaSaveHandler(w http.ResponseWriter, r *http.Request) {
 	ideaText := r.FormValue("ideaText")
 	ideaId := r.FormValue("ideaId")
 	seriesId := r.FormValue("seriesId")
+	language := r.FormValue("language")
 	sid, convErr := strconv.Atoi(seriesId)
 	if convErr != nil {
 		sid = 0
@@ -449,13 +714,26 @@ func ideaSaveHandler(w http.ResponseWriter, r *http.Request) {
 	if convErr != nil {
 		id = 0
 	}
+	priority, convErr := strconv.Atoi(r.FormValue("priority"))
+	if convErr != nil {
+		priority = 0
+	}
+	notBefore := strings.TrimSpace(r.FormValue("notBefore"))
+	if _, err := time.Parse("2006-01-02", notBefore); err != nil {
+		notBefore = ""
+	}
 	if id > 0 {
-		//Update by Id
+		//Update by Id, the status only changes through /ideaStatus
 		idea := models.Idea{
-			Id:       id,
-			IdeaText: ideaText,
-			Status:   "NEW",
-			SeriesId: sid,
+			Id:          id,
+			IdeaText:    ideaText,
+			IdeaConcept: strings.TrimSpace(r.FormValue("ideaConcept")),
+			SeriesId:    sid,
+			Language:    language,
+			Priority:    clampPriority(priority),
+			Tags:        normalizeTags(r.FormValue("tags")),
+			Keyword:     strings.TrimSpace(r.FormValue("keyword")),
+			NotBefore:   notBefore,
 		}
 		_, err := models.UpdateIdea(idea, id)
 		if err != nil {
@@ -464,13 +742,22 @@ func ideaSaveHandler(w http.ResponseWriter, r *http.Request) {
 	} else {
 		//Insert New
 		idea := models.Idea{
-			IdeaText: ideaText,
-			Status:   "NEW",
-			SeriesId: sid,
+			IdeaText:    ideaText,
+			Status:      models.IdeaNew,
+			IdeaConcept: strings.TrimSpace(r.FormValue("ideaConcept")),
+			SeriesId:    sid,
+			Language:    language,
+			Priority:    clampPriority(priority),
+			Tags:        normalizeTags(r.FormValue("tags")),
+			Keyword:     strings.TrimSpace(r.FormValue("keyword")),
+			NotBefore:   notBefore,
 		}
-		_, err := models.AddIdea(idea)
+		ideaId, err := models.AddIdea(idea, "web")
 		if err != nil {
 			util.Logger.Error().Err(err).Msg("Error adding idea")
+		} else {
+			idea.Id = int(ideaId)
+			webhooks.Fire(webhooks.IdeaCreated, idea)
 		}
 	}
 	if sid > 0 {
@@ -488,23 +775,50 @@ func seriesSaveHandler(w http.ResponseWriter, r *http.Request) {
 	if convErr != nil {
 		id = 0
 	}
+	articleLength, convErr := strconv.Atoi(r.FormValue("articleLength"))
+	if convErr != nil {
+		articleLength = 0
+	}
+	wpCategory, convErr := strconv.Atoi(r.FormValue("wpCategory"))
+	if convErr != nil {
+		wpCategory = 0
+	}
+	personaId, convErr := strconv.Atoi(r.FormValue("personaId"))
+	if convErr != nil {
+		personaId = 0
+	}
+	refMediaId, convErr := strconv.Atoi(r.FormValue("refMediaId"))
+	if convErr != nil {
+		refMediaId = 0
+	}
+	series := models.Series{
+		Id:            id,
+		SeriesName:    seriesName,
+		SeriesPrompt:  seriesPrompt,
+		SystemPrompt:  r.FormValue("systemPrompt"),
+		ArticlePrompt: r.FormValue("articlePrompt"),
+		ArticleLength: articleLength,
+		ImgEngine:     r.FormValue("imgEngine"),
+		ImgMode:       r.FormValue("imgMode"),
+		PublishStatus: r.FormValue("publishStatus"),
+		WpCategory:    wpCategory,
+		PersonaId:     personaId,
+		Language:      r.FormValue("language"),
+		SdModel:       r.FormValue("sdModel"),
+		SdStyle:       r.FormValue("sdStyle"),
+		SdLora:        r.FormValue("sdLora"),
+		RefMediaId:    refMediaId,
+		PartTitles:    r.FormValue("partTitles") == "true",
+		NavLinks:      r.FormValue("navLinks") == "true",
+	}
 	if id > 0 {
 		//Update by Id
-		series := models.Series{
-			Id:           id,
-			SeriesName:   seriesName,
-			SeriesPrompt: seriesPrompt,
-		}
 		_, err := models.UpdateSeries(series, id)
 		if err != nil {
 			util.Logger.Error().Err(err).Msg("Error updating series")
 		}
 	} else {
 		//Insert New
-		series := models.Series{
-			SeriesName:   seriesName,
-			SeriesPrompt: seriesPrompt,
-		}
 		id, err := models.AddSeriesReturningId(series)
 		if err != nil {
 			util.Logger.Error().Err(err).Msg("Error adding series")
@@ -516,14 +830,18 @@ func seriesSaveHandler(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		util.Logger.Error().Err(err).Msg("Error getting series")
 	}
-	ideas, err := models.GetOpenSeriesIdeas(seriesId)
+	personas, err := models.GetPersonas()
 	if err != nil {
-		util.Logger.Error().Err(err).Msg("Error getting ideas")
+		util.Logger.Error().Err(err).Msg("Error getting personas")
 	}
 	seriesData := SeriesData{
-		ErrorCode: "",
-		Series:    series,
-		Ideas:     ideas,
+		ErrorCode:    "",
+		Series:       series,
+		Parts:        seriesParts(seriesId),
+		Personas:     personas,
+		Languages:    LanguageNames,
+		SdOptions:    sdOptions(),
+		ImageEngines: imageEngineLabels(),
 	}
 	buf := &bytes.Buffer{}
 	renderErr := seriesTpl.Execute(buf, seriesData)
@@ -537,6 +855,61 @@ func seriesSaveHandler(w http.ResponseWriter, r *http.Request) {
 
 }
 
+// seriesParts lists every idea of a series in part order, written ones included so the numbering is complete
+func seriesParts(seriesId string) []SeriesPart {
+	ideas, err := models.GetSeriesIdeas(seriesId)
+	if err != nil {
+		util.Logger.Error().Err(err).Msg("Error getting ideas for series")
+	}
+	parts := make([]SeriesPart, 0, len(ideas))
+	for i, idea := range ideas {
+		parts = append(parts, SeriesPart{Idea: idea, Part: i + 1})
+	}
+	return parts
+}
+
+// seriesMoveHandler moves an idea one part earlier or later in its series
+func seriesMoveHandler(w http.ResponseWriter, r *http.Request) {
+	ideaId, convErr := strconv.Atoi(r.FormValue("ideaId"))
+	if convErr != nil {
+		ideaId = 0
+	}
+	idea, err := models.GetIdeaById(strconv.Itoa(ideaId))
+	if err != nil || idea.SeriesId == 0 {
+		http.Redirect(w, r, "/seriesList", http.StatusSeeOther)
+		return
+	}
+	seriesUrl := "/series?seriesId=" + strconv.Itoa(idea.SeriesId)
+	err = models.MoveSeriesIdea(ideaId, r.FormValue("dir") != "down")
+	if err != nil {
+		util.Logger.Error().Err(err).Msg("Error moving series idea")
+		http.Redirect(w, r, seriesUrl+"&error="+url.QueryEscape(err.Error()), http.StatusSeeOther)
+		return
+	}
+	http.Redirect(w, r, seriesUrl, http.StatusSeeOther)
+}
+
+// ideaStatusHandler moves an idea to another status in its lifecycle, recording who moved it and why
+func ideaStatusHandler(w http.ResponseWriter, r *http.Request) {
+	ideaId, convErr := strconv.Atoi(r.FormValue("ideaId"))
+	if convErr != nil || ideaId == 0 {
+		http.Redirect(w, r, "/ideaList", http.StatusSeeOther)
+		return
+	}
+	ideaUrl := "/idea?ideaId=" + strconv.Itoa(ideaId)
+	changedBy := strings.TrimSpace(r.FormValue("changedBy"))
+	if changedBy == "" {
+		changedBy = "web"
+	}
+	_, err := models.SetIdeaStatus(strconv.Itoa(ideaId), r.FormValue("status"), changedBy, strings.TrimSpace(r.FormValue("note")))
+	if err != nil {
+		util.Logger.Error().Err(err).Msg("Error changing idea status")
+		http.Redirect(w, r, ideaUrl+"&error="+url.QueryEscape(err.Error()), http.StatusSeeOther)
+		return
+	}
+	http.Redirect(w, r, ideaUrl, http.StatusSeeOther)
+}
+
 func ideaRemoveHandler(w http.ResponseWriter, r *http.Request) {
 	ideaId := r.FormValue("ideaId")
 	id, convErr := strconv.Atoi(ideaId)
@@ -561,15 +934,24 @@ func createHandler(w http.ResponseWriter, r *http.Request) {
 	length := r.FormValue("articleLength")
 	gpt4 := r.FormValue("useGpt4")
 	ideaId := r.FormValue("ideaId")
-	unsplashImg := r.FormValue("unsplashImage")
-	unsplashSearch := r.FormValue("unsplashPrompt")
+	stockImg := r.FormValue("stockImage")
+	stockSearch := r.FormValue("stockPrompt")
 	publishStatus := r.FormValue("publishStatus")
 	conceptAsTitle := r.FormValue("conceptAsTitle")
+	personaId, convErr := strconv.Atoi(r.FormValue("personaId"))
+	if convErr != nil {
+		personaId = 0
+	}
+	language := r.FormValue("language")
+	libraryMediaId, convErr := strconv.Atoi(r.FormValue("libraryMediaId"))
+	if convErr != nil || r.FormValue("libraryImage") != "true" {
+		libraryMediaId = 0
+	}
 	var imgBytes []byte
 
 	iLen, convErr := strconv.Atoi(length)
 	if convErr != nil {
-		iLen = 500
+		iLen = 0
 	}
 
 	iId, err := strconv.Atoi(ideaId)
@@ -577,12 +959,19 @@ func createHandler(w http.ResponseWriter, r *http.Request) {
 		iId = 0
 	}
 	concept := ""
+	keyword := ""
+	sid := 0
 	if iId > 0 {
 		idea, err := models.GetIdeaById(ideaId)
 		if err != nil {
 			util.Logger.Error().Err(err).Msg("Error getting idea")
 		}
 		concept = idea.IdeaConcept
+		sid = idea.SeriesId
+		keyword = idea.Keyword
+		if language == "" {
+			language = idea.Language
+		}
 	}
 
 	post := Post{
@@ -603,13 +992,18 @@ func createHandler(w http.ResponseWriter, r *http.Request) {
 		GenerateImg:    generateImg == "true",
 		DownloadImg:    downloadImg == "true",
 		ImgUrl:         imgUrl,
-		UnsplashImg:    unsplashImg == "true",
+		StockImg:       stockImg == "true",
 		IdeaId:         ideaId,
-		UnsplashSearch: unsplashSearch,
+		StockSearch:    stockSearch,
 		Concept:        concept,
+		Keyword:        keyword,
+		SeriesId:       sid,
+		PersonaId:      personaId,
+		Language:       language,
+		LibraryMediaId: libraryMediaId,
 	}
 
-	err, post = writeArticle(post)
+	err, post = writeIdeaArticle(post)
 	if err != nil {
 		post.Error = err.Error()
 	}
@@ -637,32 +1031,127 @@ func testHandler(w http.ResponseWriter, r *http.Request) {
 
 }
 
+// ImageProgressData is what the pages poll while an image generates, Preview is base64 encoded
+type ImageProgressData struct {
+	Active  bool    `json:"active"`
+	Percent float64 `json:"percent"`
+	Eta     float64 `json:"eta"`
+	Step    int     `json:"step"`
+	Steps   int     `json:"steps"`
+	Preview string  `json:"preview"`
+}
+
+func imageProgressHandler(w http.ResponseWriter, _ *http.Request) {
+	progress := imageProgress()
+	progressData := ImageProgressData{
+		Active:  progress.Active,
+		Percent: progress.Percent,
+		Eta:     progress.Eta,
+		Step:    progress.Step,
+		Steps:   progress.Steps,
+	}
+	if len(progress.Preview) > 0 {
+		progressData.Preview = base64.StdEncoding.EncodeToString(progress.Preview)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	err := json.NewEncoder(w).Encode(progressData)
+	if err != nil {
+		util.Logger.Error().Err(err).Msg("Error writing image progress")
+	}
+}
+
+func imageCancelHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	//A single job is cancelled from the queue page, everything from the progress panel
+	if jobId, convErr := strconv.Atoi(r.FormValue("jobId")); convErr == nil {
+		if !cancelImageJob(jobId) {
+			http.Redirect(w, r, "/imageQueue?error="+url.QueryEscape("Image job "+strconv.Itoa(jobId)+" already finished"), http.StatusSeeOther)
+			return
+		}
+		http.Redirect(w, r, "/imageQueue", http.StatusSeeOther)
+		return
+	}
+	cancelled := cancelImageJobs()
+	util.Logger.Info().Msg("Cancelled " + strconv.Itoa(cancelled) + " image generations")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// imageOptionsTimeout keeps a stalled image engine from holding up the pages that list its options
+const imageOptionsTimeout = 10 * time.Second
+
+// sdOptions lists the checkpoints, styles and LoRAs Stable Diffusion offers, empty unless it is the image engine
+func sdOptions() map[string][]string {
+	if Settings["IMG_MODE"] != "sd" {
+		return nil
+	}
+	generator, ok := imagegen.Get("sd")
+	if !ok {
+		return nil
+	}
+	loader, ok := generator.(imagegen.OptionLoader)
+	if !ok {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), imageOptionsTimeout)
+	defer cancel()
+	return loader.LoadOptions(ctx, Settings)
+}
+
 func settingsHandler(w http.ResponseWriter, r *http.Request) {
 	settings, err := models.GetSettings()
 
 	if err != nil {
 		util.Logger.Error().Err(err).Msg("Error getting settings")
 	}
-	sdUrl := Settings["SD_URL"]
-	upscalers := map[string]stablediffusion.Upscaler{}
-	samplers := map[string]stablediffusion.Algorithm{}
-	if sdUrl != "" {
-		ctx := context.Background()
-		upscalers, err = stablediffusion.GetUpscalers(sdUrl, ctx)
-		if err != nil {
-			util.Logger.Error().Err(err).Msg("Error getting upscalers")
+	imageEngines := []ImageEngineSettings{}
+	for _, generator := range imagegen.All() {
+		fields := generator.Fields()
+		if loader, ok := generator.(imagegen.OptionLoader); ok {
+			ctx, cancel := context.WithTimeout(r.Context(), imageOptionsTimeout)
+			options := loader.LoadOptions(ctx, Settings)
+			cancel()
+			for i := range fields {
+				if loaded, found := options[fields[i].Name]; found {
+					fields[i].Options = loaded
+				}
+			}
 		}
-		samplers, err = stablediffusion.GetSamplers(sdUrl, ctx)
-		if err != nil {
-			util.Logger.Error().Err(err).Msg("Error getting samplers")
+		//Keep the saved value selectable even when the backend can't be reached to list its options
+		for i := range fields {
+			current := Settings[fields[i].Name]
+			if fields[i].Type != "select" || current == "" {
+				continue
+			}
+			found := false
+			for _, option := range fields[i].Options {
+				found = found || option == current
+			}
+			if !found {
+				fields[i].Options = append(fields[i].Options, current)
+			}
 		}
+		imageEngines = append(imageEngines, ImageEngineSettings{
+			Name:   generator.Name(),
+			Label:  generator.Label(),
+			Fields: fields,
+		})
 	}
 
+	personas, err := models.GetPersonas()
+	if err != nil {
+		util.Logger.Error().Err(err).Msg("Error getting personas")
+	}
 	settingsData := SettingsData{
-		ErrorCode: "",
-		Settings:  settings,
-		Upscalers: upscalers,
-		Samplers:  samplers,
+		ErrorCode:      "",
+		Settings:       settings,
+		Personas:       personas,
+		Languages:      LanguageNames,
+		ImageEngines:   imageEngines,
+		SocialChannels: socialChannelSettings(),
 	}
 	buf := &bytes.Buffer{}
 	renderErr := settingsTpl.Execute(buf, settingsData)
@@ -689,15 +1178,38 @@ func settingsSaveHandler(w http.ResponseWriter, r *http.Request) {
 	restartHandler(w, r)
 }
 
+// socialChannelSettings lists the registered social channels and the settings each reads
+func socialChannelSettings() []SocialChannelSettings {
+	channels := []SocialChannelSettings{}
+	for _, channel := range social.All() {
+		channels = append(channels, SocialChannelSettings{
+			Name:   channel.Name(),
+			Label:  channel.Label(),
+			Fields: channel.Fields(),
+		})
+	}
+	return channels
+}
+
 func templateHandler(w http.ResponseWriter, r *http.Request) {
 	templates, err := models.GetTemplates()
-	templatesDate := TemplatesData{
-		ErrorCode: "",
-		Templates: templates,
-	}
 	if err != nil {
 		util.Logger.Error().Err(err).Msg("Error getting templates")
 	}
+	//Localized templates are named <template>.<language>
+	localizedTemplates := map[string]models.Template{}
+	for name, tmpl := range templates {
+		if strings.Contains(name, ".") {
+			localizedTemplates[name] = tmpl
+		}
+	}
+	templatesDate := TemplatesData{
+		ErrorCode:          "",
+		Templates:          templates,
+		LocalizedTemplates: localizedTemplates,
+		Languages:          LanguageNames,
+		SocialChannels:     socialChannelSettings(),
+	}
 
 	buf := &bytes.Buffer{}
 	renderErr := templatesTpl.Execute(buf, templatesDate)
@@ -714,12 +1226,24 @@ func templateSaveHandler(w http.ResponseWriter, r *http.Request) {
 	templates := map[string]string{}
 	_ = r.FormValue("system-prompt")
 	for k, v := range r.Form {
+		if k == "newTemplateName" || k == "newTemplateLanguage" || k == "newTemplateText" {
+			continue
+		}
 		templates[k] = v[0]
 		_, err := models.UpsertTemplate(k, v[0])
 		if err != nil {
 			util.Logger.Error().Err(err).Msg("Error updating setting")
 		}
 	}
+	newTemplateName := r.FormValue("newTemplateName")
+	newTemplateLanguage := r.FormValue("newTemplateLanguage")
+	newTemplateText := r.FormValue("newTemplateText")
+	if newTemplateName != "" && newTemplateLanguage != "" && strings.TrimSpace(newTemplateText) != "" {
+		_, err := models.UpsertTemplate(newTemplateName+"."+newTemplateLanguage, newTemplateText)
+		if err != nil {
+			util.Logger.Error().Err(err).Msg("Error adding localized template")
+		}
+	}
 	loadTemplates()
 	templateHandler(w, r)
 }
@@ -731,8 +1255,8 @@ func retestHandler(w http.ResponseWriter, r *http.Request) {
 		testOpenAI()
 	} else if r.FormValue("test") == "unsplash" {
 		testUnsplash()
-	} else if r.FormValue("test") == "sd" {
-		testStableDiffusion()
+	} else if r.FormValue("test") == "image" {
+		testImageEngine()
 	} else {
 		runSystemTests()
 	}
@@ -753,3 +1277,903 @@ func restartHandler(w http.ResponseWriter, r *http.Request) {
 	task := Restart{}
 	RestartChannel <- task
 }
+
+func experimentsHandler(w http.ResponseWriter, _ *http.Request) {
+	stats, err := models.GetVariantStats()
+	if err != nil {
+		util.Logger.Error().Err(err).Msg("Error getting variant stats")
+	}
+	experimentData := ExperimentData{
+		ErrorCode: "",
+		Enabled:   Settings["PROMPT_EXPERIMENT_ENABLE"] == "true",
+		Stats:     stats,
+	}
+	buf := &bytes.Buffer{}
+	renderErr := experimentsTpl.Execute(buf, experimentData)
+	if renderErr != nil {
+		util.Logger.Error().Err(renderErr).Msg("Error executing template")
+	}
+	_, renderErr = buf.WriteTo(w)
+	if renderErr != nil {
+		util.Logger.Error().Err(renderErr).Msg("Error writing template to buffer")
+	}
+}
+
+func experimentViewsHandler(w http.ResponseWriter, r *http.Request) {
+	refreshExperimentViews()
+	experimentsHandler(w, r)
+}
+
+func variantHandler(w http.ResponseWriter, r *http.Request) {
+	variantId := r.FormValue("variantId")
+	id, convErr := strconv.Atoi(variantId)
+	if convErr != nil {
+		id = 0
+	}
+	variantData := VariantData{
+		ErrorCode: "",
+		Variant: models.PromptVariant{
+			SystemPrompt:  Templates["system-prompt"],
+			ArticlePrompt: Templates["article-prompt"],
+			Active:        true,
+		},
+	}
+	if id > 0 {
+		variant, err := models.GetPromptVariantById(variantId)
+		if err != nil {
+			util.Logger.Error().Err(err).Msg("Error getting variant by id")
+		}
+		variantData.Variant = variant
+	}
+	buf := &bytes.Buffer{}
+	renderErr := variantTpl.Execute(buf, variantData)
+	if renderErr != nil {
+		util.Logger.Error().Err(renderErr).Msg("Error executing template")
+	}
+	_, renderErr = buf.WriteTo(w)
+	if renderErr != nil {
+		util.Logger.Error().Err(renderErr).Msg("Error writing template to buffer")
+	}
+}
+
+func variantSaveHandler(w http.ResponseWriter, r *http.Request) {
+	variantId := r.FormValue("variantId")
+	id, convErr := strconv.Atoi(variantId)
+	if convErr != nil {
+		id = 0
+	}
+	variant := models.PromptVariant{
+		Id:            id,
+		VariantName:   r.FormValue("variantName"),
+		SystemPrompt:  r.FormValue("systemPrompt"),
+		ArticlePrompt: r.FormValue("articlePrompt"),
+		Active:        r.FormValue("active") == "true",
+	}
+	if id > 0 {
+		_, err := models.UpdatePromptVariant(variant)
+		if err != nil {
+			util.Logger.Error().Err(err).Msg("Error updating variant")
+		}
+	} else {
+		_, err := models.AddPromptVariant(variant)
+		if err != nil {
+			util.Logger.Error().Err(err).Msg("Error adding variant")
+		}
+	}
+	experimentsHandler(w, r)
+}
+
+func variantRemoveHandler(w http.ResponseWriter, r *http.Request) {
+	variantId := r.FormValue("variantId")
+	id, convErr := strconv.Atoi(variantId)
+	if convErr != nil {
+		id = 0
+	}
+	if id > 0 {
+		_, err := models.DeletePromptVariant(id)
+		if err != nil {
+			util.Logger.Error().Err(err).Msg("Error deleting variant")
+		}
+	}
+	experimentsHandler(w, r)
+}
+
+func conceptListHandler(w http.ResponseWriter, _ *http.Request) {
+	concepts, err := models.GetConceptOverrides()
+	if err != nil {
+		util.Logger.Error().Err(err).Msg("Error getting concepts")
+	}
+	conceptListData := ConceptListData{
+		ErrorCode: "",
+		Concepts:  concepts,
+	}
+	buf := &bytes.Buffer{}
+	renderErr := conceptListTpl.Execute(buf, conceptListData)
+	if renderErr != nil {
+		util.Logger.Error().Err(renderErr).Msg("Error executing template")
+	}
+	_, renderErr = buf.WriteTo(w)
+	if renderErr != nil {
+		util.Logger.Error().Err(renderErr).Msg("Error writing template to buffer")
+	}
+}
+
+// imageEngineLabels maps the registered image engines' names to their labels for the override screens
+func imageEngineLabels() map[string]string {
+	labels := map[string]string{}
+	for _, generator := range imagegen.All() {
+		labels[generator.Name()] = generator.Label()
+	}
+	return labels
+}
+
+func conceptHandler(w http.ResponseWriter, r *http.Request) {
+	concept, err := models.GetConceptOverride(r.FormValue("concept"))
+	if err != nil {
+		util.Logger.Error().Err(err).Msg("Error getting concept")
+	}
+	conceptData := ConceptData{
+		ErrorCode:    "",
+		Concept:      concept,
+		ImageEngines: imageEngineLabels(),
+	}
+	buf := &bytes.Buffer{}
+	renderErr := conceptTpl.Execute(buf, conceptData)
+	if renderErr != nil {
+		util.Logger.Error().Err(renderErr).Msg("Error executing template")
+	}
+	_, renderErr = buf.WriteTo(w)
+	if renderErr != nil {
+		util.Logger.Error().Err(renderErr).Msg("Error writing template to buffer")
+	}
+}
+
+func conceptSaveHandler(w http.ResponseWriter, r *http.Request) {
+	concept := r.FormValue("concept")
+	articleLength, convErr := strconv.Atoi(r.FormValue("articleLength"))
+	if convErr != nil {
+		articleLength = 0
+	}
+	wpCategory, convErr := strconv.Atoi(r.FormValue("wpCategory"))
+	if convErr != nil {
+		wpCategory = 0
+	}
+	if strings.TrimSpace(concept) != "" {
+		override := models.ConceptOverride{
+			Concept:       concept,
+			SystemPrompt:  r.FormValue("systemPrompt"),
+			ArticlePrompt: r.FormValue("articlePrompt"),
+			ArticleLength: articleLength,
+			ImgEngine:     r.FormValue("imgEngine"),
+			ImgMode:       r.FormValue("imgMode"),
+			PublishStatus: r.FormValue("publishStatus"),
+			WpCategory:    wpCategory,
+		}
+		_, err := models.UpsertConceptOverride(override)
+		if err != nil {
+			util.Logger.Error().Err(err).Msg("Error saving concept overrides")
+		}
+	}
+	conceptListHandler(w, r)
+}
+
+func personaListHandler(w http.ResponseWriter, _ *http.Request) {
+	personas, err := models.GetPersonas()
+	if err != nil {
+		util.Logger.Error().Err(err).Msg("Error getting personas")
+	}
+	personaListData := PersonaListData{
+		ErrorCode: "",
+		Personas:  personas,
+	}
+	buf := &bytes.Buffer{}
+	renderErr := personaListTpl.Execute(buf, personaListData)
+	if renderErr != nil {
+		util.Logger.Error().Err(renderErr).Msg("Error executing template")
+	}
+	_, renderErr = buf.WriteTo(w)
+	if renderErr != nil {
+		util.Logger.Error().Err(renderErr).Msg("Error writing template to buffer")
+	}
+}
+
+func personaHandler(w http.ResponseWriter, r *http.Request) {
+	personaId := r.FormValue("personaId")
+	id, convErr := strconv.Atoi(personaId)
+	if convErr != nil {
+		id = 0
+	}
+	personaData := PersonaData{
+		ErrorCode: "",
+		Persona:   models.Persona{},
+	}
+	if id > 0 {
+		persona, err := models.GetPersonaById(personaId)
+		if err != nil {
+			util.Logger.Error().Err(err).Msg("Error getting persona by id")
+		}
+		personaData.Persona = persona
+	}
+	buf := &bytes.Buffer{}
+	renderErr := personaTpl.Execute(buf, personaData)
+	if renderErr != nil {
+		util.Logger.Error().Err(renderErr).Msg("Error executing template")
+	}
+	_, renderErr = buf.WriteTo(w)
+	if renderErr != nil {
+		util.Logger.Error().Err(renderErr).Msg("Error writing template to buffer")
+	}
+}
+
+func personaSaveHandler(w http.ResponseWriter, r *http.Request) {
+	personaId := r.FormValue("personaId")
+	id, convErr := strconv.Atoi(personaId)
+	if convErr != nil {
+		id = 0
+	}
+	persona := models.Persona{
+		Id:            id,
+		PersonaName:   r.FormValue("personaName"),
+		Voice:         r.FormValue("voice"),
+		StyleRules:    r.FormValue("styleRules"),
+		BannedPhrases: r.FormValue("bannedPhrases"),
+		Examples:      r.FormValue("examples"),
+	}
+	if id > 0 {
+		_, err := models.UpdatePersona(persona)
+		if err != nil {
+			util.Logger.Error().Err(err).Msg("Error updating persona")
+		}
+	} else {
+		_, err := models.AddPersona(persona)
+		if err != nil {
+			util.Logger.Error().Err(err).Msg("Error adding persona")
+		}
+	}
+	personaListHandler(w, r)
+}
+
+func personaRemoveHandler(w http.ResponseWriter, r *http.Request) {
+	personaId := r.FormValue("personaId")
+	id, convErr := strconv.Atoi(personaId)
+	if convErr != nil {
+		id = 0
+	}
+	if id > 0 {
+		_, err := models.DeletePersona(id)
+		if err != nil {
+			util.Logger.Error().Err(err).Msg("Error deleting persona")
+		}
+	}
+	personaListHandler(w, r)
+}
+
+// importMaxSize bounds the size of an uploaded import file
+const importMaxSize = 10 << 20
+
+// ideaImportHandler shows the import form. Posting it with action preview lists what would be imported,
+// and with action import adds the ideas that are neither duplicates nor errors.
+func ideaImportHandler(w http.ResponseWriter, r *http.Request) {
+	importData := ImportData{
+		ErrorCode:  r.FormValue("error"),
+		Format:     "auto",
+		Formats:    bulk.Formats,
+		Languages:  LanguageNames,
+		ExportData: ExportData,
+	}
+	seriesList, err := models.GetSeries()
+	if err != nil {
+		util.Logger.Error().Err(err).Msg("Error getting series")
+	}
+	importData.Series = seriesList
+	if r.Method == http.MethodPost {
+		importData = runImport(r, importData)
+	}
+	buf := &bytes.Buffer{}
+	renderErr := ideaImportTpl.Execute(buf, importData)
+	if renderErr != nil {
+		util.Logger.Error().Err(renderErr).Msg("Error executing template")
+	}
+	_, renderErr = buf.WriteTo(w)
+	if renderErr != nil {
+		util.Logger.Error().Err(renderErr).Msg("Error writing template to buffer")
+	}
+}
+
+func runImport(r *http.Request, importData ImportData) ImportData {
+	err := r.ParseMultipartForm(importMaxSize)
+	if err != nil && !errors.Is(err, http.ErrNotMultipart) {
+		importData.ErrorCode = err.Error()
+		return importData
+	}
+	importData.Content = r.FormValue("content")
+	importData.Format = r.FormValue("format")
+	sid, convErr := strconv.Atoi(r.FormValue("seriesId"))
+	if convErr != nil {
+		sid = 0
+	}
+	importData.Defaults = ImportDefaults{
+		Concept:  strings.TrimSpace(r.FormValue("concept")),
+		SeriesId: sid,
+		Language: r.FormValue("language"),
+	}
+	fileName := ""
+	if file, fileHeader, err := r.FormFile("file"); err == nil {
+		data, err := io.ReadAll(file)
+		file.Close()
+		if err != nil {
+			importData.ErrorCode = err.Error()
+			return importData
+		}
+		if len(data) > 0 {
+			importData.Content = string(data)
+			fileName = fileHeader.Filename
+		}
+	}
+	if strings.TrimSpace(importData.Content) == "" {
+		importData.ErrorCode = "Choose a file or paste the ideas to import"
+		return importData
+	}
+	format := importData.Format
+	if format == "" || format == "auto" {
+		format = bulk.Detect(fileName, []byte(importData.Content))
+	}
+	rows, err := bulk.Parse(format, []byte(importData.Content))
+	if err != nil {
+		importData.ErrorCode = "Could not read the ideas as " + format + ": " + err.Error()
+		return importData
+	}
+	// Keep the detected format so the import reads the pasted content the same way the preview did
+	importData.Format = format
+	importData.Rows, err = planImport(rows, importData.Defaults)
+	if err != nil {
+		util.Logger.Error().Err(err).Msg("Error planning import")
+		importData.ErrorCode = err.Error()
+		return importData
+	}
+	if r.FormValue("action") == "import" {
+		importIdeas(importData.Rows)
+		importData.Imported = true
+		importData.Content = ""
+	}
+	importData.Counts = map[string]int{}
+	for _, row := range importData.Rows {
+		importData.Counts[row.Status]++
+	}
+	return importData
+}
+
+// exportHandler downloads ideas, series or articles as CSV, JSON or Markdown
+func exportHandler(w http.ResponseWriter, r *http.Request) {
+	data := r.FormValue("data")
+	format := r.FormValue("format")
+	extension, ok := bulk.Extensions[format]
+	if !ok {
+		http.Error(w, "Unknown format "+format, http.StatusBadRequest)
+		return
+	}
+	table, err := exportTable(data)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	buf := &bytes.Buffer{}
+	if err := bulk.Write(buf, format, table); err != nil {
+		util.Logger.Error().Err(err).Msg("Error writing export")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", bulk.ContentTypes[format])
+	w.Header().Set("Content-Disposition", "attachment; filename=\"blogotron-"+data+extension+"\"")
+	_, err = buf.WriteTo(w)
+	if err != nil {
+		util.Logger.Error().Err(err).Msg("Error writing export")
+	}
+}
+
+// feedItemCount is how many of the latest entries the feed screen shows
+const feedItemCount = 50
+
+func feedListHandler(w http.ResponseWriter, r *http.Request) {
+	feedList, err := models.GetFeeds()
+	if err != nil {
+		util.Logger.Error().Err(err).Msg("Error getting feeds")
+	}
+	feedListData := FeedListData{
+		ErrorCode: r.FormValue("error"),
+		Feeds:     feedList,
+	}
+	buf := &bytes.Buffer{}
+	renderErr := feedListTpl.Execute(buf, feedListData)
+	if renderErr != nil {
+		util.Logger.Error().Err(renderErr).Msg("Error executing template")
+	}
+	_, renderErr = buf.WriteTo(w)
+	if renderErr != nil {
+		util.Logger.Error().Err(renderErr).Msg("Error writing template to buffer")
+	}
+}
+
+func feedHandler(w http.ResponseWriter, r *http.Request) {
+	feedId := r.FormValue("feedId")
+	id, convErr := strconv.Atoi(feedId)
+	if convErr != nil {
+		id = 0
+	}
+	feedData := FeedData{
+		ErrorCode: r.FormValue("error"),
+		Feed:      models.Feed{Enabled: true},
+		Languages: LanguageNames,
+	}
+	if id > 0 {
+		feed, err := models.GetFeedById(id)
+		if err != nil {
+			util.Logger.Error().Err(err).Msg("Error getting feed by id")
+		}
+		feedData.Feed = feed
+		items, err := models.GetFeedItems(id, feedItemCount)
+		if err != nil {
+			util.Logger.Error().Err(err).Msg("Error getting feed items")
+		}
+		feedData.Items = items
+	}
+	buf := &bytes.Buffer{}
+	renderErr := feedTpl.Execute(buf, feedData)
+	if renderErr != nil {
+		util.Logger.Error().Err(renderErr).Msg("Error executing template")
+	}
+	_, renderErr = buf.WriteTo(w)
+	if renderErr != nil {
+		util.Logger.Error().Err(renderErr).Msg("Error writing template to buffer")
+	}
+}
+
+func feedSaveHandler(w http.ResponseWriter, r *http.Request) {
+	feedId := r.FormValue("feedId")
+	id, convErr := strconv.Atoi(feedId)
+	if convErr != nil {
+		id = 0
+	}
+	feed := models.Feed{
+		Id:       id,
+		Name:     strings.TrimSpace(r.FormValue("feedName")),
+		Url:      strings.TrimSpace(r.FormValue("feedUrl")),
+		Rewrite:  r.FormValue("rewrite") == "true",
+		Language: r.FormValue("language"),
+		Enabled:  r.FormValue("enabled") == "true",
+	}
+	if parsed, err := url.Parse(feed.Url); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		http.Redirect(w, r, "/feed?feedId="+feedId+"&error="+url.QueryEscape("The url must start with http:// or https://"), http.StatusSeeOther)
+		return
+	}
+	if feed.Name == "" {
+		http.Redirect(w, r, "/feed?feedId="+feedId+"&error="+url.QueryEscape("The name is required, it is the concept of the feed's ideas"), http.StatusSeeOther)
+		return
+	}
+	if id > 0 {
+		_, err := models.UpdateFeed(feed)
+		if err != nil {
+			util.Logger.Error().Err(err).Msg("Error updating feed")
+		}
+	} else {
+		_, err := models.AddFeed(feed)
+		if err != nil {
+			util.Logger.Error().Err(err).Msg("Error adding feed")
+		}
+	}
+	http.Redirect(w, r, "/feeds", http.StatusSeeOther)
+}
+
+func feedRemoveHandler(w http.ResponseWriter, r *http.Request) {
+	feedId := r.FormValue("feedId")
+	id, convErr := strconv.Atoi(feedId)
+	if convErr != nil {
+		id = 0
+	}
+	if id > 0 {
+		_, err := models.DeleteFeed(id)
+		if err != nil {
+			util.Logger.Error().Err(err).Msg("Error deleting feed")
+		}
+	}
+	http.Redirect(w, r, "/feeds", http.StatusSeeOther)
+}
+
+// feedCheckHandler checks one feed now rather than waiting for FEED_INTERVAL, even when it is disabled
+func feedCheckHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	feedId := r.FormValue("feedId")
+	id, convErr := strconv.Atoi(feedId)
+	if convErr != nil {
+		id = 0
+	}
+	feed, err := models.GetFeedById(id)
+	if err == nil && feed.Id == 0 {
+		err = errors.New("Save the feed before checking it")
+	}
+	if err == nil {
+		_, err = checkFeed(feed)
+	}
+	if err != nil {
+		util.Logger.Error().Err(err).Msg("Error checking feed")
+		http.Redirect(w, r, "/feed?feedId="+feedId+"&error="+url.QueryEscape(err.Error()), http.StatusSeeOther)
+		return
+	}
+	http.Redirect(w, r, "/feed?feedId="+feedId, http.StatusSeeOther)
+}
+
+// webhookDeliveryCount is how many of the latest deliveries the webhook screens show
+const webhookDeliveryCount = 50
+
+func webhookListHandler(w http.ResponseWriter, r *http.Request) {
+	hooks, err := models.GetWebhooks()
+	if err != nil {
+		util.Logger.Error().Err(err).Msg("Error getting webhooks")
+	}
+	deliveries, err := models.GetWebhookDeliveries(0, webhookDeliveryCount)
+	if err != nil {
+		util.Logger.Error().Err(err).Msg("Error getting webhook deliveries")
+	}
+	webhookListData := WebhookListData{
+		ErrorCode:    r.FormValue("error"),
+		Webhooks:     hooks,
+		Deliveries:   deliveries,
+		WebhookNames: map[int]string{},
+	}
+	for _, hook := range hooks {
+		webhookListData.WebhookNames[hook.Id] = hook.Name
+	}
+	buf := &bytes.Buffer{}
+	renderErr := webhookListTpl.Execute(buf, webhookListData)
+	if renderErr != nil {
+		util.Logger.Error().Err(renderErr).Msg("Error executing template")
+	}
+	_, renderErr = buf.WriteTo(w)
+	if renderErr != nil {
+		util.Logger.Error().Err(renderErr).Msg("Error writing template to buffer")
+	}
+}
+
+func webhookHandler(w http.ResponseWriter, r *http.Request) {
+	webhookId := r.FormValue("webhookId")
+	id, convErr := strconv.Atoi(webhookId)
+	if convErr != nil {
+		id = 0
+	}
+	webhookData := WebhookData{
+		ErrorCode:  r.FormValue("error"),
+		Webhook:    models.Webhook{Enabled: true},
+		Events:     webhooks.Events,
+		Subscribed: map[string]bool{},
+	}
+	if id > 0 {
+		hook, err := models.GetWebhookById(id)
+		if err != nil {
+			util.Logger.Error().Err(err).Msg("Error getting webhook by id")
+		}
+		webhookData.Webhook = hook
+		for _, event := range webhooks.Events {
+			webhookData.Subscribed[event] = hook.Events != "" && webhooks.Matches(hook.Events, event)
+		}
+		deliveries, err := models.GetWebhookDeliveries(id, webhookDeliveryCount)
+		if err != nil {
+			util.Logger.Error().Err(err).Msg("Error getting webhook deliveries")
+		}
+		webhookData.Deliveries = deliveries
+	}
+	buf := &bytes.Buffer{}
+	renderErr := webhookTpl.Execute(buf, webhookData)
+	if renderErr != nil {
+		util.Logger.Error().Err(renderErr).Msg("Error executing template")
+	}
+	_, renderErr = buf.WriteTo(w)
+	if renderErr != nil {
+		util.Logger.Error().Err(renderErr).Msg("Error writing template to buffer")
+	}
+}
+
+func webhookSaveHandler(w http.ResponseWriter, r *http.Request) {
+	webhookId := r.FormValue("webhookId")
+	id, convErr := strconv.Atoi(webhookId)
+	if convErr != nil {
+		id = 0
+	}
+	_ = r.FormValue("webhookName")
+	hook := models.Webhook{
+		Id:      id,
+		Name:    r.FormValue("webhookName"),
+		Url:     strings.TrimSpace(r.FormValue("webhookUrl")),
+		Secret:  r.FormValue("secret"),
+		Events:  strings.Join(r.Form["events"], ","),
+		Enabled: r.FormValue("enabled") == "true",
+	}
+	if parsed, err := url.Parse(hook.Url); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		http.Redirect(w, r, "/webhook?webhookId="+webhookId+"&error="+url.QueryEscape("The url must start with http:// or https://"), http.StatusSeeOther)
+		return
+	}
+	if id > 0 {
+		_, err := models.UpdateWebhook(hook)
+		if err != nil {
+			util.Logger.Error().Err(err).Msg("Error updating webhook")
+		}
+	} else {
+		_, err := models.AddWebhook(hook)
+		if err != nil {
+			util.Logger.Error().Err(err).Msg("Error adding webhook")
+		}
+	}
+	http.Redirect(w, r, "/webhooks", http.StatusSeeOther)
+}
+
+func webhookRemoveHandler(w http.ResponseWriter, r *http.Request) {
+	webhookId := r.FormValue("webhookId")
+	id, convErr := strconv.Atoi(webhookId)
+	if convErr != nil {
+		id = 0
+	}
+	if id > 0 {
+		_, err := models.DeleteWebhook(id)
+		if err != nil {
+			util.Logger.Error().Err(err).Msg("Error deleting webhook")
+		}
+	}
+	http.Redirect(w, r, "/webhooks", http.StatusSeeOther)
+}
+
+// webhookTestHandler sends a webhook.ping to one webhook and waits for the answer
+func webhookTestHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	webhookId := r.FormValue("webhookId")
+	id, convErr := strconv.Atoi(webhookId)
+	if convErr != nil {
+		id = 0
+	}
+	hook, err := models.GetWebhookById(id)
+	if err == nil && hook.Id == 0 {
+		err = errors.New("Save the webhook before testing it")
+	}
+	if err == nil {
+		err = webhooks.Test(hook)
+	}
+	if err != nil {
+		util.Logger.Error().Err(err).Msg("Error testing webhook")
+		http.Redirect(w, r, "/webhook?webhookId="+webhookId+"&error="+url.QueryEscape(err.Error()), http.StatusSeeOther)
+		return
+	}
+	http.Redirect(w, r, "/webhook?webhookId="+webhookId, http.StatusSeeOther)
+}
+
+// webhookRedeliverHandler sends a logged delivery again, the new attempt shows up as its own delivery
+func webhookRedeliverHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	deliveryId, convErr := strconv.Atoi(r.FormValue("deliveryId"))
+	if convErr != nil {
+		deliveryId = 0
+	}
+	returnTo := "/webhooks"
+	if webhookId := r.FormValue("webhookId"); webhookId != "" {
+		returnTo = "/webhook?webhookId=" + webhookId
+	}
+	if err := webhooks.Redeliver(deliveryId); err != nil {
+		util.Logger.Error().Err(err).Msg("Error redelivering webhook")
+		separator := "?"
+		if strings.Contains(returnTo, "?") {
+			separator = "&"
+		}
+		http.Redirect(w, r, returnTo+separator+"error="+url.QueryEscape(err.Error()), http.StatusSeeOther)
+		return
+	}
+	http.Redirect(w, r, returnTo, http.StatusSeeOther)
+}
+
+func articleTranslateHandler(w http.ResponseWriter, r *http.Request) {
+	articleId := r.FormValue("articleId")
+	id, convErr := strconv.Atoi(articleId)
+	if convErr != nil {
+		id = 0
+	}
+	if id > 0 {
+		translation, err := translateArticle(id, r.FormValue("language"), r.FormValue("publishStatus"))
+		if err != nil {
+			util.Logger.Error().Err(err).Msg("Error translating article")
+			http.Redirect(w, r, "/article?articleId="+articleId+"&error="+url.QueryEscape(err.Error()), http.StatusSeeOther)
+			return
+		}
+		articleId = strconv.Itoa(translation.Id)
+	}
+	http.Redirect(w, r, "/article?articleId="+articleId, http.StatusSeeOther)
+}
+
+// articleShareHandler shares an article on the SOCIAL_CHANNELS it hasn't been posted to yet, retrying failed ones
+func articleShareHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	articleId := r.FormValue("articleId")
+	id, convErr := strconv.Atoi(articleId)
+	if convErr != nil {
+		id = 0
+	}
+	if id > 0 {
+		article, err := models.GetArticleById(id)
+		if err == nil {
+			err = distributeArticle(article, nil)
+		}
+		if err != nil {
+			util.Logger.Error().Err(err).Msg("Error sharing article")
+			http.Redirect(w, r, "/article?articleId="+articleId+"&error="+url.QueryEscape(err.Error()), http.StatusSeeOther)
+			return
+		}
+	}
+	http.Redirect(w, r, "/article?articleId="+articleId, http.StatusSeeOther)
+}
+
+func reviewHandler(w http.ResponseWriter, r *http.Request) {
+	articles, err := models.GetReviewArticles()
+	if err != nil {
+		util.Logger.Error().Err(err).Msg("Error getting review articles")
+	}
+	reviewData := ReviewData{
+		ErrorCode: r.FormValue("error"),
+		Articles:  articles,
+	}
+	buf := &bytes.Buffer{}
+	renderErr := reviewTpl.Execute(buf, reviewData)
+	if renderErr != nil {
+		util.Logger.Error().Err(renderErr).Msg("Error executing template")
+	}
+	_, renderErr = buf.WriteTo(w)
+	if renderErr != nil {
+		util.Logger.Error().Err(renderErr).Msg("Error writing template to buffer")
+	}
+}
+
+func reviewActionHandler(w http.ResponseWriter, r *http.Request) {
+	id, convErr := strconv.Atoi(r.FormValue("articleId"))
+	if convErr != nil {
+		id = 0
+	}
+	reviewer := r.FormValue("reviewer")
+	comment := r.FormValue("reviewComment")
+	var err error
+	if id > 0 {
+		switch r.FormValue("action") {
+		case "approve":
+			err = approveArticle(id, reviewer, comment)
+		case "reject":
+			err = rejectArticle(id, reviewer, comment)
+		case "rewrite":
+			err = rewriteArticle(id, reviewer, comment)
+		}
+	}
+	if err != nil {
+		util.Logger.Error().Err(err).Msg("Error reviewing article")
+		http.Redirect(w, r, "/review?error="+url.QueryEscape(err.Error()), http.StatusSeeOther)
+		return
+	}
+	http.Redirect(w, r, "/review", http.StatusSeeOther)
+}
+
+func imagePickHandler(w http.ResponseWriter, r *http.Request) {
+	id, convErr := strconv.Atoi(r.FormValue("articleId"))
+	if convErr != nil {
+		id = 0
+	}
+	imagePickData := ImagePickData{
+		ErrorCode: r.FormValue("error"),
+	}
+	article, err := models.GetArticleById(id)
+	if err != nil {
+		util.Logger.Error().Err(err).Msg("Error getting article by id")
+	}
+	imagePickData.Article = article
+	candidates, err := models.GetImageCandidates(id)
+	if err != nil {
+		util.Logger.Error().Err(err).Msg("Error getting image candidates")
+	}
+	imagePickData.Candidates = candidates
+	buf := &bytes.Buffer{}
+	renderErr := imagePickTpl.Execute(buf, imagePickData)
+	if renderErr != nil {
+		util.Logger.Error().Err(renderErr).Msg("Error executing template")
+	}
+	_, renderErr = buf.WriteTo(w)
+	if renderErr != nil {
+		util.Logger.Error().Err(renderErr).Msg("Error writing template to buffer")
+	}
+}
+
+func imagePickSaveHandler(w http.ResponseWriter, r *http.Request) {
+	articleId := r.FormValue("articleId")
+	id, convErr := strconv.Atoi(articleId)
+	if convErr != nil {
+		id = 0
+	}
+	candidateId, convErr := strconv.Atoi(r.FormValue("candidateId"))
+	if convErr != nil {
+		candidateId = 0
+	}
+	err := pickArticleImage(id, candidateId)
+	if err != nil {
+		util.Logger.Error().Err(err).Msg("Error picking article image")
+		http.Redirect(w, r, "/imagePick?articleId="+articleId+"&error="+url.QueryEscape(err.Error()), http.StatusSeeOther)
+		return
+	}
+	http.Redirect(w, r, "/article?articleId="+articleId, http.StatusSeeOther)
+}
+
+func mediaListHandler(w http.ResponseWriter, r *http.Request) {
+	media, err := models.GetMedia()
+	if err != nil {
+		util.Logger.Error().Err(err).Msg("Error getting media")
+	}
+	mediaListData := MediaListData{
+		ErrorCode: r.FormValue("error"),
+		Media:     media,
+	}
+	buf := &bytes.Buffer{}
+	renderErr := mediaListTpl.Execute(buf, mediaListData)
+	if renderErr != nil {
+		util.Logger.Error().Err(renderErr).Msg("Error executing template")
+	}
+	_, renderErr = buf.WriteTo(w)
+	if renderErr != nil {
+		util.Logger.Error().Err(renderErr).Msg("Error writing template to buffer")
+	}
+}
+
+func imageQueueHandler(w http.ResponseWriter, r *http.Request) {
+	imageQueueData := ImageQueueData{
+		ErrorCode: r.FormValue("error"),
+		Jobs:      imageQueue.Jobs(),
+	}
+	buf := &bytes.Buffer{}
+	renderErr := imageQueueTpl.Execute(buf, imageQueueData)
+	if renderErr != nil {
+		util.Logger.Error().Err(renderErr).Msg("Error executing template")
+	}
+	_, renderErr = buf.WriteTo(w)
+	if renderErr != nil {
+		util.Logger.Error().Err(renderErr).Msg("Error writing template to buffer")
+	}
+}
+
+func mediaFileHandler(w http.ResponseWriter, r *http.Request) {
+	id, convErr := strconv.Atoi(r.FormValue("mediaId"))
+	if convErr != nil {
+		id = 0
+	}
+	media, err := models.GetMediaById(id)
+	if err != nil || media.Id == 0 {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", media.MimeType)
+	http.ServeFile(w, r, filepath.Join(mediaDir, media.FileName))
+}
+
+func mediaDeleteHandler(w http.ResponseWriter, r *http.Request) {
+	id, convErr := strconv.Atoi(r.FormValue("mediaId"))
+	if convErr != nil {
+		id = 0
+	}
+	if id > 0 {
+		err := deleteFromLibrary(id)
+		if err != nil {
+			util.Logger.Error().Err(err).Msg("Error deleting library image")
+			http.Redirect(w, r, "/media?error="+url.QueryEscape(err.Error()), http.StatusSeeOther)
+			return
+		}
+	}
+	http.Redirect(w, r, "/media", http.StatusSeeOther)
+}
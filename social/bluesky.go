@@ -0,0 +1,152 @@
+package social
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// bluesky posts to the account BLUESKY_HANDLE with an app password, through BLUESKY_URL or bsky.social
+type bluesky struct{}
+
+func init() {
+	Register(bluesky{})
+}
+
+func (bluesky) Name() string {
+	return "bluesky"
+}
+
+func (bluesky) Label() string {
+	return "Bluesky"
+}
+
+func (bluesky) Fields() []SettingField {
+	return []SettingField{
+		{Name: "BLUESKY_URL", Type: "text", Help: "The account's PDS, leave empty for https://bsky.social"},
+		{Name: "BLUESKY_HANDLE", Type: "text", Help: "For example yourblog.bsky.social"},
+		{Name: "BLUESKY_APP_PASSWORD", Type: "password", Help: "Create one under Settings > App Passwords, not your account password."},
+	}
+}
+
+func (bluesky) MaxLength() int {
+	return 300
+}
+
+type blueskySession struct {
+	AccessJwt string `json:"accessJwt"`
+	Did       string `json:"did"`
+}
+
+type blueskyBlob struct {
+	Blob json.RawMessage `json:"blob"`
+}
+
+type blueskyRecord struct {
+	Uri string `json:"uri"`
+	Cid string `json:"cid"`
+}
+
+func (bluesky) Post(ctx context.Context, settings map[string]string, msg Message) (Result, error) {
+	if settings["BLUESKY_HANDLE"] == "" || settings["BLUESKY_APP_PASSWORD"] == "" {
+		return Result{}, errors.New("BLUESKY_HANDLE and BLUESKY_APP_PASSWORD must be set")
+	}
+	pds := baseUrl(settings, "BLUESKY_URL", "https://bsky.social")
+
+	var session blueskySession
+	err := sendJSON(ctx, pds+"/xrpc/com.atproto.server.createSession", map[string]string{}, map[string]string{
+		"identifier": strings.TrimPrefix(settings["BLUESKY_HANDLE"], "@"),
+		"password":   settings["BLUESKY_APP_PASSWORD"],
+	}, &session)
+	if err != nil {
+		return Result{}, errors.New("Error logging in: " + err.Error())
+	}
+	auth := "Bearer " + session.AccessJwt
+
+	var thumb json.RawMessage
+	if len(msg.Image) > 0 {
+		var uploaded blueskyBlob
+		err = send(ctx, pds+"/xrpc/com.atproto.repo.uploadBlob", map[string]string{"Authorization": auth, "Content-Type": msg.ImageMime}, bytes.NewReader(msg.Image), &uploaded)
+		if err != nil {
+			return Result{}, errors.New("Error uploading image: " + err.Error())
+		}
+		thumb = uploaded.Blob
+	}
+
+	post := map[string]interface{}{
+		"$type":     "app.bsky.feed.post",
+		"text":      msg.Text,
+		"createdAt": time.Now().UTC().Format(time.RFC3339),
+	}
+	if facets := blueskyFacets(msg.Text, msg.Link); len(facets) > 0 {
+		post["facets"] = facets
+	}
+	//A link card shows the featured image as its thumbnail, without a link the image is attached on its own
+	if msg.Link != "" {
+		external := map[string]interface{}{
+			"uri":         msg.Link,
+			"title":       msg.Title,
+			"description": msg.Description,
+		}
+		if thumb != nil {
+			external["thumb"] = thumb
+		}
+		post["embed"] = map[string]interface{}{"$type": "app.bsky.embed.external", "external": external}
+	} else if thumb != nil {
+		post["embed"] = map[string]interface{}{
+			"$type":  "app.bsky.embed.images",
+			"images": []map[string]interface{}{{"alt": msg.ImageAlt, "image": thumb}},
+		}
+	}
+
+	var record blueskyRecord
+	err = sendJSON(ctx, pds+"/xrpc/com.atproto.repo.createRecord", map[string]string{"Authorization": auth}, map[string]interface{}{
+		"repo":       session.Did,
+		"collection": "app.bsky.feed.post",
+		"record":     post,
+	}, &record)
+	if err != nil {
+		return Result{}, err
+	}
+	result := Result{Id: record.Uri}
+	//Record uris are at://<did>/app.bsky.feed.post/<rkey>
+	if i := strings.LastIndex(record.Uri, "/"); i >= 0 {
+		result.Url = "https://bsky.app/profile/" + session.Did + "/post/" + record.Uri[i+1:]
+	}
+	return result, nil
+}
+
+// blueskyFacets marks the link and hashtags in the text, Bluesky only makes them clickable when told their byte ranges
+func blueskyFacets(text string, link string) []map[string]interface{} {
+	var facets []map[string]interface{}
+	facet := func(start int, end int, feature map[string]interface{}) {
+		facets = append(facets, map[string]interface{}{
+			"index":    map[string]int{"byteStart": start, "byteEnd": end},
+			"features": []map[string]interface{}{feature},
+		})
+	}
+	if link != "" {
+		if start := strings.Index(text, link); start >= 0 {
+			facet(start, start+len(link), map[string]interface{}{"$type": "app.bsky.richtext.facet#link", "uri": link})
+		}
+	}
+	for start := 0; start < len(text); start++ {
+		if text[start] != '#' || (start > 0 && !unicode.IsSpace(rune(text[start-1]))) {
+			continue
+		}
+		end := start + 1
+		for end < len(text) && !unicode.IsSpace(rune(text[end])) {
+			end++
+		}
+		tag := strings.TrimRight(text[start+1:end], ".,!?:;")
+		if tag != "" {
+			facet(start, start+1+len(tag), map[string]interface{}{"$type": "app.bsky.richtext.facet#tag", "tag": tag})
+		}
+		start = end
+	}
+	return facets
+}
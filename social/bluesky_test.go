@@ -0,0 +1,109 @@
+package social
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestBlueskyPost(t *testing.T) {
+	server, requests := fakeServer(t, map[string]string{
+		"/xrpc/com.atproto.server.createSession": `{"accessJwt":"jwt","did":"did:plc:abc"}`,
+		"/xrpc/com.atproto.repo.uploadBlob":      `{"blob":{"$type":"blob","ref":{"$link":"bafy"},"mimeType":"image/png","size":3}}`,
+		"/xrpc/com.atproto.repo.createRecord":    `{"uri":"at://did:plc:abc/app.bsky.feed.post/3k","cid":"c1"}`,
+	})
+	settings := map[string]string{"BLUESKY_URL": server.URL, "BLUESKY_HANDLE": "@blog.example", "BLUESKY_APP_PASSWORD": "app-pass"}
+	link := "https://blog.example/post"
+	msg := Message{Title: "Title", Description: "Desc", Text: "New post " + link + " #Go", Link: link, Image: []byte("png"), ImageMime: "image/png"}
+
+	result, err := bluesky{}.Post(context.Background(), settings, msg)
+	if err != nil {
+		t.Fatalf("Post() error: %v", err)
+	}
+	if result.Id != "at://did:plc:abc/app.bsky.feed.post/3k" || result.Url != "https://bsky.app/profile/did:plc:abc/post/3k" {
+		t.Errorf("Post() = %+v", result)
+	}
+
+	got := requests()
+	if len(got) != 3 {
+		t.Fatalf("got %d requests, want 3", len(got))
+	}
+	session, upload, record := got[0], got[1], got[2]
+
+	var login map[string]string
+	if err := json.Unmarshal(session.Body, &login); err != nil {
+		t.Fatalf("session body: %v", err)
+	}
+	if login["identifier"] != "blog.example" || login["password"] != "app-pass" {
+		t.Errorf("session body = %v", login)
+	}
+	if auth := session.Header.Get("Authorization"); auth != "" {
+		t.Errorf("session Authorization = %q, want none", auth)
+	}
+
+	if upload.Path != "/xrpc/com.atproto.repo.uploadBlob" || upload.Header.Get("Authorization") != "Bearer jwt" ||
+		upload.Header.Get("Content-Type") != "image/png" || string(upload.Body) != "png" {
+		t.Errorf("upload to %s with Authorization %q, Content-Type %q and body %q", upload.Path,
+			upload.Header.Get("Authorization"), upload.Header.Get("Content-Type"), upload.Body)
+	}
+
+	if record.Path != "/xrpc/com.atproto.repo.createRecord" || record.Header.Get("Authorization") != "Bearer jwt" {
+		t.Errorf("record to %s with Authorization %q", record.Path, record.Header.Get("Authorization"))
+	}
+	var body struct {
+		Repo       string `json:"repo"`
+		Collection string `json:"collection"`
+		Record     struct {
+			Text   string `json:"text"`
+			Facets []struct {
+				Index struct {
+					ByteStart int `json:"byteStart"`
+					ByteEnd   int `json:"byteEnd"`
+				} `json:"index"`
+				Features []map[string]string `json:"features"`
+			} `json:"facets"`
+			Embed struct {
+				Type     string `json:"$type"`
+				External struct {
+					Uri   string          `json:"uri"`
+					Title string          `json:"title"`
+					Thumb json.RawMessage `json:"thumb"`
+				} `json:"external"`
+			} `json:"embed"`
+		} `json:"record"`
+	}
+	if err := json.Unmarshal(record.Body, &body); err != nil {
+		t.Fatalf("record body: %v", err)
+	}
+	if body.Repo != "did:plc:abc" || body.Collection != "app.bsky.feed.post" || body.Record.Text != msg.Text {
+		t.Errorf("record body = %+v", body)
+	}
+	if body.Record.Embed.Type != "app.bsky.embed.external" || body.Record.Embed.External.Uri != link ||
+		body.Record.Embed.External.Title != "Title" || len(body.Record.Embed.External.Thumb) == 0 {
+		t.Errorf("embed = %+v", body.Record.Embed)
+	}
+	if len(body.Record.Facets) != 2 {
+		t.Fatalf("got %d facets, want 2", len(body.Record.Facets))
+	}
+	for i, want := range []string{link, "#Go"} {
+		facet := body.Record.Facets[i]
+		if got := msg.Text[facet.Index.ByteStart:facet.Index.ByteEnd]; got != want {
+			t.Errorf("facet %d covers %q, want %q", i, got, want)
+		}
+	}
+}
+
+func TestBlueskyFacets(t *testing.T) {
+	text := "Café news #Go, #open_source and a#notatag https://blog.example"
+	facets := blueskyFacets(text, "https://blog.example")
+	want := []string{"https://blog.example", "#Go", "#open_source"}
+	if len(facets) != len(want) {
+		t.Fatalf("got %d facets, want %d", len(facets), len(want))
+	}
+	for i, facet := range facets {
+		index := facet["index"].(map[string]int)
+		if got := text[index["byteStart"]:index["byteEnd"]]; got != want[i] {
+			t.Errorf("facet %d covers %q, want %q", i, got, want[i])
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package social
+
+import (
+	"context"
+	"errors"
+)
+
+// mastodon posts a status with MASTODON_TOKEN to the instance at MASTODON_URL
+type mastodon struct{}
+
+func init() {
+	Register(mastodon{})
+}
+
+func (mastodon) Name() string {
+	return "mastodon"
+}
+
+func (mastodon) Label() string {
+	return "Mastodon"
+}
+
+func (mastodon) Fields() []SettingField {
+	return []SettingField{
+		{Name: "MASTODON_URL", Type: "text", Help: "Your instance, for example https://mastodon.social"},
+		{Name: "MASTODON_TOKEN", Type: "password", Help: "Access token of an application with the write:statuses and write:media scopes, from Preferences > Development."},
+	}
+}
+
+func (mastodon) MaxLength() int {
+	return 500
+}
+
+// LinkLength is what Mastodon counts every link as, however long it is
+func (mastodon) LinkLength() int {
+	return 23
+}
+
+type mastodonMedia struct {
+	Id string `json:"id"`
+}
+
+type mastodonStatus struct {
+	Id  string `json:"id"`
+	Url string `json:"url"`
+}
+
+func (mastodon) Post(ctx context.Context, settings map[string]string, msg Message) (Result, error) {
+	if settings["MASTODON_URL"] == "" || settings["MASTODON_TOKEN"] == "" {
+		return Result{}, errors.New("MASTODON_URL and MASTODON_TOKEN must be set")
+	}
+	instance := baseUrl(settings, "MASTODON_URL", "")
+	headers := map[string]string{"Authorization": "Bearer " + settings["MASTODON_TOKEN"]}
+
+	status := map[string]interface{}{"status": msg.Text}
+	if len(msg.Image) > 0 {
+		body, contentType, err := imageForm("file", msg, map[string]string{"description": msg.ImageAlt})
+		if err != nil {
+			return Result{}, err
+		}
+		var media mastodonMedia
+		err = send(ctx, instance+"/api/v2/media", map[string]string{"Authorization": headers["Authorization"], "Content-Type": contentType}, body, &media)
+		if err != nil {
+			return Result{}, errors.New("Error uploading image: " + err.Error())
+		}
+		status["media_ids"] = []string{media.Id}
+	}
+	var posted mastodonStatus
+	if err := sendJSON(ctx, instance+"/api/v1/statuses", headers, status, &posted); err != nil {
+		return Result{}, err
+	}
+	return Result{Id: posted.Id, Url: posted.Url}, nil
+}
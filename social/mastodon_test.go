@@ -0,0 +1,67 @@
+package social
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestMastodonPost(t *testing.T) {
+	server, requests := fakeServer(t, map[string]string{
+		"/api/v2/media":    `{"id":"m1"}`,
+		"/api/v1/statuses": `{"id":"s1","url":"https://mastodon.example/@blog/s1"}`,
+	})
+	settings := map[string]string{"MASTODON_URL": server.URL + "/", "MASTODON_TOKEN": "secret"}
+	msg := Message{Text: "New post https://blog.example/post", Image: []byte("png"), ImageMime: "image/png", ImageAlt: "A cat"}
+
+	result, err := mastodon{}.Post(context.Background(), settings, msg)
+	if err != nil {
+		t.Fatalf("Post() error: %v", err)
+	}
+	if result.Id != "s1" || result.Url != "https://mastodon.example/@blog/s1" {
+		t.Errorf("Post() = %+v", result)
+	}
+
+	got := requests()
+	if len(got) != 2 {
+		t.Fatalf("got %d requests, want 2", len(got))
+	}
+	upload, status := got[0], got[1]
+	if upload.Path != "/api/v2/media" || status.Path != "/api/v1/statuses" {
+		t.Fatalf("paths = %s, %s", upload.Path, status.Path)
+	}
+	for _, r := range got {
+		if r.Method != "POST" || r.Header.Get("Authorization") != "Bearer secret" {
+			t.Errorf("%s sent %s with Authorization %q", r.Path, r.Method, r.Header.Get("Authorization"))
+		}
+	}
+	if !strings.HasPrefix(upload.Header.Get("Content-Type"), "multipart/form-data") {
+		t.Errorf("upload Content-Type = %q", upload.Header.Get("Content-Type"))
+	}
+	if !strings.Contains(string(upload.Body), `filename="image.png"`) || !strings.Contains(string(upload.Body), "A cat") {
+		t.Errorf("upload body is missing the image or its description: %s", upload.Body)
+	}
+
+	var body struct {
+		Status   string   `json:"status"`
+		MediaIds []string `json:"media_ids"`
+	}
+	if err := json.Unmarshal(status.Body, &body); err != nil {
+		t.Fatalf("status body: %v", err)
+	}
+	if body.Status != msg.Text || len(body.MediaIds) != 1 || body.MediaIds[0] != "m1" {
+		t.Errorf("status body = %+v", body)
+	}
+}
+
+func TestMastodonPostErrors(t *testing.T) {
+	if _, err := (mastodon{}).Post(context.Background(), map[string]string{}, Message{Text: "hi"}); err == nil {
+		t.Error("Post() without settings succeeded")
+	}
+	server, _ := fakeServer(t, map[string]string{})
+	settings := map[string]string{"MASTODON_URL": server.URL, "MASTODON_TOKEN": "secret"}
+	if _, err := (mastodon{}).Post(context.Background(), settings, Message{Text: "hi"}); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("Post() error = %v, want the 404", err)
+	}
+}
@@ -0,0 +1,232 @@
+package social
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Message is what gets shared about a published article, Text is already rendered from the social-post template
+type Message struct {
+	Title       string
+	Description string
+	Text        string
+	Link        string
+	Image       []byte
+	ImageMime   string
+	ImageAlt    string
+}
+
+// Result identifies the post a channel made, Url is empty when the channel has no page for it
+type Result struct {
+	Id  string
+	Url string
+}
+
+// SettingField describes one setting a channel reads, used to render it on the settings page.
+// Type is text, password, textarea or select.
+type SettingField struct {
+	Name    string
+	Type    string
+	Help    string
+	Options []string
+}
+
+// Channel is implemented by each place articles are shared to. Name is the value listed in SOCIAL_CHANNELS.
+// MaxLength is the longest text the channel accepts, 0 for no limit.
+type Channel interface {
+	Name() string
+	Label() string
+	Fields() []SettingField
+	MaxLength() int
+	Post(ctx context.Context, settings map[string]string, msg Message) (Result, error)
+}
+
+// LinkCounter is implemented by channels that count every link as the same number of characters
+type LinkCounter interface {
+	LinkLength() int
+}
+
+var channels = map[string]Channel{}
+
+// Register makes a channel available to SOCIAL_CHANNELS, channels register themselves from init
+func Register(channel Channel) {
+	channels[channel.Name()] = channel
+}
+
+func Get(name string) (Channel, bool) {
+	channel, ok := channels[name]
+	return channel, ok
+}
+
+// All returns the registered channels ordered by name
+func All() []Channel {
+	all := make([]Channel, 0, len(channels))
+	for _, channel := range channels {
+		all = append(all, channel)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		return all[i].Name() < all[j].Name()
+	})
+	return all
+}
+
+// Fit shortens text to limit characters, cutting the words before the link so the link itself survives.
+// linkLength is how many characters the channel counts a link as, 0 to count it as written.
+func Fit(text string, link string, limit int, linkLength int) string {
+	text = strings.TrimSpace(text)
+	if limit <= 0 {
+		return text
+	}
+	before, after, hasLink := "", "", false
+	if link != "" {
+		before, after, hasLink = strings.Cut(text, link)
+	}
+	if !hasLink {
+		return cutWords(text, limit)
+	}
+	counted := utf8.RuneCountInString(link)
+	if linkLength > 0 {
+		counted = linkLength
+	}
+	if utf8.RuneCountInString(before)+counted+utf8.RuneCountInString(after) <= limit {
+		return text
+	}
+	//Whatever follows the link is usually hashtags, keep them only when there is room
+	room := limit - counted - utf8.RuneCountInString(after)
+	if room < 2 {
+		after = ""
+		room = limit - counted
+	}
+	if room < 2 {
+		return link
+	}
+	return cutWords(strings.TrimSpace(before), room-1) + " " + link + after
+}
+
+// cutWords trims text to limit characters at a word boundary, ending it with an ellipsis
+func cutWords(text string, limit int) string {
+	if utf8.RuneCountInString(text) <= limit {
+		return text
+	}
+	runes := []rune(text)
+	cut := string(runes[:limit-1])
+	if i := strings.LastIndexAny(cut, " \n"); i > 0 {
+		cut = cut[:i]
+	}
+	return strings.TrimSpace(cut) + "…"
+}
+
+// Hashtags turns comma separated keywords into camel cased hashtags, dropping anything but letters and digits
+func Hashtags(keywords string) string {
+	var tags []string
+	for _, keyword := range strings.Split(keywords, ",") {
+		tag := ""
+		for _, word := range strings.FieldsFunc(keyword, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		}) {
+			first, size := utf8.DecodeRuneInString(word)
+			tag += string(unicode.ToUpper(first)) + word[size:]
+		}
+		if tag != "" {
+			tags = append(tags, "#"+tag)
+		}
+	}
+	return strings.Join(tags, " ")
+}
+
+// errNotJSON is returned when a request succeeded but its response couldn't be read
+var errNotJSON = errors.New("error parsing response")
+
+// sendJSON posts body as JSON with the given headers and decodes a 2xx JSON response into result, result may be nil
+func sendJSON(ctx context.Context, postUrl string, headers map[string]string, body interface{}, result interface{}) error {
+	jsonData, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("error encoding request: %w", err)
+	}
+	headers["Content-Type"] = "application/json"
+	return send(ctx, postUrl, headers, bytes.NewReader(jsonData), result)
+}
+
+// send posts body with the given headers and decodes a 2xx JSON response into result, result may be nil
+func send(ctx context.Context, postUrl string, headers map[string]string, body io.Reader, result interface{}) error {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, postUrl, body)
+	if err != nil {
+		return fmt.Errorf("error making request: %w", err)
+	}
+	for k, v := range headers {
+		httpReq.Header.Set(k, v)
+	}
+	resp, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("error sending request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("error status code %d: %s", resp.StatusCode, string(respBody))
+	}
+	if result == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("%w: %v", errNotJSON, err)
+	}
+	return nil
+}
+
+// imageForm builds a multipart form holding the image under fileField along with any plain fields
+func imageForm(fileField string, msg Message, fields map[string]string) (*bytes.Buffer, string, error) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	for k, v := range fields {
+		if err := writer.WriteField(k, v); err != nil {
+			return nil, "", err
+		}
+	}
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, fileField, "image"+extension(msg.ImageMime)))
+	header.Set("Content-Type", msg.ImageMime)
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		return nil, "", err
+	}
+	if _, err := part.Write(msg.Image); err != nil {
+		return nil, "", err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, "", err
+	}
+	return body, writer.FormDataContentType(), nil
+}
+
+func extension(mimeType string) string {
+	switch mimeType {
+	case "image/png":
+		return ".png"
+	case "image/webp":
+		return ".webp"
+	case "image/gif":
+		return ".gif"
+	}
+	return ".jpg"
+}
+
+// baseUrl returns the setting, or fallback when it is empty, without a trailing slash
+func baseUrl(settings map[string]string, name string, fallback string) string {
+	value := strings.TrimSpace(settings[name])
+	if value == "" {
+		value = fallback
+	}
+	return strings.TrimRight(value, "/")
+}
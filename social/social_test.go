@@ -0,0 +1,102 @@
+package social
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"unicode/utf8"
+)
+
+type recordedRequest struct {
+	Method string
+	Path   string
+	Header http.Header
+	Body   []byte
+}
+
+// fakeServer answers each path with its canned JSON response and records every request it receives
+func fakeServer(t *testing.T, responses map[string]string) (*httptest.Server, func() []recordedRequest) {
+	t.Helper()
+	var mu sync.Mutex
+	var requests []recordedRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		requests = append(requests, recordedRequest{Method: r.Method, Path: r.URL.Path, Header: r.Header.Clone(), Body: body})
+		mu.Unlock()
+		response, ok := responses[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, response)
+	}))
+	t.Cleanup(server.Close)
+	return server, func() []recordedRequest {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]recordedRequest(nil), requests...)
+	}
+}
+
+func TestFit(t *testing.T) {
+	link := "https://example.com/a-rather-long-article-slug"
+	tests := []struct {
+		name       string
+		text       string
+		link       string
+		limit      int
+		linkLength int
+		want       string
+	}{
+		{"no limit", "  some text  ", "", 0, 0, "some text"},
+		{"fits", "Short post " + link, link, 100, 0, "Short post " + link},
+		{"no link cuts at a word", "one two three four", "", 10, 0, "one two…"},
+		{"link counted as written", "Read all about the new release " + link, link, 60, 0, "Read all…" + " " + link},
+		{"link counted as fixed length", "Read all about the new release " + link, link, 40, 23, "Read all about…" + " " + link},
+		{"fits with fixed link length", "Read all about it " + link, link, 41, 23, "Read all about it " + link},
+		{"hashtags kept when there is room", "Read all about the new release " + link + " #Go", link, 44, 23, "Read all about…" + " " + link + " #Go"},
+		{"hashtags dropped when there isn't", "Read all about it " + link + " #Go #Blogging", link, 36, 23, "Read all…" + " " + link},
+		{"only the link fits", "Read all about it " + link, link, 24, 23, link},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Fit(tt.text, tt.link, tt.limit, tt.linkLength)
+			if got != tt.want {
+				t.Errorf("Fit() = %q, want %q", got, tt.want)
+			}
+			if tt.limit > 0 && tt.linkLength == 0 && utf8.RuneCountInString(got) > tt.limit {
+				t.Errorf("Fit() is %d characters, limit %d", utf8.RuneCountInString(got), tt.limit)
+			}
+			if tt.link != "" && !strings.Contains(got, tt.link) {
+				t.Errorf("Fit() lost the link: %q", got)
+			}
+		})
+	}
+}
+
+func TestHashtags(t *testing.T) {
+	tests := []struct {
+		name     string
+		keywords string
+		want     string
+	}{
+		{"empty", "", ""},
+		{"single word", "golang", "#Golang"},
+		{"camel cases words", "machine learning, open source", "#MachineLearning #OpenSource"},
+		{"drops punctuation", "c++, node.js, rock 'n' roll", "#C #NodeJs #RockNRoll"},
+		{"keeps digits and accents", "web3, café culture", "#Web3 #CaféCulture"},
+		{"skips empty keywords", "one,, ,two", "#One #Two"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Hashtags(tt.keywords); got != tt.want {
+				t.Errorf("Hashtags(%q) = %q, want %q", tt.keywords, got, tt.want)
+			}
+		})
+	}
+}
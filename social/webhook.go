@@ -0,0 +1,73 @@
+package social
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+)
+
+// webhook posts the message as JSON to SOCIAL_WEBHOOK_URL, for services like Zapier, IFTTT or n8n to pass along
+type webhook struct{}
+
+func init() {
+	Register(webhook{})
+}
+
+func (webhook) Name() string {
+	return "webhook"
+}
+
+func (webhook) Label() string {
+	return "Webhook"
+}
+
+func (webhook) Fields() []SettingField {
+	return []SettingField{
+		{Name: "SOCIAL_WEBHOOK_URL", Type: "text", Help: "Receives a JSON POST with title, description, text, link, image_alt, image_mime and image (base64)."},
+	}
+}
+
+func (webhook) MaxLength() int {
+	return 0
+}
+
+type webhookPayload struct {
+	Channel     string `json:"channel"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Text        string `json:"text"`
+	Link        string `json:"link"`
+	ImageAlt    string `json:"image_alt,omitempty"`
+	ImageMime   string `json:"image_mime,omitempty"`
+	Image       string `json:"image,omitempty"`
+}
+
+// webhookResponse is read when the receiver answers with JSON, receivers that don't are still a success
+type webhookResponse struct {
+	Id  string `json:"id"`
+	Url string `json:"url"`
+}
+
+func (webhook) Post(ctx context.Context, settings map[string]string, msg Message) (Result, error) {
+	if settings["SOCIAL_WEBHOOK_URL"] == "" {
+		return Result{}, errors.New("SOCIAL_WEBHOOK_URL must be set")
+	}
+	payload := webhookPayload{
+		Channel:     "webhook",
+		Title:       msg.Title,
+		Description: msg.Description,
+		Text:        msg.Text,
+		Link:        msg.Link,
+	}
+	if len(msg.Image) > 0 {
+		payload.ImageAlt = msg.ImageAlt
+		payload.ImageMime = msg.ImageMime
+		payload.Image = base64.StdEncoding.EncodeToString(msg.Image)
+	}
+	var response webhookResponse
+	err := sendJSON(ctx, settings["SOCIAL_WEBHOOK_URL"], map[string]string{}, payload, &response)
+	if err != nil && !errors.Is(err, errNotJSON) {
+		return Result{}, err
+	}
+	return Result{Id: response.Id, Url: response.Url}, nil
+}
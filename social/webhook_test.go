@@ -0,0 +1,55 @@
+package social
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+)
+
+func TestWebhookPost(t *testing.T) {
+	server, requests := fakeServer(t, map[string]string{
+		"/hook": `{"id":"42","url":"https://social.example/42"}`,
+	})
+	settings := map[string]string{"SOCIAL_WEBHOOK_URL": server.URL + "/hook"}
+	msg := Message{Title: "Title", Description: "Desc", Text: "Text", Link: "https://blog.example/post", Image: []byte("jpg"), ImageMime: "image/jpeg", ImageAlt: "Alt"}
+
+	result, err := webhook{}.Post(context.Background(), settings, msg)
+	if err != nil {
+		t.Fatalf("Post() error: %v", err)
+	}
+	if result.Id != "42" || result.Url != "https://social.example/42" {
+		t.Errorf("Post() = %+v", result)
+	}
+
+	got := requests()
+	if len(got) != 1 {
+		t.Fatalf("got %d requests, want 1", len(got))
+	}
+	if got[0].Path != "/hook" || got[0].Header.Get("Content-Type") != "application/json" {
+		t.Errorf("request to %s with Content-Type %q", got[0].Path, got[0].Header.Get("Content-Type"))
+	}
+	if auth := got[0].Header.Get("Authorization"); auth != "" {
+		t.Errorf("Authorization = %q, want none", auth)
+	}
+	var payload webhookPayload
+	if err := json.Unmarshal(got[0].Body, &payload); err != nil {
+		t.Fatalf("body: %v", err)
+	}
+	want := webhookPayload{Channel: "webhook", Title: "Title", Description: "Desc", Text: "Text", Link: msg.Link,
+		ImageAlt: "Alt", ImageMime: "image/jpeg", Image: base64.StdEncoding.EncodeToString(msg.Image)}
+	if payload != want {
+		t.Errorf("body = %+v, want %+v", payload, want)
+	}
+}
+
+func TestWebhookPostWithoutJSONResponse(t *testing.T) {
+	server, _ := fakeServer(t, map[string]string{"/hook": "OK"})
+	result, err := webhook{}.Post(context.Background(), map[string]string{"SOCIAL_WEBHOOK_URL": server.URL + "/hook"}, Message{Text: "Text"})
+	if err != nil {
+		t.Fatalf("Post() error: %v", err)
+	}
+	if result != (Result{}) {
+		t.Errorf("Post() = %+v, want an empty result", result)
+	}
+}
@@ -0,0 +1,144 @@
+package social
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// x posts a tweet as the user behind X_ACCESS_TOKEN, signed with OAuth 1.0a
+type x struct{}
+
+func init() {
+	Register(x{})
+}
+
+func (x) Name() string {
+	return "x"
+}
+
+func (x) Label() string {
+	return "X (Twitter)"
+}
+
+func (x) Fields() []SettingField {
+	return []SettingField{
+		{Name: "X_API_KEY", Type: "password", Help: "Consumer key of an app with Read and Write permissions, from the X developer portal."},
+		{Name: "X_API_SECRET", Type: "password"},
+		{Name: "X_ACCESS_TOKEN", Type: "password", Help: "Access token and secret generated for your account after setting Read and Write."},
+		{Name: "X_ACCESS_SECRET", Type: "password"},
+		{Name: "X_API_URL", Type: "text", Help: "Leave empty for https://api.twitter.com"},
+		{Name: "X_UPLOAD_URL", Type: "text", Help: "Leave empty for https://upload.twitter.com"},
+	}
+}
+
+func (x) MaxLength() int {
+	return 280
+}
+
+// LinkLength is what X counts every link as, whatever its real length, since it wraps them in t.co
+func (x) LinkLength() int {
+	return 23
+}
+
+type xMedia struct {
+	MediaIdString string `json:"media_id_string"`
+}
+
+type xTweet struct {
+	Data struct {
+		Id string `json:"id"`
+	} `json:"data"`
+}
+
+func (x) Post(ctx context.Context, settings map[string]string, msg Message) (Result, error) {
+	if settings["X_API_KEY"] == "" || settings["X_API_SECRET"] == "" || settings["X_ACCESS_TOKEN"] == "" || settings["X_ACCESS_SECRET"] == "" {
+		return Result{}, errors.New("X_API_KEY, X_API_SECRET, X_ACCESS_TOKEN and X_ACCESS_SECRET must be set")
+	}
+	tweet := map[string]interface{}{"text": msg.Text}
+	if len(msg.Image) > 0 {
+		uploadUrl := baseUrl(settings, "X_UPLOAD_URL", "https://upload.twitter.com") + "/1.1/media/upload.json"
+		body, contentType, err := imageForm("media", msg, nil)
+		if err != nil {
+			return Result{}, err
+		}
+		var media xMedia
+		err = send(ctx, uploadUrl, map[string]string{"Authorization": oauthHeader(settings, http.MethodPost, uploadUrl), "Content-Type": contentType}, body, &media)
+		if err != nil {
+			return Result{}, errors.New("Error uploading image: " + err.Error())
+		}
+		tweet["media"] = map[string][]string{"media_ids": {media.MediaIdString}}
+	}
+	tweetUrl := baseUrl(settings, "X_API_URL", "https://api.twitter.com") + "/2/tweets"
+	var posted xTweet
+	err := sendJSON(ctx, tweetUrl, map[string]string{"Authorization": oauthHeader(settings, http.MethodPost, tweetUrl)}, tweet, &posted)
+	if err != nil {
+		return Result{}, err
+	}
+	return Result{Id: posted.Data.Id, Url: "https://x.com/i/status/" + posted.Data.Id}, nil
+}
+
+// oauthHeader signs a request with HMAC-SHA1. JSON and multipart bodies aren't part of the signature so only the url is.
+func oauthHeader(settings map[string]string, method string, requestUrl string) string {
+	nonce := make([]byte, 16)
+	rand.Read(nonce)
+	params := map[string]string{
+		"oauth_consumer_key":     settings["X_API_KEY"],
+		"oauth_nonce":            hex.EncodeToString(nonce),
+		"oauth_signature_method": "HMAC-SHA1",
+		"oauth_timestamp":        strconv.FormatInt(time.Now().Unix(), 10),
+		"oauth_token":            settings["X_ACCESS_TOKEN"],
+		"oauth_version":          "1.0",
+	}
+	signed := map[string]string{}
+	for k, v := range params {
+		signed[k] = v
+	}
+	base := requestUrl
+	if parsed, err := url.Parse(requestUrl); err == nil {
+		for k, v := range parsed.Query() {
+			signed[k] = v[0]
+		}
+		parsed.RawQuery = ""
+		base = parsed.String()
+	}
+	keys := make([]string, 0, len(signed))
+	for k := range signed {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, percentEncode(k)+"="+percentEncode(signed[k]))
+	}
+	signatureBase := method + "&" + percentEncode(base) + "&" + percentEncode(strings.Join(pairs, "&"))
+	mac := hmac.New(sha1.New, []byte(percentEncode(settings["X_API_SECRET"])+"&"+percentEncode(settings["X_ACCESS_SECRET"])))
+	mac.Write([]byte(signatureBase))
+	params["oauth_signature"] = base64.StdEncoding.EncodeToString(mac.Sum(nil))
+
+	keys = keys[:0]
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	header := make([]string, 0, len(keys))
+	for _, k := range keys {
+		header = append(header, percentEncode(k)+`="`+percentEncode(params[k])+`"`)
+	}
+	return "OAuth " + strings.Join(header, ", ")
+}
+
+// percentEncode is the RFC 3986 encoding OAuth requires, which escapes spaces as %20 rather than +
+func percentEncode(value string) string {
+	return strings.ReplaceAll(url.QueryEscape(value), "+", "%20")
+}
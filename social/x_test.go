@@ -0,0 +1,111 @@
+package social
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"net/url"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestXPost(t *testing.T) {
+	server, requests := fakeServer(t, map[string]string{
+		"/1.1/media/upload.json": `{"media_id_string":"777"}`,
+		"/2/tweets":              `{"data":{"id":"123"}}`,
+	})
+	settings := map[string]string{
+		"X_API_KEY": "key", "X_API_SECRET": "secret", "X_ACCESS_TOKEN": "token", "X_ACCESS_SECRET": "token-secret",
+		"X_API_URL": server.URL, "X_UPLOAD_URL": server.URL,
+	}
+	msg := Message{Text: "New post https://blog.example/post", Image: []byte("gif"), ImageMime: "image/gif"}
+
+	result, err := x{}.Post(context.Background(), settings, msg)
+	if err != nil {
+		t.Fatalf("Post() error: %v", err)
+	}
+	if result.Id != "123" || result.Url != "https://x.com/i/status/123" {
+		t.Errorf("Post() = %+v", result)
+	}
+
+	got := requests()
+	if len(got) != 2 {
+		t.Fatalf("got %d requests, want 2", len(got))
+	}
+	upload, tweet := got[0], got[1]
+	if upload.Path != "/1.1/media/upload.json" || tweet.Path != "/2/tweets" {
+		t.Fatalf("paths = %s, %s", upload.Path, tweet.Path)
+	}
+	for _, r := range got {
+		checkOAuthSignature(t, r, server.URL+r.Path, settings)
+	}
+	if !strings.Contains(string(upload.Body), `name="media"; filename="image.gif"`) {
+		t.Errorf("upload body is missing the image: %s", upload.Body)
+	}
+
+	var body struct {
+		Text  string `json:"text"`
+		Media struct {
+			MediaIds []string `json:"media_ids"`
+		} `json:"media"`
+	}
+	if err := json.Unmarshal(tweet.Body, &body); err != nil {
+		t.Fatalf("tweet body: %v", err)
+	}
+	if body.Text != msg.Text || len(body.Media.MediaIds) != 1 || body.Media.MediaIds[0] != "777" {
+		t.Errorf("tweet body = %+v", body)
+	}
+}
+
+// checkOAuthSignature recomputes the HMAC-SHA1 signature from the parameters the request was sent with
+func checkOAuthSignature(t *testing.T, r recordedRequest, requestUrl string, settings map[string]string) {
+	t.Helper()
+	header := r.Header.Get("Authorization")
+	if !strings.HasPrefix(header, "OAuth ") {
+		t.Fatalf("%s Authorization = %q", r.Path, header)
+	}
+	params := map[string]string{}
+	for _, pair := range strings.Split(strings.TrimPrefix(header, "OAuth "), ", ") {
+		k, v, _ := strings.Cut(pair, "=")
+		key, _ := url.QueryUnescape(k)
+		value, _ := url.QueryUnescape(strings.Trim(v, `"`))
+		params[key] = value
+	}
+	if params["oauth_consumer_key"] != "key" || params["oauth_token"] != "token" || params["oauth_signature_method"] != "HMAC-SHA1" {
+		t.Errorf("%s OAuth params = %v", r.Path, params)
+	}
+	signature := params["oauth_signature"]
+	delete(params, "oauth_signature")
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, percentEncode(k)+"="+percentEncode(params[k]))
+	}
+	base := r.Method + "&" + percentEncode(requestUrl) + "&" + percentEncode(strings.Join(pairs, "&"))
+	mac := hmac.New(sha1.New, []byte(percentEncode(settings["X_API_SECRET"])+"&"+percentEncode(settings["X_ACCESS_SECRET"])))
+	mac.Write([]byte(base))
+	if want := base64.StdEncoding.EncodeToString(mac.Sum(nil)); signature != want {
+		t.Errorf("%s oauth_signature = %q, want %q", r.Path, signature, want)
+	}
+}
+
+func TestPercentEncode(t *testing.T) {
+	tests := map[string]string{
+		"abc-._~":     "abc-._~",
+		"a b":         "a%20b",
+		"a+b=c&d":     "a%2Bb%3Dc%26d",
+		"https://x/y": "https%3A%2F%2Fx%2Fy",
+	}
+	for value, want := range tests {
+		if got := percentEncode(value); got != want {
+			t.Errorf("percentEncode(%q) = %q, want %q", value, got, want)
+		}
+	}
+}
@@ -0,0 +1,6 @@
+DELETE FROM "templates" WHERE template_name LIKE '%.es' OR template_name LIKE '%.de';
+DELETE FROM "settings" WHERE setting_name IN ('DEFAULT_LANGUAGE', 'WP_LANG_PLUGIN');
+ALTER TABLE "articles" DROP COLUMN translation_of;
+ALTER TABLE "articles" DROP COLUMN language;
+ALTER TABLE "series" DROP COLUMN language;
+ALTER TABLE "idea" DROP COLUMN language;
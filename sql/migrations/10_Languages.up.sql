@@ -0,0 +1,23 @@
+ALTER TABLE "idea"
+    ADD COLUMN language TEXT DEFAULT '';
+
+ALTER TABLE "series"
+    ADD COLUMN language TEXT DEFAULT '';
+
+ALTER TABLE "articles"
+    ADD COLUMN language TEXT DEFAULT '';
+
+ALTER TABLE "articles"
+    ADD COLUMN translation_of INTEGER DEFAULT 0;
+
+INSERT INTO "settings" VALUES ('DEFAULT_LANGUAGE','en',current_timestamp, current_timestamp);
+INSERT INTO "settings" VALUES ('WP_LANG_PLUGIN','none',current_timestamp, current_timestamp);
+
+INSERT into templates (template_name, template_text, create_dt, update_dt) VALUES ('system-prompt.es', 'Eres un redactor de blogs y quieres escribir un nuevo artículo.', current_timestamp, current_timestamp);
+INSERT into templates (template_name, template_text, create_dt, update_dt) VALUES ('article-prompt.es', 'Escribe un artículo sobre {{.Prompt}}.  El artículo debe tener {{.Length}} palabras y debe usar encabezados y subencabezados HTML para organizar el artículo.  Incluye la palabra clave principal (que es {{.Keyword}}) en el título, en el primer párrafo y un par de veces a lo largo del texto, de la forma más natural posible.  Incluye un buen título dentro de una etiqueta h1.', current_timestamp, current_timestamp);
+INSERT into templates (template_name, template_text, create_dt, update_dt) VALUES ('title-prompt.es', 'Busca un título para tu artículo. Usa el idioma del artículo.', current_timestamp, current_timestamp);
+INSERT into templates (template_name, template_text, create_dt, update_dt) VALUES ('description-prompt.es', 'Escribe una meta descripción concisa y cautivadora para el artículo que incluya la palabra clave principal {{.Keyword}}. Devuelve solo la descripción, sin ningún otro texto ni marcado.', current_timestamp, current_timestamp);
+INSERT into templates (template_name, template_text, create_dt, update_dt) VALUES ('system-prompt.de', 'Du bist Blogautor und möchtest einen neuen Artikel schreiben.', current_timestamp, current_timestamp);
+INSERT into templates (template_name, template_text, create_dt, update_dt) VALUES ('article-prompt.de', 'Schreibe einen Artikel über {{.Prompt}}.  Der Artikel soll {{.Length}} Wörter lang sein und HTML-Überschriften und Zwischenüberschriften verwenden, um den Artikel zu gliedern.  Verwende das Hauptkeyword (nämlich {{.Keyword}}) im Titel, im ersten Absatz und ein paar Mal im Text, so natürlich wie möglich.  Füge einen guten Titel in einem h1-Tag ein.', current_timestamp, current_timestamp);
+INSERT into templates (template_name, template_text, create_dt, update_dt) VALUES ('title-prompt.de', 'Finde einen Titel für deinen Artikel. Verwende die Sprache des Artikels.', current_timestamp, current_timestamp);
+INSERT into templates (template_name, template_text, create_dt, update_dt) VALUES ('description-prompt.de', 'Schreibe eine prägnante und fesselnde Meta-Beschreibung für den Artikel, die das Hauptkeyword {{.Keyword}} enthält. Gib nur die Beschreibung zurück, ohne weiteren Text oder Markup.', current_timestamp, current_timestamp);
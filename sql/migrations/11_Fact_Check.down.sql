@@ -0,0 +1,4 @@
+DELETE FROM "templates" WHERE template_name = 'factcheck-prompt';
+DELETE FROM "settings" WHERE setting_name IN ('FACT_CHECK_ENABLE', 'FACT_CHECK_DRAFT_RISK');
+ALTER TABLE "articles" DROP COLUMN fact_check_report;
+ALTER TABLE "articles" DROP COLUMN fact_check_risk;
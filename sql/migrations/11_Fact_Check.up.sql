@@ -0,0 +1,10 @@
+ALTER TABLE "articles"
+    ADD COLUMN fact_check_risk TEXT DEFAULT '';
+
+ALTER TABLE "articles"
+    ADD COLUMN fact_check_report TEXT DEFAULT '';
+
+INSERT INTO "settings" VALUES ('FACT_CHECK_ENABLE','false',current_timestamp, current_timestamp);
+INSERT INTO "settings" VALUES ('FACT_CHECK_DRAFT_RISK','high',current_timestamp, current_timestamp);
+
+INSERT into templates (template_name, template_text, create_dt, update_dt) VALUES ('factcheck-prompt', 'List the factual claims made in the article, such as statistics, dates, quotes, names and research findings.  For each claim decide whether it is common knowledge that can be verified or whether it may be invented or cannot be verified.  Then rate the overall risk that the article contains invented facts as low, medium or high.', current_timestamp, current_timestamp);
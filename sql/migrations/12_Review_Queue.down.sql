@@ -0,0 +1,6 @@
+DELETE FROM "settings" WHERE setting_name = 'REVIEW_ENABLE';
+ALTER TABLE "articles" DROP COLUMN review_img;
+ALTER TABLE "articles" DROP COLUMN review_comment;
+ALTER TABLE "articles" DROP COLUMN reviewer;
+ALTER TABLE "articles" DROP COLUMN wp_category;
+ALTER TABLE "articles" DROP COLUMN publish_status;
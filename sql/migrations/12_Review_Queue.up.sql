@@ -0,0 +1,16 @@
+ALTER TABLE "articles"
+    ADD COLUMN publish_status TEXT DEFAULT '';
+
+ALTER TABLE "articles"
+    ADD COLUMN wp_category INTEGER DEFAULT 0;
+
+ALTER TABLE "articles"
+    ADD COLUMN reviewer TEXT DEFAULT '';
+
+ALTER TABLE "articles"
+    ADD COLUMN review_comment TEXT DEFAULT '';
+
+ALTER TABLE "articles"
+    ADD COLUMN review_img TEXT DEFAULT '';
+
+INSERT INTO "settings" VALUES ('REVIEW_ENABLE','false',current_timestamp, current_timestamp);
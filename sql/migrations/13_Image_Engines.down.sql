@@ -0,0 +1 @@
+DELETE FROM "settings" WHERE setting_name IN ('DALLE_SIZE', 'COMFY_URL', 'COMFY_WORKFLOW', 'OPENAI_IMG_URL', 'OPENAI_IMG_KEY', 'OPENAI_IMG_MODEL');
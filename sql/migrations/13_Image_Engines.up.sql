@@ -0,0 +1,6 @@
+INSERT INTO "settings" VALUES ('DALLE_SIZE','256x256',current_timestamp, current_timestamp);
+INSERT INTO "settings" VALUES ('COMFY_URL','',current_timestamp, current_timestamp);
+INSERT INTO "settings" VALUES ('COMFY_WORKFLOW','',current_timestamp, current_timestamp);
+INSERT INTO "settings" VALUES ('OPENAI_IMG_URL','',current_timestamp, current_timestamp);
+INSERT INTO "settings" VALUES ('OPENAI_IMG_KEY','',current_timestamp, current_timestamp);
+INSERT INTO "settings" VALUES ('OPENAI_IMG_MODEL','',current_timestamp, current_timestamp);
@@ -0,0 +1,2 @@
+DELETE FROM "settings" WHERE setting_name IN ('IMG_CANDIDATES', 'IMG_AUTO_PICK');
+DROP TABLE "image_candidates";
@@ -0,0 +1,11 @@
+CREATE TABLE "image_candidates" (
+                        "id"                INTEGER,
+                        "article_id"        INTEGER,
+                        "image_data"        text,
+                        "source"            text,
+                        "create_dt"         INTEGER,
+                        PRIMARY KEY("id" AUTOINCREMENT)
+);
+
+INSERT INTO "settings" VALUES ('IMG_CANDIDATES','1',current_timestamp, current_timestamp);
+INSERT INTO "settings" VALUES ('IMG_AUTO_PICK','first',current_timestamp, current_timestamp);
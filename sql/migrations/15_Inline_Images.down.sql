@@ -0,0 +1 @@
+DELETE FROM "settings" WHERE setting_name IN ('INLINE_IMG_ENGINE', 'INLINE_IMG_MAX');
@@ -0,0 +1,2 @@
+INSERT INTO "settings" VALUES ('INLINE_IMG_ENGINE','none',current_timestamp, current_timestamp);
+INSERT INTO "settings" VALUES ('INLINE_IMG_MAX','3',current_timestamp, current_timestamp);
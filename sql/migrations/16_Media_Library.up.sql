@@ -0,0 +1,13 @@
+CREATE TABLE "media" (
+                        "id"                INTEGER,
+                        "file_name"         text,
+                        "mime_type"         text,
+                        "prompt"            text,
+                        "seed"              INTEGER DEFAULT -1,
+                        "engine"            text,
+                        "source_url"        text,
+                        "article_id"        INTEGER DEFAULT 0,
+                        "create_dt"         INTEGER,
+                        "update_dt"         INTEGER,
+                        PRIMARY KEY("id" AUTOINCREMENT)
+);
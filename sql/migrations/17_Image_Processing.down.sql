@@ -0,0 +1 @@
+DELETE FROM "settings" WHERE setting_name IN ('IMG_FORMAT', 'FEATURED_IMG_WIDTH', 'FEATURED_IMG_HEIGHT', 'IMG_QUALITY', 'IMG_MAX_KB');
@@ -0,0 +1,5 @@
+INSERT INTO "settings" VALUES ('IMG_FORMAT','jpeg',current_timestamp, current_timestamp);
+INSERT INTO "settings" VALUES ('FEATURED_IMG_WIDTH','1200',current_timestamp, current_timestamp);
+INSERT INTO "settings" VALUES ('FEATURED_IMG_HEIGHT','630',current_timestamp, current_timestamp);
+INSERT INTO "settings" VALUES ('IMG_QUALITY','82',current_timestamp, current_timestamp);
+INSERT INTO "settings" VALUES ('IMG_MAX_KB','200',current_timestamp, current_timestamp);
@@ -0,0 +1,5 @@
+DELETE FROM "settings" WHERE setting_name = 'IMG_CREDIT_ENABLE';
+ALTER TABLE "media" DROP COLUMN credit;
+ALTER TABLE "image_candidates" DROP COLUMN download_url;
+ALTER TABLE "image_candidates" DROP COLUMN credit;
+ALTER TABLE "articles" DROP COLUMN img_credit;
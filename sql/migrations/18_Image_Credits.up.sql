@@ -0,0 +1,13 @@
+ALTER TABLE "articles"
+    ADD COLUMN img_credit TEXT DEFAULT '';
+
+ALTER TABLE "image_candidates"
+    ADD COLUMN credit TEXT DEFAULT '';
+
+ALTER TABLE "image_candidates"
+    ADD COLUMN download_url TEXT DEFAULT '';
+
+ALTER TABLE "media"
+    ADD COLUMN credit TEXT DEFAULT '';
+
+INSERT INTO "settings" VALUES ('IMG_CREDIT_ENABLE','true',current_timestamp, current_timestamp);
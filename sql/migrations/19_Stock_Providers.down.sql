@@ -0,0 +1,9 @@
+DELETE FROM "settings" WHERE setting_name IN ('STOCK_PROVIDERS', 'PEXELS_API_KEY', 'PIXABAY_API_KEY');
+UPDATE "concept_overrides" SET img_engine = 'unsplash' WHERE img_engine = 'stock';
+UPDATE "series" SET img_engine = 'unsplash' WHERE img_engine = 'stock';
+UPDATE "settings" SET setting_value = 'unsplash' WHERE setting_name IN ('AUTO_POST_IMG_ENGINE', 'INLINE_IMG_ENGINE') AND setting_value = 'stock';
+ALTER TABLE "media" DROP COLUMN license_url;
+ALTER TABLE "media" DROP COLUMN license;
+ALTER TABLE "image_candidates" DROP COLUMN license_url;
+ALTER TABLE "image_candidates" DROP COLUMN license;
+ALTER TABLE "articles" DROP COLUMN img_license;
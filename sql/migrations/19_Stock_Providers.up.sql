@@ -0,0 +1,22 @@
+ALTER TABLE "articles"
+    ADD COLUMN img_license TEXT DEFAULT '';
+
+ALTER TABLE "image_candidates"
+    ADD COLUMN license TEXT DEFAULT '';
+
+ALTER TABLE "image_candidates"
+    ADD COLUMN license_url TEXT DEFAULT '';
+
+ALTER TABLE "media"
+    ADD COLUMN license TEXT DEFAULT '';
+
+ALTER TABLE "media"
+    ADD COLUMN license_url TEXT DEFAULT '';
+
+UPDATE "settings" SET setting_value = 'stock' WHERE setting_name IN ('AUTO_POST_IMG_ENGINE', 'INLINE_IMG_ENGINE') AND setting_value = 'unsplash';
+UPDATE "series" SET img_engine = 'stock' WHERE img_engine = 'unsplash';
+UPDATE "concept_overrides" SET img_engine = 'stock' WHERE img_engine = 'unsplash';
+
+INSERT INTO "settings" VALUES ('STOCK_PROVIDERS','unsplash,pexels,pixabay,openverse',current_timestamp, current_timestamp);
+INSERT INTO "settings" VALUES ('PEXELS_API_KEY','',current_timestamp, current_timestamp);
+INSERT INTO "settings" VALUES ('PIXABAY_API_KEY','',current_timestamp, current_timestamp);
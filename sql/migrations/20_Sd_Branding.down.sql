@@ -0,0 +1,6 @@
+DELETE FROM "settings" WHERE setting_name IN ('SD_MODEL', 'SD_STYLE', 'SD_LORA', 'SD_LORA_WEIGHT', 'SD_DENOISING');
+ALTER TABLE "media" DROP COLUMN gen_info;
+ALTER TABLE "series" DROP COLUMN ref_media_id;
+ALTER TABLE "series" DROP COLUMN sd_lora;
+ALTER TABLE "series" DROP COLUMN sd_style;
+ALTER TABLE "series" DROP COLUMN sd_model;
@@ -0,0 +1,20 @@
+ALTER TABLE "series"
+    ADD COLUMN sd_model TEXT DEFAULT '';
+
+ALTER TABLE "series"
+    ADD COLUMN sd_style TEXT DEFAULT '';
+
+ALTER TABLE "series"
+    ADD COLUMN sd_lora TEXT DEFAULT '';
+
+ALTER TABLE "series"
+    ADD COLUMN ref_media_id INTEGER DEFAULT 0;
+
+ALTER TABLE "media"
+    ADD COLUMN gen_info TEXT DEFAULT '';
+
+INSERT INTO "settings" VALUES ('SD_MODEL','',current_timestamp, current_timestamp);
+INSERT INTO "settings" VALUES ('SD_STYLE','',current_timestamp, current_timestamp);
+INSERT INTO "settings" VALUES ('SD_LORA','',current_timestamp, current_timestamp);
+INSERT INTO "settings" VALUES ('SD_LORA_WEIGHT','0.8',current_timestamp, current_timestamp);
+INSERT INTO "settings" VALUES ('SD_DENOISING','0.6',current_timestamp, current_timestamp);
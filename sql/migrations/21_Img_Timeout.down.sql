@@ -0,0 +1 @@
+DELETE FROM "settings" WHERE setting_name = 'IMG_TIMEOUT';
@@ -0,0 +1 @@
+INSERT INTO "settings" VALUES ('IMG_TIMEOUT','600',current_timestamp, current_timestamp);
@@ -0,0 +1 @@
+INSERT INTO "settings" VALUES ('IMG_CONCURRENCY','sd=1,comfyui=1,openai=3,openai-compatible=1',current_timestamp, current_timestamp);
@@ -0,0 +1,2 @@
+DELETE FROM "settings" WHERE setting_name IN ('OVERLAY_LOGO', 'OVERLAY_LOGO_POSITION', 'OVERLAY_LOGO_SIZE', 'OVERLAY_LOGO_OPACITY', 'OVERLAY_TITLE_ENABLE',
+    'OVERLAY_FONT', 'OVERLAY_FONT_SIZE', 'OVERLAY_TEXT_COLOR', 'OVERLAY_TEXT_POSITION', 'OVERLAY_TEXT_BACKGROUND');
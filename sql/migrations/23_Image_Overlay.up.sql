@@ -0,0 +1,10 @@
+INSERT INTO "settings" VALUES ('OVERLAY_LOGO','',current_timestamp, current_timestamp);
+INSERT INTO "settings" VALUES ('OVERLAY_LOGO_POSITION','bottom-right',current_timestamp, current_timestamp);
+INSERT INTO "settings" VALUES ('OVERLAY_LOGO_SIZE','15',current_timestamp, current_timestamp);
+INSERT INTO "settings" VALUES ('OVERLAY_LOGO_OPACITY','80',current_timestamp, current_timestamp);
+INSERT INTO "settings" VALUES ('OVERLAY_TITLE_ENABLE','false',current_timestamp, current_timestamp);
+INSERT INTO "settings" VALUES ('OVERLAY_FONT','',current_timestamp, current_timestamp);
+INSERT INTO "settings" VALUES ('OVERLAY_FONT_SIZE','7',current_timestamp, current_timestamp);
+INSERT INTO "settings" VALUES ('OVERLAY_TEXT_COLOR','#ffffff',current_timestamp, current_timestamp);
+INSERT INTO "settings" VALUES ('OVERLAY_TEXT_POSITION','bottom',current_timestamp, current_timestamp);
+INSERT INTO "settings" VALUES ('OVERLAY_TEXT_BACKGROUND','#00000099',current_timestamp, current_timestamp);
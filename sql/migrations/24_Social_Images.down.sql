@@ -0,0 +1 @@
+DELETE FROM "settings" WHERE setting_name IN ('SOCIAL_IMG_ENABLE', 'SOCIAL_IMG_MODE', 'WP_SEO_PLUGIN');
@@ -0,0 +1,3 @@
+INSERT INTO "settings" VALUES ('SOCIAL_IMG_ENABLE','false',current_timestamp, current_timestamp);
+INSERT INTO "settings" VALUES ('SOCIAL_IMG_MODE','crop',current_timestamp, current_timestamp);
+INSERT INTO "settings" VALUES ('WP_SEO_PLUGIN','none',current_timestamp, current_timestamp);
@@ -0,0 +1,3 @@
+DELETE FROM "templates" WHERE template_name LIKE 'social-post%';
+DELETE FROM "settings" WHERE setting_name IN ('SOCIAL_CHANNELS', 'MASTODON_URL', 'MASTODON_TOKEN', 'BLUESKY_URL', 'BLUESKY_HANDLE', 'BLUESKY_APP_PASSWORD', 'SOCIAL_WEBHOOK_URL', 'X_API_KEY', 'X_API_SECRET', 'X_ACCESS_TOKEN', 'X_ACCESS_SECRET', 'X_API_URL', 'X_UPLOAD_URL');
+DROP TABLE "social_posts";
@@ -0,0 +1,32 @@
+CREATE TABLE "social_posts" (
+                        "id"                INTEGER,
+                        "article_id"        INTEGER,
+                        "channel"           text,
+                        "status"            text,
+                        "remote_id"         text,
+                        "remote_url"        text,
+                        "post_text"         text,
+                        "error"             text,
+                        "create_dt"         INTEGER,
+                        PRIMARY KEY("id" AUTOINCREMENT)
+);
+
+INSERT INTO "settings" VALUES ('SOCIAL_CHANNELS','',current_timestamp, current_timestamp);
+INSERT INTO "settings" VALUES ('MASTODON_URL','',current_timestamp, current_timestamp);
+INSERT INTO "settings" VALUES ('MASTODON_TOKEN','',current_timestamp, current_timestamp);
+INSERT INTO "settings" VALUES ('BLUESKY_URL','',current_timestamp, current_timestamp);
+INSERT INTO "settings" VALUES ('BLUESKY_HANDLE','',current_timestamp, current_timestamp);
+INSERT INTO "settings" VALUES ('BLUESKY_APP_PASSWORD','',current_timestamp, current_timestamp);
+INSERT INTO "settings" VALUES ('SOCIAL_WEBHOOK_URL','',current_timestamp, current_timestamp);
+INSERT INTO "settings" VALUES ('X_API_KEY','',current_timestamp, current_timestamp);
+INSERT INTO "settings" VALUES ('X_API_SECRET','',current_timestamp, current_timestamp);
+INSERT INTO "settings" VALUES ('X_ACCESS_TOKEN','',current_timestamp, current_timestamp);
+INSERT INTO "settings" VALUES ('X_ACCESS_SECRET','',current_timestamp, current_timestamp);
+INSERT INTO "settings" VALUES ('X_API_URL','',current_timestamp, current_timestamp);
+INSERT INTO "settings" VALUES ('X_UPLOAD_URL','',current_timestamp, current_timestamp);
+
+INSERT into templates (template_name, template_text, create_dt, update_dt) VALUES ('social-post', '{{.Title}}
+
+{{.Description}}
+
+{{.Link}} {{.Hashtags}}', current_timestamp, current_timestamp);
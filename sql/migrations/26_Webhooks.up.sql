@@ -0,0 +1,26 @@
+CREATE TABLE "webhooks" (
+                        "id"                INTEGER,
+                        "name"              text,
+                        "url"               text,
+                        "secret"            text,
+                        "events"            text,
+                        "enabled"           INTEGER DEFAULT 1,
+                        "create_dt"         INTEGER,
+                        "update_dt"         INTEGER,
+                        PRIMARY KEY("id" AUTOINCREMENT)
+);
+
+CREATE TABLE "webhook_deliveries" (
+                        "id"                INTEGER,
+                        "webhook_id"        INTEGER,
+                        "event"             text,
+                        "payload"           text,
+                        "status"            text,
+                        "attempts"          INTEGER DEFAULT 0,
+                        "status_code"       INTEGER DEFAULT 0,
+                        "response"          text,
+                        "error"             text,
+                        "create_dt"         INTEGER,
+                        "update_dt"         INTEGER,
+                        PRIMARY KEY("id" AUTOINCREMENT)
+);
@@ -0,0 +1,4 @@
+DELETE FROM "templates" WHERE template_name = 'feed-idea-prompt';
+DELETE FROM "settings" WHERE setting_name IN ('FEED_INTERVAL', 'FEED_MAX_ITEMS');
+DROP TABLE "feed_items";
+DROP TABLE "feeds";
@@ -0,0 +1,31 @@
+CREATE TABLE "feeds" (
+                        "id"                INTEGER,
+                        "name"              text,
+                        "url"               text,
+                        "rewrite"           INTEGER DEFAULT 0,
+                        "language"          text DEFAULT '',
+                        "enabled"           INTEGER DEFAULT 1,
+                        "last_checked"      text DEFAULT '',
+                        "last_error"        text DEFAULT '',
+                        "create_dt"         INTEGER,
+                        "update_dt"         INTEGER,
+                        PRIMARY KEY("id" AUTOINCREMENT)
+);
+
+CREATE TABLE "feed_items" (
+                        "id"                INTEGER,
+                        "feed_id"           INTEGER,
+                        "guid"              text,
+                        "title"             text,
+                        "link"              text,
+                        "idea_id"           INTEGER DEFAULT 0,
+                        "create_dt"         INTEGER,
+                        PRIMARY KEY("id" AUTOINCREMENT)
+);
+
+CREATE INDEX "feed_items_guid" ON "feed_items" ("feed_id", "guid");
+
+INSERT INTO "settings" VALUES ('FEED_INTERVAL','1h',current_timestamp, current_timestamp);
+INSERT INTO "settings" VALUES ('FEED_MAX_ITEMS','5',current_timestamp, current_timestamp);
+
+INSERT into templates (template_name, template_text, create_dt, update_dt) VALUES ('feed-idea-prompt', 'A news story titled "{{.Title}}" was published by {{.Feed}}.  Its summary is: {{.Summary}}  Come up with an idea for an original blog article that reacts to this news for our readers, taking its own angle rather than retelling the story.', current_timestamp, current_timestamp);
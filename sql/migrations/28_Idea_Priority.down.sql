@@ -0,0 +1,5 @@
+DELETE FROM "settings" WHERE setting_name = 'AUTO_POST_STRATEGY';
+ALTER TABLE "idea" DROP COLUMN not_before;
+ALTER TABLE "idea" DROP COLUMN keyword;
+ALTER TABLE "idea" DROP COLUMN tags;
+ALTER TABLE "idea" DROP COLUMN priority;
@@ -0,0 +1,13 @@
+ALTER TABLE "idea"
+    ADD COLUMN priority INTEGER DEFAULT 0;
+
+ALTER TABLE "idea"
+    ADD COLUMN tags TEXT DEFAULT '';
+
+ALTER TABLE "idea"
+    ADD COLUMN keyword TEXT DEFAULT '';
+
+ALTER TABLE "idea"
+    ADD COLUMN not_before TEXT DEFAULT '';
+
+INSERT INTO "settings" VALUES ('AUTO_POST_STRATEGY','random',current_timestamp, current_timestamp);
@@ -0,0 +1,10 @@
+DELETE FROM "templates" WHERE template_name IN ('series-part-prompt', 'series-part-title', 'series-nav');
+
+ALTER TABLE "series"
+    DROP COLUMN nav_links;
+
+ALTER TABLE "series"
+    DROP COLUMN part_titles;
+
+ALTER TABLE "idea"
+    DROP COLUMN series_order;
@@ -0,0 +1,14 @@
+ALTER TABLE "idea"
+    ADD COLUMN series_order INTEGER DEFAULT 0;
+
+ALTER TABLE "series"
+    ADD COLUMN part_titles INTEGER DEFAULT 0;
+
+ALTER TABLE "series"
+    ADD COLUMN nav_links INTEGER DEFAULT 1;
+
+UPDATE "idea" SET series_order = id WHERE series_id > 0;
+
+INSERT into templates (template_name, template_text, create_dt, update_dt) VALUES ('series-part-prompt', 'This article is part {{.Part}} of {{.Parts}} in the series "{{.Series}}".{{if .Previous}}  The previous part covered "{{.Previous}}".{{end}}{{if .Next}}  The next part will cover "{{.Next}}".{{end}}  Write it so it follows on from the earlier parts without repeating them, while still making sense to a reader who starts here.', current_timestamp, current_timestamp);
+INSERT into templates (template_name, template_text, create_dt, update_dt) VALUES ('series-part-title', '{{.Title}} (Part {{.Part}} of {{.Parts}})', current_timestamp, current_timestamp);
+INSERT into templates (template_name, template_text, create_dt, update_dt) VALUES ('series-nav', '<p class="series-nav">Part {{.Part}} of {{.Parts}} in the series {{.Series}}.{{if .PreviousLink}}<br>Previous: <a href="{{.PreviousLink}}">{{.PreviousTitle}}</a>{{end}}{{if .NextLink}}<br>Next: <a href="{{.NextLink}}">{{.NextTitle}}</a>{{end}}</p>', current_timestamp, current_timestamp);
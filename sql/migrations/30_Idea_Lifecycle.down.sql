@@ -0,0 +1,11 @@
+DELETE FROM "settings" WHERE setting_name = 'AUTO_POST_REQUIRE_APPROVAL';
+
+UPDATE "idea" SET status = 'NEW' WHERE status IN ('APPROVED', 'SCHEDULED');
+
+UPDATE "idea" SET status = 'REVIEW' WHERE status = 'IN-PROGRESS';
+
+UPDATE "idea" SET status = 'WRITTEN' WHERE status IN ('PUBLISHED', 'REJECTED', 'ARCHIVED');
+
+DROP INDEX "idea_history_idea_id";
+
+DROP TABLE "idea_history";
@@ -0,0 +1,18 @@
+CREATE TABLE "idea_history" (
+                        "id"                INTEGER,
+                        "idea_id"           INTEGER,
+                        "from_status"       text,
+                        "to_status"         text,
+                        "changed_by"        text,
+                        "note"              text,
+                        "create_dt"         INTEGER,
+                        PRIMARY KEY("id" AUTOINCREMENT)
+);
+
+CREATE INDEX "idea_history_idea_id" ON "idea_history" ("idea_id");
+
+UPDATE "idea" SET status = 'IN-PROGRESS' WHERE status IN ('PENDING', 'REVIEW');
+
+UPDATE "idea" SET status = 'PUBLISHED' WHERE status = 'WRITTEN' AND id IN (SELECT idea_id FROM articles WHERE wordpress_id > 0 AND publish_status = 'publish');
+
+INSERT INTO "settings" VALUES ('AUTO_POST_REQUIRE_APPROVAL','false',current_timestamp, current_timestamp);
@@ -0,0 +1,5 @@
+ALTER TABLE "concept_overrides"
+    DROP COLUMN img_mode;
+
+ALTER TABLE "series"
+    DROP COLUMN img_mode;
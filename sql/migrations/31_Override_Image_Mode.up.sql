@@ -0,0 +1,5 @@
+ALTER TABLE "series"
+    ADD COLUMN img_mode TEXT DEFAULT '';
+
+ALTER TABLE "concept_overrides"
+    ADD COLUMN img_mode TEXT DEFAULT '';
@@ -0,0 +1,5 @@
+ALTER TABLE "articles"
+    DROP COLUMN wp_edited;
+
+ALTER TABLE "articles"
+    DROP COLUMN wp_modified;
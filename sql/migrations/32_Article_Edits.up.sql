@@ -0,0 +1,5 @@
+ALTER TABLE "articles"
+    ADD COLUMN wp_modified TEXT DEFAULT '';
+
+ALTER TABLE "articles"
+    ADD COLUMN wp_edited INTEGER DEFAULT 0;
@@ -0,0 +1,4 @@
+DELETE FROM "settings" WHERE setting_name IN ('PROMPT_EXPERIMENT_ENABLE', 'WP_VIEWS_FIELD');
+ALTER TABLE "articles" DROP COLUMN wp_views;
+ALTER TABLE "articles" DROP COLUMN variant_id;
+DROP TABLE "prompt_variants";
@@ -0,0 +1,19 @@
+CREATE TABLE "prompt_variants" (
+                        "id"                INTEGER,
+                        "variant_name"      text,
+                        "system_prompt"     text,
+                        "article_prompt"    text,
+                        "active"            INTEGER DEFAULT 1,
+                        "create_dt"         INTEGER,
+                        "update_dt"         INTEGER,
+                        PRIMARY KEY("id" AUTOINCREMENT)
+);
+
+ALTER TABLE "articles"
+    ADD COLUMN variant_id INTEGER DEFAULT 0;
+
+ALTER TABLE "articles"
+    ADD COLUMN wp_views INTEGER DEFAULT 0;
+
+INSERT INTO "settings" VALUES ('PROMPT_EXPERIMENT_ENABLE','false',current_timestamp, current_timestamp);
+INSERT INTO "settings" VALUES ('WP_VIEWS_FIELD','',current_timestamp, current_timestamp);
@@ -0,0 +1,8 @@
+DELETE FROM "settings" WHERE setting_name = 'WP_CATEGORY_ID';
+DROP TABLE "concept_overrides";
+ALTER TABLE "series" DROP COLUMN wp_category;
+ALTER TABLE "series" DROP COLUMN publish_status;
+ALTER TABLE "series" DROP COLUMN img_engine;
+ALTER TABLE "series" DROP COLUMN article_length;
+ALTER TABLE "series" DROP COLUMN article_prompt;
+ALTER TABLE "series" DROP COLUMN system_prompt;
@@ -0,0 +1,32 @@
+ALTER TABLE "series"
+    ADD COLUMN system_prompt TEXT DEFAULT '';
+
+ALTER TABLE "series"
+    ADD COLUMN article_prompt TEXT DEFAULT '';
+
+ALTER TABLE "series"
+    ADD COLUMN article_length INTEGER DEFAULT 0;
+
+ALTER TABLE "series"
+    ADD COLUMN img_engine TEXT DEFAULT '';
+
+ALTER TABLE "series"
+    ADD COLUMN publish_status TEXT DEFAULT '';
+
+ALTER TABLE "series"
+    ADD COLUMN wp_category INTEGER DEFAULT 0;
+
+CREATE TABLE "concept_overrides" (
+                        "concept"           text,
+                        "system_prompt"     text,
+                        "article_prompt"    text,
+                        "article_length"    INTEGER,
+                        "img_engine"        text,
+                        "publish_status"    text,
+                        "wp_category"       INTEGER,
+                        "create_dt"         INTEGER,
+                        "update_dt"         INTEGER,
+                        PRIMARY KEY("concept")
+);
+
+INSERT INTO "settings" VALUES ('WP_CATEGORY_ID','',current_timestamp, current_timestamp);
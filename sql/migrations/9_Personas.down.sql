@@ -0,0 +1,5 @@
+DELETE FROM "settings" WHERE setting_name IN ('DEFAULT_PERSONA_ID', 'PERSONA_REVISE_ENABLE');
+ALTER TABLE "articles" DROP COLUMN persona_flags;
+ALTER TABLE "articles" DROP COLUMN persona_id;
+ALTER TABLE "series" DROP COLUMN persona_id;
+DROP TABLE "personas";
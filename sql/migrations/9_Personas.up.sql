@@ -0,0 +1,23 @@
+CREATE TABLE "personas" (
+                        "id"                INTEGER,
+                        "persona_name"      text,
+                        "voice"             text,
+                        "style_rules"       text,
+                        "banned_phrases"    text,
+                        "examples"          text,
+                        "create_dt"         INTEGER,
+                        "update_dt"         INTEGER,
+                        PRIMARY KEY("id" AUTOINCREMENT)
+);
+
+ALTER TABLE "series"
+    ADD COLUMN persona_id INTEGER DEFAULT 0;
+
+ALTER TABLE "articles"
+    ADD COLUMN persona_id INTEGER DEFAULT 0;
+
+ALTER TABLE "articles"
+    ADD COLUMN persona_flags TEXT DEFAULT '';
+
+INSERT INTO "settings" VALUES ('DEFAULT_PERSONA_ID','0',current_timestamp, current_timestamp);
+INSERT INTO "settings" VALUES ('PERSONA_REVISE_ENABLE','false',current_timestamp, current_timestamp);
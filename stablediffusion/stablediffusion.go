@@ -5,8 +5,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
+	"strconv"
 )
 
 func buildURL(baseUrl string, path string) (*url.URL, error) {
@@ -15,32 +17,65 @@ func buildURL(baseUrl string, path string) (*url.URL, error) {
 		return nil, err
 	}
 
-	u.Path = path
+	ref, err := url.Parse(path)
+	if err != nil {
+		return nil, err
+	}
+	u.Path = ref.Path
+	u.RawQuery = ref.RawQuery
 
 	return u, nil
 }
 
 // SimpleImageRequest is all of the parameters needed to generate an image.
 type SimpleImageRequest struct {
-	Prompt           string   `json:"prompt"`
-	NegativePrompt   string   `json:"negative_prompt"`
-	Styles           []string `json:"styles"`
-	Seed             int      `json:"seed"`
-	SamplerName      string   `json:"sampler_name"`
-	BatchSize        int      `json:"batch_size"`
-	NIter            int      `json:"n_iter"`
-	Steps            int      `json:"steps"`
-	CfgScale         int      `json:"cfg_scale"`
-	Width            int      `json:"width"`
-	Height           int      `json:"height"`
-	SNoise           int      `json:"s_noise"`
-	EnableHr         bool     `json:"enable_hr"`
-	HrScale          int      `json:"hr_scale"`
-	HrUpscaler       string   `json:"hr_upscaler"`
-	OverrideSettings struct {
-	} `json:"override_settings"`
-	OverrideSettingsRestoreAfterwards bool `json:"override_settings_restore_afterwards"`
-	SaveImages                        bool `json:"save_images"`
+	Prompt         string   `json:"prompt"`
+	NegativePrompt string   `json:"negative_prompt"`
+	Styles         []string `json:"styles"`
+	Seed           int      `json:"seed"`
+	SamplerName    string   `json:"sampler_name"`
+	BatchSize      int      `json:"batch_size"`
+	NIter          int      `json:"n_iter"`
+	Steps          int      `json:"steps"`
+	CfgScale       int      `json:"cfg_scale"`
+	Width          int      `json:"width"`
+	Height         int      `json:"height"`
+	SNoise         int      `json:"s_noise"`
+	EnableHr       bool     `json:"enable_hr"`
+	HrScale        int      `json:"hr_scale"`
+	HrUpscaler     string   `json:"hr_upscaler"`
+	// OverrideSettings are web ui options applied for this request only, e.g. sd_model_checkpoint
+	OverrideSettings                  map[string]interface{} `json:"override_settings,omitempty"`
+	OverrideSettingsRestoreAfterwards bool                   `json:"override_settings_restore_afterwards"`
+	SaveImages                        bool                   `json:"save_images"`
+}
+
+// Img2ImgRequest generates from reference images, DenoisingStrength sets how far it may stray from them (0-1)
+type Img2ImgRequest struct {
+	SimpleImageRequest
+	InitImages        [][]byte `json:"init_images"`
+	DenoisingStrength float64  `json:"denoising_strength"`
+	ResizeMode        int      `json:"resize_mode"`
+}
+
+// Progress is how far along the web ui is with its current job, CurrentImage is a preview of it when live previews are on
+type Progress struct {
+	Progress     float64 `json:"progress"`
+	EtaRelative  float64 `json:"eta_relative"`
+	State        State   `json:"state"`
+	CurrentImage []byte  `json:"current_image"`
+	Textinfo     string  `json:"textinfo"`
+}
+
+type State struct {
+	Skipped       bool   `json:"skipped"`
+	Interrupted   bool   `json:"interrupted"`
+	Job           string `json:"job"`
+	JobCount      int    `json:"job_count"`
+	JobTimestamp  string `json:"job_timestamp"`
+	JobNo         int    `json:"job_no"`
+	SamplingStep  int    `json:"sampling_step"`
+	SamplingSteps int    `json:"sampling_steps"`
 }
 
 type ImageResponse struct {
@@ -48,6 +83,16 @@ type ImageResponse struct {
 	Info   string   `json:"info"`
 }
 
+// ParseInfo reads the generation parameters the web ui returns as a JSON string in Info
+func (r *ImageResponse) ParseInfo() (ImageInfo, error) {
+	var info ImageInfo
+	if r.Info == "" {
+		return info, nil
+	}
+	err := json.Unmarshal([]byte(r.Info), &info)
+	return info, err
+}
+
 type Algorithm struct {
 	Name    string            `json:"name"`
 	Aliases []string          `json:"aliases"`
@@ -55,43 +100,65 @@ type Algorithm struct {
 }
 
 type Upscaler struct {
-	Name      string `json:"name"`
+	Name      string  `json:"name"`
+	ModelName string  `json:"model_name"`
+	ModelPath string  `json:"model_path"`
+	ModelUrl  string  `json:"model_url"`
+	Scale     float64 `json:"scale"`
+}
+
+// Model is a checkpoint the web ui can load, Title is the value sd_model_checkpoint expects
+type Model struct {
+	Title     string `json:"title"`
 	ModelName string `json:"model_name"`
-	ModelPath string `json:"model_path"`
-	ModelUrl  string `json:"model_url"`
-	scale     int    `json:"scale"`
+	Hash      string `json:"hash"`
+	Sha256    string `json:"sha256"`
+	Filename  string `json:"filename"`
+}
+
+// PromptStyle is a saved style, its prompts are merged into the request's when named in Styles
+type PromptStyle struct {
+	Name           string `json:"name"`
+	Prompt         string `json:"prompt"`
+	NegativePrompt string `json:"negative_prompt"`
+}
+
+type Lora struct {
+	Name  string `json:"name"`
+	Alias string `json:"alias"`
+	Path  string `json:"path"`
 }
 
 type ImageInfo struct {
-	Prompt                string      `json:"prompt"`
-	AllPrompts            []string    `json:"all_prompts"`
-	NegativePrompt        string      `json:"negative_prompt"`
-	AllNegativePrompts    []string    `json:"all_negative_prompts"`
-	Seed                  int         `json:"seed"`
-	AllSeeds              []int       `json:"all_seeds"`
-	Subseed               int         `json:"subseed"`
-	AllSubseeds           []int       `json:"all_subseeds"`
-	SubseedStrength       int         `json:"subseed_strength"`
-	Width                 int         `json:"width"`
-	Height                int         `json:"height"`
-	SamplerName           string      `json:"sampler_name"`
-	CfgScale              float64     `json:"cfg_scale"`
-	Steps                 int         `json:"steps"`
-	BatchSize             int         `json:"batch_size"`
-	RestoreFaces          bool        `json:"restore_faces"`
-	FaceRestorationModel  interface{} `json:"face_restoration_model"`
-	SdModelHash           string      `json:"sd_model_hash"`
-	SeedResizeFromW       int         `json:"seed_resize_from_w"`
-	SeedResizeFromH       int         `json:"seed_resize_from_h"`
-	DenoisingStrength     int         `json:"denoising_strength"`
-	ExtraGenerationParams struct {
-	} `json:"extra_generation_params"`
-	IndexOfFirstImage             int           `json:"index_of_first_image"`
-	Infotexts                     []string      `json:"infotexts"`
-	Styles                        []interface{} `json:"styles"`
-	JobTimestamp                  string        `json:"job_timestamp"`
-	ClipSkip                      int           `json:"clip_skip"`
-	IsUsingInpaintingConditioning bool          `json:"is_using_inpainting_conditioning"`
+	Prompt               string      `json:"prompt"`
+	AllPrompts           []string    `json:"all_prompts"`
+	NegativePrompt       string      `json:"negative_prompt"`
+	AllNegativePrompts   []string    `json:"all_negative_prompts"`
+	Seed                 int64       `json:"seed"`
+	AllSeeds             []int64     `json:"all_seeds"`
+	Subseed              int64       `json:"subseed"`
+	AllSubseeds          []int64     `json:"all_subseeds"`
+	SubseedStrength      float64     `json:"subseed_strength"`
+	Width                int         `json:"width"`
+	Height               int         `json:"height"`
+	SamplerName          string      `json:"sampler_name"`
+	CfgScale             float64     `json:"cfg_scale"`
+	Steps                int         `json:"steps"`
+	BatchSize            int         `json:"batch_size"`
+	RestoreFaces         bool        `json:"restore_faces"`
+	FaceRestorationModel interface{} `json:"face_restoration_model"`
+	SdModelHash          string      `json:"sd_model_hash"`
+	SeedResizeFromW      int         `json:"seed_resize_from_w"`
+	SeedResizeFromH      int         `json:"seed_resize_from_h"`
+	DenoisingStrength    float64     `json:"denoising_strength"`
+	// ExtraGenerationParams holds extension parameters such as the LoRA hashes
+	ExtraGenerationParams         map[string]interface{} `json:"extra_generation_params"`
+	IndexOfFirstImage             int                    `json:"index_of_first_image"`
+	Infotexts                     []string               `json:"infotexts"`
+	Styles                        []string               `json:"styles"`
+	JobTimestamp                  string                 `json:"job_timestamp"`
+	ClipSkip                      int                    `json:"clip_skip"`
+	IsUsingInpaintingConditioning bool                   `json:"is_using_inpainting_conditioning"`
 }
 
 var (
@@ -104,94 +171,149 @@ func Generate(sdUrl string, ctx context.Context, inp SimpleImageRequest) (*Image
 	return Default.Generate(sdUrl, ctx, inp)
 }
 
+func Img2Img(sdUrl string, ctx context.Context, inp Img2ImgRequest) (*ImageResponse, error) {
+	return Default.Img2Img(sdUrl, ctx, inp)
+}
+
 type Client struct {
 	HTTP *http.Client
 }
 
 func (c *Client) Generate(sdUrl string, ctx context.Context, inp SimpleImageRequest) (*ImageResponse, error) {
-	u, err := buildURL(sdUrl, "/sdapi/v1/txt2img")
+	var result ImageResponse
+	if err := c.post(sdUrl, "/sdapi/v1/txt2img", ctx, inp, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (c *Client) Img2Img(sdUrl string, ctx context.Context, inp Img2ImgRequest) (*ImageResponse, error) {
+	var result ImageResponse
+	if err := c.post(sdUrl, "/sdapi/v1/img2img", ctx, inp, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (c *Client) post(sdUrl string, path string, ctx context.Context, inp interface{}, result interface{}) error {
+	u, err := buildURL(sdUrl, path)
 	if err != nil {
-		return nil, fmt.Errorf("error building URL: %w", err)
+		return fmt.Errorf("error building URL: %w", err)
 	}
 
 	var buf bytes.Buffer
 	if err := json.NewEncoder(&buf).Encode(inp); err != nil {
-		return nil, fmt.Errorf("error encoding json: %w", err)
+		return fmt.Errorf("error encoding json: %w", err)
 	}
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), &buf)
 	if err != nil {
-		return nil, fmt.Errorf("error making request: %w", err)
+		return fmt.Errorf("error making request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	return c.do(req, result)
+}
+
+func (c *Client) get(sdUrl string, path string, ctx context.Context, result interface{}) error {
+	u, err := buildURL(sdUrl, path)
+	if err != nil {
+		return fmt.Errorf("error building URL: %w", err)
 	}
 
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return fmt.Errorf("error making request: %w", err)
+	}
+
+	return c.do(req, result)
+}
+
+func (c *Client) do(req *http.Request, result interface{}) error {
 	resp, err := c.HTTP.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("error fetching response: %w", err)
+		return fmt.Errorf("error fetching response: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("error status code: %w", err)
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("error status code %d: %s", resp.StatusCode, string(respBody))
 	}
 
-	var result ImageResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("error parsing ImageResponse: %w", err)
+	if result == nil {
+		return nil
 	}
-
-	return &result, nil
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("error parsing response: %w", err)
+	}
+	return nil
 }
 
 func GetSamplers(sdUrl string, ctx context.Context) (map[string]Algorithm, error) {
-	u, err := buildURL(sdUrl, "/sdapi/v1/samplers")
-	if err != nil {
-		return nil, fmt.Errorf("error building URL: %w", err)
-	}
-
-	var buf bytes.Buffer
-	if err := json.NewEncoder(&buf).Encode(""); err != nil {
-		return nil, fmt.Errorf("error parsing SamplerResponse: %w", err)
-	}
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), &buf)
-	resp, err := Default.HTTP.Do(req)
 	samplers := []Algorithm{}
-	if err := json.NewDecoder(resp.Body).Decode(&samplers); err != nil {
-		return nil, fmt.Errorf("error parsing ImageResponse: %w", err)
+	if err := Default.get(sdUrl, "/sdapi/v1/samplers", ctx, &samplers); err != nil {
+		return nil, err
 	}
 	response := make(map[string]Algorithm)
 	for _, sampler := range samplers {
 		response[sampler.Name] = sampler
 	}
-
-	if err != nil {
-		return nil, fmt.Errorf("error fetching response: %w", err)
-	}
 	return response, nil
 }
 
 func GetUpscalers(sdUrl string, ctx context.Context) (map[string]Upscaler, error) {
-	u, err := buildURL(sdUrl, "/sdapi/v1/upscalers")
-	if err != nil {
-		return nil, fmt.Errorf("error building URL: %w", err)
-	}
-
-	var buf bytes.Buffer
-	if err := json.NewEncoder(&buf).Encode(""); err != nil {
-		return nil, fmt.Errorf("error parsing SamplerResponse: %w", err)
-	}
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), &buf)
-	resp, err := Default.HTTP.Do(req)
 	upscalers := []Upscaler{}
-	if err := json.NewDecoder(resp.Body).Decode(&upscalers); err != nil {
-		return nil, fmt.Errorf("error parsing ImageResponse: %w", err)
+	if err := Default.get(sdUrl, "/sdapi/v1/upscalers", ctx, &upscalers); err != nil {
+		return nil, err
 	}
 	response := make(map[string]Upscaler)
 	for _, upscaler := range upscalers {
 		response[upscaler.Name] = upscaler
 	}
+	return response, nil
+}
 
-	if err != nil {
-		return nil, fmt.Errorf("error fetching response: %w", err)
+// GetModels lists the checkpoints in the web ui's models/Stable-diffusion directory
+func GetModels(sdUrl string, ctx context.Context) ([]Model, error) {
+	models := []Model{}
+	if err := Default.get(sdUrl, "/sdapi/v1/sd-models", ctx, &models); err != nil {
+		return nil, err
 	}
-	return response, nil
+	return models, nil
+}
+
+func GetStyles(sdUrl string, ctx context.Context) ([]PromptStyle, error) {
+	styles := []PromptStyle{}
+	if err := Default.get(sdUrl, "/sdapi/v1/prompt-styles", ctx, &styles); err != nil {
+		return nil, err
+	}
+	return styles, nil
+}
+
+// GetLoras lists the LoRAs the web ui has loaded, it needs the built in Lora extension
+func GetLoras(sdUrl string, ctx context.Context) ([]Lora, error) {
+	loras := []Lora{}
+	if err := Default.get(sdUrl, "/sdapi/v1/loras", ctx, &loras); err != nil {
+		return nil, err
+	}
+	return loras, nil
+}
+
+func GetProgress(sdUrl string, ctx context.Context) (*Progress, error) {
+	var progress Progress
+	if err := Default.get(sdUrl, "/sdapi/v1/progress?skip_current_image=false", ctx, &progress); err != nil {
+		return nil, err
+	}
+	return &progress, nil
+}
+
+// Interrupt stops the job the web ui is running, the interrupted request returns what it has generated so far
+func Interrupt(sdUrl string, ctx context.Context) error {
+	return Default.post(sdUrl, "/sdapi/v1/interrupt", ctx, struct{}{}, nil)
+}
+
+// LoraPrompt is the prompt tag that applies a LoRA at the given weight
+func LoraPrompt(name string, weight float64) string {
+	return "<lora:" + name + ":" + strconv.FormatFloat(weight, 'f', -1, 64) + ">"
 }
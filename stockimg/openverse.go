@@ -0,0 +1,102 @@
+package stockimg
+
+import (
+	"context"
+	"html"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+var openverseUrl = "https://api.openverse.org/v1/images/"
+
+// openverse searches the openly licensed images indexed by Openverse, it works without a key
+type openverse struct{}
+
+func init() {
+	Register(openverse{})
+}
+
+func (openverse) Name() string {
+	return "openverse"
+}
+
+func (openverse) Label() string {
+	return "Openverse"
+}
+
+type openverseResponse struct {
+	Results []struct {
+		Title             string `json:"title"`
+		Url               string `json:"url"`
+		Creator           string `json:"creator"`
+		CreatorUrl        string `json:"creator_url"`
+		ForeignLandingUrl string `json:"foreign_landing_url"`
+		License           string `json:"license"`
+		LicenseVersion    string `json:"license_version"`
+		LicenseUrl        string `json:"license_url"`
+	} `json:"results"`
+}
+
+func (openverse) Search(ctx context.Context, settings map[string]string, query string, count int) ([]Photo, error) {
+	params := url.Values{}
+	params.Set("q", query)
+	params.Set("page_size", strconv.Itoa(count))
+	//Only licenses that allow use on a commercial blog and changes, images are cropped and resized
+	params.Set("license_type", "commercial,modification")
+	params.Set("mature", "false")
+	var result openverseResponse
+	err := getJSON(ctx, openverseUrl+"?"+params.Encode(), nil, &result)
+	if err != nil {
+		return nil, err
+	}
+	var photos []Photo
+	for _, result := range result.Results {
+		if strings.Contains(result.License, "nd") {
+			//NoDerivatives licenses forbid the crops made for each size
+			continue
+		}
+		imgBytes, err := download(ctx, result.Url)
+		if err != nil {
+			//Openverse indexes other sites, skip the images that have gone missing
+			continue
+		}
+		license := openverseLicense(result.License, result.LicenseVersion)
+		photos = append(photos, Photo{
+			Image:      imgBytes,
+			Provider:   "openverse",
+			PageUrl:    result.ForeignLandingUrl,
+			Credit:     openverseCredit(result.Title, result.ForeignLandingUrl, result.Creator, result.CreatorUrl, license, result.LicenseUrl),
+			License:    license,
+			LicenseUrl: result.LicenseUrl,
+		})
+	}
+	return photos, nil
+}
+
+// openverseLicense turns the license code, e.g. by-sa and 4.0, into its display name, CC BY-SA 4.0
+func openverseLicense(code string, version string) string {
+	switch code {
+	case "cc0":
+		return "CC0 1.0"
+	case "pdm":
+		return "Public Domain Mark 1.0"
+	}
+	return strings.TrimSpace("CC " + strings.ToUpper(code) + " " + version)
+}
+
+// openverseCredit follows the title, author, source, license format Creative Commons asks for
+func openverseCredit(title string, pageUrl string, creator string, creatorUrl string, license string, licenseUrl string) string {
+	if title == "" {
+		title = "Photo"
+	}
+	credit := "<a href=\"" + html.EscapeString(pageUrl) + "\">" + html.EscapeString(title) + "</a>"
+	if creator != "" {
+		if creatorUrl != "" {
+			credit += " by <a href=\"" + html.EscapeString(creatorUrl) + "\">" + html.EscapeString(creator) + "</a>"
+		} else {
+			credit += " by " + html.EscapeString(creator)
+		}
+	}
+	return credit + " is licensed under <a href=\"" + html.EscapeString(licenseUrl) + "\">" + html.EscapeString(license) + "</a>"
+}
@@ -0,0 +1,71 @@
+package stockimg
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strconv"
+)
+
+var pexelsUrl = "https://api.pexels.com/v1/search"
+
+// pexels searches Pexels with PEXELS_API_KEY
+type pexels struct{}
+
+func init() {
+	Register(pexels{})
+}
+
+func (pexels) Name() string {
+	return "pexels"
+}
+
+func (pexels) Label() string {
+	return "Pexels"
+}
+
+type pexelsResponse struct {
+	Photos []struct {
+		Url             string `json:"url"`
+		Photographer    string `json:"photographer"`
+		PhotographerUrl string `json:"photographer_url"`
+		Src             struct {
+			Large2x string `json:"large2x"`
+			Large   string `json:"large"`
+		} `json:"src"`
+	} `json:"photos"`
+}
+
+func (pexels) Search(ctx context.Context, settings map[string]string, query string, count int) ([]Photo, error) {
+	if settings["PEXELS_API_KEY"] == "" {
+		return nil, errors.New("PEXELS_API_KEY must be set")
+	}
+	params := url.Values{}
+	params.Set("query", query)
+	params.Set("per_page", strconv.Itoa(count))
+	var result pexelsResponse
+	err := getJSON(ctx, pexelsUrl+"?"+params.Encode(), map[string]string{"Authorization": settings["PEXELS_API_KEY"]}, &result)
+	if err != nil {
+		return nil, err
+	}
+	var photos []Photo
+	for _, result := range result.Photos {
+		imgUrl := result.Src.Large2x
+		if imgUrl == "" {
+			imgUrl = result.Src.Large
+		}
+		imgBytes, err := download(ctx, imgUrl)
+		if err != nil {
+			return nil, err
+		}
+		photos = append(photos, Photo{
+			Image:      imgBytes,
+			Provider:   "pexels",
+			PageUrl:    result.Url,
+			Credit:     credit("Photo by", result.Photographer, result.PhotographerUrl, "Pexels", "https://www.pexels.com"),
+			License:    "Pexels License",
+			LicenseUrl: "https://www.pexels.com/license/",
+		})
+	}
+	return photos, nil
+}
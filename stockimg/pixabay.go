@@ -0,0 +1,76 @@
+package stockimg
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strconv"
+)
+
+var pixabayUrl = "https://pixabay.com/api/"
+
+// pixabay searches Pixabay with PIXABAY_API_KEY
+type pixabay struct{}
+
+func init() {
+	Register(pixabay{})
+}
+
+func (pixabay) Name() string {
+	return "pixabay"
+}
+
+func (pixabay) Label() string {
+	return "Pixabay"
+}
+
+type pixabayResponse struct {
+	Hits []struct {
+		PageUrl       string `json:"pageURL"`
+		LargeImageUrl string `json:"largeImageURL"`
+		User          string `json:"user"`
+		UserId        int    `json:"user_id"`
+	} `json:"hits"`
+}
+
+func (pixabay) Search(ctx context.Context, settings map[string]string, query string, count int) ([]Photo, error) {
+	if settings["PIXABAY_API_KEY"] == "" {
+		return nil, errors.New("PIXABAY_API_KEY must be set")
+	}
+	params := url.Values{}
+	params.Set("key", settings["PIXABAY_API_KEY"])
+	params.Set("q", query)
+	params.Set("image_type", "photo")
+	params.Set("safesearch", "true")
+	//Pixabay won't return fewer than 3 results per page
+	perPage := count
+	if perPage < 3 {
+		perPage = 3
+	}
+	params.Set("per_page", strconv.Itoa(perPage))
+	var result pixabayResponse
+	err := getJSON(ctx, pixabayUrl+"?"+params.Encode(), nil, &result)
+	if err != nil {
+		return nil, err
+	}
+	var photos []Photo
+	for _, hit := range result.Hits {
+		if len(photos) >= count {
+			break
+		}
+		imgBytes, err := download(ctx, hit.LargeImageUrl)
+		if err != nil {
+			return nil, err
+		}
+		userUrl := "https://pixabay.com/users/" + url.PathEscape(hit.User) + "-" + strconv.Itoa(hit.UserId) + "/"
+		photos = append(photos, Photo{
+			Image:      imgBytes,
+			Provider:   "pixabay",
+			PageUrl:    hit.PageUrl,
+			Credit:     credit("Image by", hit.User, userUrl, "Pixabay", "https://pixabay.com/"),
+			License:    "Pixabay Content License",
+			LicenseUrl: "https://pixabay.com/service/license-summary/",
+		})
+	}
+	return photos, nil
+}
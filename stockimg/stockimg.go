@@ -0,0 +1,154 @@
+package stockimg
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Photo is a stock photo along with who to credit for it and the license it was published under
+type Photo struct {
+	Image []byte
+	// Provider is the Name of the provider the photo came from
+	Provider string
+	PageUrl  string
+	// Credit is the attribution line as HTML, its values already escaped
+	Credit     string
+	License    string
+	LicenseUrl string
+	// DownloadLocation is passed back to the provider when the photo is used, for providers that track usage
+	DownloadLocation string
+}
+
+// Provider is implemented by each stock photo source. Name is the value listed in STOCK_PROVIDERS.
+type Provider interface {
+	Name() string
+	Label() string
+	Search(ctx context.Context, settings map[string]string, query string, count int) ([]Photo, error)
+}
+
+// Tracker is implemented by providers that need to be told when one of their photos is used
+type Tracker interface {
+	TrackDownload(ctx context.Context, settings map[string]string, photo Photo) error
+}
+
+var providers = map[string]Provider{}
+
+// Register makes a provider available to STOCK_PROVIDERS, providers register themselves from init
+func Register(provider Provider) {
+	providers[provider.Name()] = provider
+}
+
+func Get(name string) (Provider, bool) {
+	provider, ok := providers[name]
+	return provider, ok
+}
+
+// All returns the registered providers ordered by name
+func All() []Provider {
+	all := make([]Provider, 0, len(providers))
+	for _, provider := range providers {
+		all = append(all, provider)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		return all[i].Name() < all[j].Name()
+	})
+	return all
+}
+
+// Search asks each provider in order, falling back to the next when one fails or finds nothing
+func Search(ctx context.Context, settings map[string]string, order []string, query string, count int) ([]Photo, error) {
+	if count < 1 {
+		count = 1
+	}
+	var errs []string
+	for _, name := range order {
+		provider, ok := Get(name)
+		if !ok {
+			errs = append(errs, "unknown provider "+name)
+			continue
+		}
+		photos, err := provider.Search(ctx, settings, query, count)
+		if err != nil {
+			errs = append(errs, name+": "+err.Error())
+			continue
+		}
+		if len(photos) > 0 {
+			return photos, nil
+		}
+		errs = append(errs, name+": no results")
+	}
+	if len(errs) == 0 {
+		return nil, errors.New("No stock photo providers set in STOCK_PROVIDERS")
+	}
+	return nil, errors.New("No stock photos found for " + query + " (" + strings.Join(errs, ", ") + ")")
+}
+
+// Track tells the photo's provider it was used, providers that don't track usage are skipped
+func Track(ctx context.Context, settings map[string]string, photo Photo) error {
+	provider, ok := Get(photo.Provider)
+	if !ok {
+		return nil
+	}
+	tracker, ok := provider.(Tracker)
+	if !ok {
+		return nil
+	}
+	return tracker.TrackDownload(ctx, settings, photo)
+}
+
+// getJSON sends a GET with the given headers and decodes the JSON response into result
+func getJSON(ctx context.Context, getUrl string, headers map[string]string, result interface{}) error {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, getUrl, nil)
+	if err != nil {
+		return fmt.Errorf("error making request: %w", err)
+	}
+	for k, v := range headers {
+		httpReq.Header.Set(k, v)
+	}
+	resp, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("error fetching response: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("error status code %d: %s", resp.StatusCode, string(respBody))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("error parsing search response: %w", err)
+	}
+	return nil
+}
+
+// download fetches a url, returning the body when the response is 200
+func download(ctx context.Context, getUrl string) ([]byte, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, getUrl, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error making request: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching response: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error status code %d", resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// credit builds an attribution line linking the author and the site the photo was found on
+func credit(prefix string, author string, authorUrl string, site string, siteUrl string) string {
+	if author == "" {
+		return prefix + " <a href=\"" + html.EscapeString(siteUrl) + "\">" + html.EscapeString(site) + "</a>"
+	}
+	return prefix + " <a href=\"" + html.EscapeString(authorUrl) + "\">" + html.EscapeString(author) + "</a> on <a href=\"" +
+		html.EscapeString(siteUrl) + "\">" + html.EscapeString(site) + "</a>"
+}
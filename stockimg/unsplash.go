@@ -0,0 +1,45 @@
+package stockimg
+
+import (
+	"context"
+	"golang/unsplash"
+)
+
+// unsplashProvider searches Unsplash with UNSPLASH_ACCESS_KEY
+type unsplashProvider struct{}
+
+func init() {
+	Register(unsplashProvider{})
+}
+
+func (unsplashProvider) Name() string {
+	return "unsplash"
+}
+
+func (unsplashProvider) Label() string {
+	return "Unsplash"
+}
+
+func (unsplashProvider) Search(ctx context.Context, settings map[string]string, query string, count int) ([]Photo, error) {
+	results, err := unsplash.GetPhotosBySearch(settings["UNSPLASH_ACCESS_KEY"], query, count)
+	if err != nil {
+		return nil, err
+	}
+	var photos []Photo
+	for _, result := range results {
+		photos = append(photos, Photo{
+			Image:            result.Image,
+			Provider:         "unsplash",
+			PageUrl:          result.PhotoUrl,
+			Credit:           result.Credit(),
+			License:          "Unsplash License",
+			LicenseUrl:       "https://unsplash.com/license",
+			DownloadLocation: result.DownloadLocation,
+		})
+	}
+	return photos, nil
+}
+
+func (unsplashProvider) TrackDownload(ctx context.Context, settings map[string]string, photo Photo) error {
+	return unsplash.TrackDownload(settings["UNSPLASH_ACCESS_KEY"], photo.DownloadLocation)
+}
@@ -4,19 +4,53 @@ import (
 	"errors"
 	"github.com/hbagdi/go-unsplash/unsplash"
 	"golang.org/x/oauth2"
+	"html"
 	"io"
 	"net/http"
 	"strconv"
 )
 
+// utmParams are required by the Unsplash API guidelines on every link back to Unsplash
+const utmParams = "?utm_source=blogotron&utm_medium=referral"
+
+// Photo is a downloaded Unsplash photo along with who to credit for it
+type Photo struct {
+	Image            []byte
+	PhotographerName string
+	PhotographerUrl  string
+	PhotoUrl         string
+	DownloadLocation string
+}
+
+// Credit is the attribution Unsplash asks for wherever the photo is shown
+func (p Photo) Credit() string {
+	if p.PhotographerName == "" {
+		return ""
+	}
+	return "Photo by <a href=\"" + html.EscapeString(p.PhotographerUrl+utmParams) + "\">" + html.EscapeString(p.PhotographerName) +
+		"</a> on <a href=\"" + html.EscapeString("https://unsplash.com/"+utmParams) + "\">Unsplash</a>"
+}
+
+func GetPhotoBySearch(unsplashAccessKey string, searchString string) (Photo, error) {
+	photos, err := GetPhotosBySearch(unsplashAccessKey, searchString, 1)
+	if err != nil {
+		return Photo{}, err
+	}
+	return photos[0], nil
+}
+
 func GetImageBySearch(unsplashAccessKey string, searchString string) ([]byte, error) {
-	imgBytes := []byte{}
-	ts := oauth2.StaticTokenSource(
-		// note Client-ID in front of the access token
-		&oauth2.Token{AccessToken: "Client-ID " + unsplashAccessKey},
-	)
-	client := oauth2.NewClient(oauth2.NoContext, ts)
-	unClient := unsplash.New(client)
+	photo, err := GetPhotoBySearch(unsplashAccessKey, searchString)
+	if err != nil {
+		return nil, err
+	}
+	return photo.Image, nil
+}
+
+// GetPhotosBySearch downloads up to count of the top search results.
+// TrackDownload should be called for the photos that end up being used.
+func GetPhotosBySearch(unsplashAccessKey string, searchString string, count int) ([]Photo, error) {
+	unClient := unsplash.New(newClient(unsplashAccessKey))
 
 	opt := unsplash.SearchOpt{}
 	opt.Query = searchString
@@ -24,11 +58,65 @@ func GetImageBySearch(unsplashAccessKey string, searchString string) ([]byte, er
 	if err != nil {
 		return nil, err
 	}
-	imgUrl := ""
+	var photos []Photo
 	for _, c := range *searchResults.Results {
-		imgUrl = c.Urls.Regular.URL.String()
-		break
+		if len(photos) >= count {
+			break
+		}
+		imgBytes, err := downloadImage(c.Urls.Regular.URL.String())
+		if err != nil {
+			return nil, err
+		}
+		photo := Photo{Image: imgBytes}
+		if c.Photographer != nil {
+			if c.Photographer.Name != nil {
+				photo.PhotographerName = *c.Photographer.Name
+			}
+			if c.Photographer.Links != nil && c.Photographer.Links.HTML != nil {
+				photo.PhotographerUrl = c.Photographer.Links.HTML.URL.String()
+			}
+		}
+		if c.Links != nil {
+			if c.Links.HTML != nil {
+				photo.PhotoUrl = c.Links.HTML.URL.String()
+			}
+			if c.Links.DownloadLocation != nil {
+				photo.DownloadLocation = c.Links.DownloadLocation.URL.String()
+			}
+		}
+		photos = append(photos, photo)
 	}
+	if len(photos) == 0 {
+		return nil, errors.New("No Unsplash results for: " + searchString)
+	}
+	return photos, nil
+}
+
+// TrackDownload tells Unsplash a photo was used, as the API guidelines require
+func TrackDownload(unsplashAccessKey string, downloadLocation string) error {
+	if downloadLocation == "" {
+		return nil
+	}
+	response, err := newClient(unsplashAccessKey).Get(downloadLocation)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != 200 {
+		return errors.New("Bad response code tracking Unsplash download: " + strconv.Itoa(response.StatusCode))
+	}
+	return nil
+}
+
+func newClient(unsplashAccessKey string) *http.Client {
+	ts := oauth2.StaticTokenSource(
+		// note Client-ID in front of the access token
+		&oauth2.Token{AccessToken: "Client-ID " + unsplashAccessKey},
+	)
+	return oauth2.NewClient(oauth2.NoContext, ts)
+}
+
+func downloadImage(imgUrl string) ([]byte, error) {
 	response, err := http.Get(imgUrl)
 	if err != nil {
 		return nil, err
@@ -40,9 +128,5 @@ func GetImageBySearch(unsplashAccessKey string, searchString string) ([]byte, er
 		err = errors.New("Bad response code downloading image: " + strconv.Itoa(response.StatusCode))
 		return nil, err
 	}
-	imgBytes, err = io.ReadAll(response.Body)
-	if err != nil {
-		return nil, err
-	}
-	return imgBytes, nil
+	return io.ReadAll(response.Body)
 }
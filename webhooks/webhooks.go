@@ -0,0 +1,305 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"golang/models"
+	"golang/util"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The events Blog-o-Tron fires, webhooks subscribe to them by name
+const (
+	IdeaCreated      = "idea.created"
+	ArticleGenerated = "article.generated"
+	ArticlePublished = "article.published"
+	JobFailed        = "job.failed"
+	SystemTestFailed = "system.test.failed"
+	// Ping is only sent by the Test button, to the one webhook being tested
+	Ping = "webhook.ping"
+)
+
+// Events lists the events a webhook can subscribe to
+var Events = []string{IdeaCreated, ArticleGenerated, ArticlePublished, JobFailed, SystemTestFailed}
+
+// Payload is the JSON body of every delivery, Data depends on the event
+type Payload struct {
+	Id        string      `json:"id"`
+	Event     string      `json:"event"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// RetryDelays are the waits before each retry of a failed delivery, a delivery is tried len(RetryDelays)+1 times.
+// Retries are sent by RetryDue, so they survive a restart.
+var RetryDelays = []time.Duration{30 * time.Second, 5 * time.Minute, 30 * time.Minute}
+
+// sendTimeout bounds each attempt, receivers are expected to answer quickly and do their work later
+var sendTimeout = 15 * time.Second
+
+// keepDeliveries is how many deliveries the log holds, older ones are pruned as new ones are added
+const keepDeliveries = 1000
+
+// retryMu keeps RetryDue from sending the same delivery twice when a sweep runs long
+var retryMu sync.Mutex
+
+// logMu serializes writes to the delivery log, deliveries run concurrently and SQLite takes one writer at a time
+var logMu sync.Mutex
+
+// maxResponse is how much of the receiver's response is kept in the delivery log
+const maxResponse = 1000
+
+// Matches reports whether an event passes a webhook's filter. The filter is a comma separated list of
+// event names where * matches every event and a trailing .* matches a group, such as article.*.
+// An empty filter matches every event.
+func Matches(filter string, event string) bool {
+	if strings.TrimSpace(filter) == "" {
+		return true
+	}
+	for _, pattern := range strings.Split(filter, ",") {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "*" || pattern == event {
+			return true
+		}
+		if group, ok := strings.CutSuffix(pattern, ".*"); ok && strings.HasPrefix(event, group+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// Sign returns the hex HMAC-SHA256 of the timestamp and body joined by a dot, which receivers recompute
+// with the shared secret to check the X-Blogotron-Signature header
+func Sign(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Fire delivers an event to every enabled webhook subscribed to it. Deliveries run in the background,
+// so Fire never holds up the caller, and each is logged with the outcome of its attempts.
+func Fire(event string, data interface{}) {
+	hooks, err := models.GetWebhooks()
+	if err != nil {
+		util.Logger.Error().Err(err).Msg("Error getting webhooks")
+		return
+	}
+	var body []byte
+	for _, hook := range hooks {
+		if !hook.Enabled || !Matches(hook.Events, event) {
+			continue
+		}
+		if body == nil {
+			body, err = newPayload(event, data)
+			if err != nil {
+				util.Logger.Error().Err(err).Msg("Error encoding " + event + " event")
+				return
+			}
+		}
+		go deliver(hook, event, body)
+	}
+}
+
+// Subscribed reports whether any enabled webhook would receive the event, for skipping work only its data needs
+func Subscribed(event string) bool {
+	hooks, err := models.GetWebhooks()
+	if err != nil {
+		return false
+	}
+	for _, hook := range hooks {
+		if hook.Enabled && Matches(hook.Events, event) {
+			return true
+		}
+	}
+	return false
+}
+
+// Test sends a ping to one webhook and waits for the result, without retrying
+func Test(hook models.Webhook) error {
+	body, err := newPayload(Ping, map[string]string{"message": "Webhook test from Blog-o-Tron"})
+	if err != nil {
+		return err
+	}
+	delivery, err := logDelivery(hook, Ping, body)
+	if err != nil {
+		return err
+	}
+	return attempt(hook, &delivery, 1)
+}
+
+// Redeliver sends a logged delivery's payload to its webhook again in the background, with the usual retries
+func Redeliver(deliveryId int) error {
+	delivery, err := models.GetWebhookDeliveryById(deliveryId)
+	if err != nil {
+		return err
+	}
+	if delivery.Id == 0 {
+		return errors.New("Delivery " + strconv.Itoa(deliveryId) + " not found")
+	}
+	hook, err := models.GetWebhookById(delivery.WebhookId)
+	if err != nil {
+		return err
+	}
+	if hook.Id == 0 {
+		return errors.New("The webhook for delivery " + strconv.Itoa(deliveryId) + " was deleted")
+	}
+	go deliver(hook, delivery.Event, []byte(delivery.Payload))
+	return nil
+}
+
+func newPayload(event string, data interface{}) ([]byte, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	return json.Marshal(Payload{
+		Id:        hex.EncodeToString(id),
+		Event:     event,
+		CreatedAt: time.Now().UTC(),
+		Data:      data,
+	})
+}
+
+func logDelivery(hook models.Webhook, event string, body []byte) (models.WebhookDelivery, error) {
+	delivery := models.WebhookDelivery{
+		WebhookId: hook.Id,
+		Event:     event,
+		Payload:   string(body),
+		Status:    "pending",
+	}
+	logMu.Lock()
+	defer logMu.Unlock()
+	id, err := models.AddWebhookDelivery(delivery)
+	if err != nil {
+		return delivery, err
+	}
+	delivery.Id = int(id)
+	return delivery, models.PruneWebhookDeliveries(keepDeliveries)
+}
+
+// deliver logs a delivery and makes its first attempt, a failed delivery is left retrying for RetryDue
+func deliver(hook models.Webhook, event string, body []byte) {
+	delivery, err := logDelivery(hook, event, body)
+	if err != nil {
+		util.Logger.Error().Err(err).Msg("Error logging webhook delivery")
+	}
+	err = attempt(hook, &delivery, 1)
+	if err != nil {
+		util.Logger.Error().Err(err).Str("webhook", hook.Name).Msg("Error delivering " + event + " webhook, attempt 1")
+	}
+}
+
+// RetryDue sends the retrying deliveries whose wait from RetryDelays has passed since their last attempt. It runs on
+// a schedule, so deliveries left retrying when Blog-o-Tron stopped pick up where they left off.
+func RetryDue() {
+	if !retryMu.TryLock() {
+		return
+	}
+	defer retryMu.Unlock()
+	deliveries, err := models.GetRetryingWebhookDeliveries()
+	if err != nil {
+		util.Logger.Error().Err(err).Msg("Error getting retrying webhook deliveries")
+		return
+	}
+	for _, delivery := range deliveries {
+		if delivery.Attempts > len(RetryDelays) || delivery.Attempts < 1 {
+			delivery.Status = "failed"
+			updateDelivery(delivery)
+			continue
+		}
+		lastAttempt, err := time.ParseInLocation("2006-01-02 15:04:05", delivery.UpdateDate, time.UTC)
+		if err == nil && time.Now().UTC().Before(lastAttempt.Add(RetryDelays[delivery.Attempts-1])) {
+			continue
+		}
+		hook, err := models.GetWebhookById(delivery.WebhookId)
+		if err != nil {
+			util.Logger.Error().Err(err).Msg("Error getting webhook")
+			continue
+		}
+		if hook.Id == 0 {
+			delivery.Status = "failed"
+			delivery.Error = "The webhook was deleted"
+			updateDelivery(delivery)
+			continue
+		}
+		err = attempt(hook, &delivery, delivery.Attempts+1)
+		if err != nil {
+			util.Logger.Error().Err(err).Str("webhook", hook.Name).Msg("Error delivering " + delivery.Event + " webhook, attempt " + strconv.Itoa(delivery.Attempts))
+		}
+	}
+}
+
+// attempt sends the delivery once and records how it went, leaving it retrying unless it succeeded or was the last try
+func attempt(hook models.Webhook, delivery *models.WebhookDelivery, attempt int) error {
+	statusCode, response, err := send(hook, delivery.Event, []byte(delivery.Payload))
+	delivery.Attempts = attempt
+	delivery.StatusCode = statusCode
+	delivery.Response = response
+	delivery.Error = ""
+	switch {
+	case err == nil:
+		delivery.Status = "delivered"
+	case attempt > len(RetryDelays) || delivery.Event == Ping:
+		delivery.Status = "failed"
+		delivery.Error = err.Error()
+	default:
+		delivery.Status = "retrying"
+		delivery.Error = err.Error()
+	}
+	if delivery.Id > 0 {
+		updateDelivery(*delivery)
+	}
+	return err
+}
+
+func updateDelivery(delivery models.WebhookDelivery) {
+	logMu.Lock()
+	defer logMu.Unlock()
+	if _, err := models.UpdateWebhookDelivery(delivery); err != nil {
+		util.Logger.Error().Err(err).Msg("Error logging webhook delivery")
+	}
+}
+
+// send posts the body with its event headers and signature, any 2xx response is a success
+func send(hook models.Webhook, event string, body []byte) (int, string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.Url, bytes.NewReader(body))
+	if err != nil {
+		return 0, "", err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Blog-o-Tron-Webhook")
+	req.Header.Set("X-Blogotron-Event", event)
+	req.Header.Set("X-Blogotron-Timestamp", timestamp)
+	var payload Payload
+	if json.Unmarshal(body, &payload) == nil {
+		req.Header.Set("X-Blogotron-Delivery", payload.Id)
+	}
+	if hook.Secret != "" {
+		req.Header.Set("X-Blogotron-Signature", "sha256="+Sign(hook.Secret, timestamp, body))
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponse))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, string(respBody), errors.New("Webhook returned status code " + strconv.Itoa(resp.StatusCode))
+	}
+	return resp.StatusCode, string(respBody), nil
+}